@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/johnmikee/cuebert/cuebert/api"
+)
+
+// serveAPI adds the admin api to the health server when an api token is set.
+func (c *Cuebert) serveAPI() {
+	if c.config.APIToken == "" {
+		c.log.Info().Msg("no api token set, not serving the admin api")
+		return
+	}
+
+	http.Handle(api.Prefix, api.New(&api.Config{
+		Token:     c.config.APIToken,
+		AllowStop: c.flags.apiAllowStop,
+		Bot:       c.bot,
+		Tables:    c.tables,
+		Log:       c.log,
+	}).Handler())
+}
@@ -0,0 +1,147 @@
+// Package api serves the slack admin commands over http so automation can
+// run cuebert without a person in slack. Every request needs the api token.
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/johnmikee/cuebert/cuebert/bot"
+	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Prefix is the path the api is served under.
+const Prefix = "/api/v1/"
+
+// ActorHeader names who is calling the api in the audit log, ex: the
+// runbook or ticket. calls without it are recorded as api.
+const ActorHeader = "X-Cuebert-Actor"
+
+// maxBody is the largest request body read.
+const maxBody = 1 << 20
+
+// Config holds what the api needs to serve requests.
+type Config struct {
+	Token string
+	// AllowStop serves stop. It is off by default since the token stands in
+	// for the second approval slack asks for.
+	AllowStop bool
+	Bot       *bot.Bot
+	Tables    *tables.Config
+	Log       logger.Logger
+}
+
+// API serves the admin operations.
+type API struct {
+	token     []byte
+	allowStop bool
+	bot       *bot.Bot
+	tables    *tables.Config
+	log       logger.Logger
+}
+
+// New returns the api for the config.
+func New(c *Config) *API {
+	return &API{
+		token:     []byte(c.Token),
+		allowStop: c.AllowStop,
+		bot:       c.Bot,
+		tables:    c.Tables,
+		log:       logger.ChildLogger("api", &c.Log),
+	}
+}
+
+// Handler returns the routes under Prefix. Requests without the token get a
+// 401.
+func (a *API) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(Prefix+"start", methods{http.MethodPost: a.start}.serve)
+	mux.HandleFunc(Prefix+"stop", methods{http.MethodPost: a.stop}.serve)
+	mux.HandleFunc(Prefix+"config", methods{http.MethodGet: a.getConfig, http.MethodPatch: a.updateConfig}.serve)
+	mux.HandleFunc(Prefix+"exclusions", methods{http.MethodGet: a.exclusions, http.MethodPost: a.addExclusion}.serve)
+	mux.HandleFunc(Prefix+"reports/", methods{http.MethodGet: a.report}.serve)
+	mux.HandleFunc(Prefix+"users", methods{http.MethodGet: a.userInfo}.serve)
+	mux.HandleFunc(Prefix+"bot_results", methods{http.MethodGet: a.botResults}.serve)
+	mux.HandleFunc(Prefix+"devices", methods{http.MethodGet: a.devices}.serve)
+
+	return a.authenticate(mux)
+}
+
+// authenticate lets requests with the bearer token through.
+func (a *API) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || len(a.token) == 0 || subtle.ConstantTimeCompare([]byte(token), a.token) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="cuebert"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// methods routes a path by the request method.
+type methods map[string]http.HandlerFunc
+
+func (m methods) serve(w http.ResponseWriter, r *http.Request) {
+	h, ok := m[r.Method]
+	if !ok {
+		allowed := make([]string, 0, len(m))
+		for method := range m {
+			allowed = append(allowed, method)
+		}
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, http.StatusMethodNotAllowed, errors.New(r.Method+" is not allowed"))
+		return
+	}
+
+	h(w, r)
+}
+
+// actor is who the request is recorded under in the audit log.
+func actor(r *http.Request) string {
+	if name := strings.TrimSpace(r.Header.Get(ActorHeader)); name != "" {
+		return "api:" + name
+	}
+
+	return "api"
+}
+
+// decode reads the json body into v. Bodies over maxBody and fields v does
+// not have are rejected.
+func decode(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(v)
+}
+
+// decodeError writes the error decode returned, a 413 if the body was too
+// large.
+func decodeError(w http.ResponseWriter, what string, err error) {
+	code := http.StatusBadRequest
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		code = http.StatusRequestEntityTooLarge
+	}
+
+	writeError(w, code, fmt.Errorf("decoding %s: %w", what, err))
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,285 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/bot"
+	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/db/audit"
+	"github.com/johnmikee/cuebert/db/devices"
+	"github.com/johnmikee/cuebert/db/exclusions"
+	"github.com/johnmikee/cuebert/db/sqlite"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+const token = "secret"
+
+type lifeCycle struct {
+	running atomic.Bool
+	starts  atomic.Int32
+	updates int
+}
+
+func (l *lifeCycle) Start() bool {
+	if !l.running.CompareAndSwap(false, true) {
+		return false
+	}
+	l.starts.Add(1)
+
+	return true
+}
+
+func (l *lifeCycle) Stop() bool    { return l.running.CompareAndSwap(true, false) }
+func (l *lifeCycle) Update()       { l.updates++ }
+func (l *lifeCycle) Running() bool { return l.running.Load() }
+
+func newTestAPI(t *testing.T) (http.Handler, *lifeCycle, *tables.Config) {
+	t.Helper()
+
+	store, err := sqlite.Open(filepath.Join(t.TempDir(), "cue.db"))
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	t.Cleanup(store.Close)
+
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+
+	_, err = devices.Device(store, &log).AddAllDevices(devices.DI{
+		{DeviceID: "d1", DeviceName: "old", Model: "Mac14,2", SerialNumber: "S1", Platform: "Mac", OSVersion: "13.3", User: "old@example.com", UserMDMID: "1"},
+	})
+	if err != nil {
+		t.Fatalf("adding devices: %v", err)
+	}
+
+	lc := &lifeCycle{}
+	tbl := tables.New(store, &log)
+	b := bot.New(&bot.Config{
+		Cfg:       bot.CfgSetter(bot.WithCheckInterval(15)),
+		LifeCycle: lc,
+		Log:       log,
+		Tables:    tbl,
+	})
+
+	a := New(&Config{Token: token, AllowStop: true, Bot: b, Tables: tbl, Log: log})
+
+	return a.Handler(), lc, tbl
+}
+
+func do(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(ActorHeader, "runbook")
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestAuthenticate(t *testing.T) {
+	h, _, _ := newTestAPI(t)
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"no token", ""},
+		{"wrong token", "Bearer nope"},
+		{"not bearer", token},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, Prefix+"config", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusUnauthorized {
+				t.Errorf("got status code: %d, want: %d", rr.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+
+	if rr := do(h, http.MethodDelete, Prefix+"config", ""); rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("got status code: %d, want: %d", rr.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestStart(t *testing.T) {
+	h, lc, tbl := newTestAPI(t)
+
+	if rr := do(h, http.MethodPost, Prefix+"start", ""); rr.Code != http.StatusAccepted {
+		t.Fatalf("got status code: %d, want: %d", rr.Code, http.StatusAccepted)
+	}
+	if !lc.Running() {
+		t.Error("start did not start cuebert")
+	}
+
+	if rr := do(h, http.MethodPost, Prefix+"start", ""); rr.Code != http.StatusConflict {
+		t.Errorf("got status code: %d, want: %d", rr.Code, http.StatusConflict)
+	}
+
+	events, err := tbl.AuditSince(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Action != audit.Started || events[0].Actor != "api:runbook" {
+		t.Errorf("got audit events: %+v", events)
+	}
+}
+
+func TestStop(t *testing.T) {
+	h, _, _ := newTestAPI(t)
+
+	if rr := do(h, http.MethodPost, Prefix+"stop", ""); rr.Code != http.StatusConflict {
+		t.Errorf("got status code: %d, want: %d", rr.Code, http.StatusConflict)
+	}
+
+	// stopping needs to be allowed.
+	h = New(&Config{Token: token, Log: logger.NewLogger(&logger.Config{Level: "error"})}).Handler()
+	if rr := do(h, http.MethodPost, Prefix+"stop", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("got status code: %d, want: %d", rr.Code, http.StatusForbidden)
+	}
+}
+
+func TestStartConcurrent(t *testing.T) {
+	h, lc, tbl := newTestAPI(t)
+
+	const n = 10
+
+	codes := make(chan int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- do(h, http.MethodPost, Prefix+"start", "").Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	accepted := 0
+	for code := range codes {
+		switch code {
+		case http.StatusAccepted:
+			accepted++
+		case http.StatusConflict:
+		default:
+			t.Errorf("got status code: %d", code)
+		}
+	}
+	if accepted != 1 || lc.starts.Load() != 1 {
+		t.Errorf("got %d accepted and %d starts, want 1", accepted, lc.starts.Load())
+	}
+
+	events, err := tbl.AuditSince(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Errorf("got %d audit events, want 1", len(events))
+	}
+}
+
+func TestConfig(t *testing.T) {
+	h, lc, _ := newTestAPI(t)
+
+	rr := do(h, http.MethodPatch, Prefix+"config", `{"poll_interval": 5}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status code: %d, want: %d: %s", rr.Code, http.StatusOK, rr.Body)
+	}
+
+	var got bot.ConfigState
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.PollInterval != 5 || got.CheckInterval != 15 {
+		t.Errorf("got poll interval %d and check interval %d, want 5 and 15", got.PollInterval, got.CheckInterval)
+	}
+	if lc.updates != 1 {
+		t.Errorf("got %d updates, want 1", lc.updates)
+	}
+
+	if rr := do(h, http.MethodPatch, Prefix+"config", `{"nope": true}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("got status code: %d, want: %d", rr.Code, http.StatusBadRequest)
+	}
+
+	large := `{"help_docs_url": "` + strings.Repeat("a", maxBody) + `"}`
+	if rr := do(h, http.MethodPatch, Prefix+"config", large); rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("got status code: %d, want: %d", rr.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestExclusions(t *testing.T) {
+	h, _, _ := newTestAPI(t)
+
+	tests := []struct {
+		body string
+		code int
+	}{
+		{`{"serial_number": "S1", "reason": "lab", "until": "2030-01-01"}`, http.StatusCreated},
+		{`{"serial_number": "S1", "reason": "lab", "until": "soon"}`, http.StatusBadRequest},
+		{`{"serial_number": "", "reason": "lab", "until": "2030-01-01"}`, http.StatusBadRequest},
+		{`{"serial_number": "S9", "reason": "lab", "until": "2030-01-01"}`, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if rr := do(h, http.MethodPost, Prefix+"exclusions", tt.body); rr.Code != tt.code {
+			t.Errorf("%s: got status code: %d, want: %d", tt.body, rr.Code, tt.code)
+		}
+	}
+
+	rr := do(h, http.MethodGet, Prefix+"exclusions?serial=S1", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status code: %d, want: %d", rr.Code, http.StatusOK)
+	}
+
+	var got exclusions.EI
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].UserEmail != "old@example.com" || !got[0].Approved {
+		t.Errorf("got exclusions: %+v", got)
+	}
+}
+
+func TestReadEndpoints(t *testing.T) {
+	h, _, _ := newTestAPI(t)
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{Prefix + "devices?serial=S1", http.StatusOK, `"serial_number":"S1"`},
+		{Prefix + "bot_results", http.StatusOK, `[]`},
+		{Prefix + "reports/os", http.StatusOK, `{"label":"13.3","count":1}`},
+		{Prefix + "reports/first-message-sent", http.StatusOK, `"report":"first message sent"`},
+		{Prefix + "reports/nope", http.StatusNotFound, `not a report`},
+		{Prefix + "users?email=old@example.com", http.StatusOK, `"email":"old@example.com"`},
+		{Prefix + "users", http.StatusBadRequest, `slack_id or email`},
+	}
+
+	for _, tt := range tests {
+		rr := do(h, http.MethodGet, tt.path, "")
+		if rr.Code != tt.code {
+			t.Errorf("%s: got status code: %d, want: %d", tt.path, rr.Code, tt.code)
+		}
+		if !strings.Contains(rr.Body.String(), tt.body) {
+			t.Errorf("%s: got body %s, want it to contain %s", tt.path, rr.Body, tt.body)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/bot"
+	dbot "github.com/johnmikee/cuebert/db/bot"
+	"github.com/johnmikee/cuebert/db/devices"
+	"github.com/johnmikee/cuebert/db/exclusions"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+)
+
+// start starts cuebert like `start cuebert` without the config modal.
+func (a *API) start(w http.ResponseWriter, r *http.Request) {
+	err := a.bot.Start(actor(r))
+	if errors.Is(err, bot.ErrRunning) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		a.log.Err(err).Msg("starting cuebert")
+		writeError(w, http.StatusInternalServerError, errors.New("could not start cuebert"))
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "starting"})
+}
+
+// stop stops cuebert like `stop cuebert` when the api is allowed to. The
+// token stands in for the second approval asked for in slack.
+func (a *API) stop(w http.ResponseWriter, r *http.Request) {
+	if !a.allowStop {
+		writeError(w, http.StatusForbidden, errors.New("stopping cuebert needs a second approval in slack. set -api-allow-stop to allow the api to stop it"))
+		return
+	}
+
+	err := a.bot.Stop(actor(r))
+	if errors.Is(err, bot.ErrNotRunning) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		a.log.Err(err).Msg("stopping cuebert")
+		writeError(w, http.StatusInternalServerError, errors.New("could not stop cuebert"))
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "stopping"})
+}
+
+// getConfig returns the config `update config` can change.
+func (a *API) getConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.bot.ConfigState())
+}
+
+// updateConfig changes the fields in the body like `update config`. Fields
+// left out keep their value.
+func (a *API) updateConfig(w http.ResponseWriter, r *http.Request) {
+	c := a.bot.ConfigState()
+
+	if err := decode(w, r, &c); err != nil {
+		decodeError(w, "config", err)
+		return
+	}
+
+	a.bot.UpdateConfig(actor(r), c)
+
+	writeJSON(w, http.StatusOK, a.bot.ConfigState())
+}
+
+// exclusionRequest is the body of a new exclusion.
+type exclusionRequest struct {
+	SerialNumber string `json:"serial_number"`
+	Reason       string `json:"reason"`
+	Until        string `json:"until"`
+}
+
+// exclusions returns the exclusions, filtered by the serial or email query
+// parameter.
+func (a *API) exclusions(w http.ResponseWriter, r *http.Request) {
+	q := a.tables.WithContext(r.Context()).ExclusionBy()
+
+	var (
+		res exclusions.EI
+		err error
+	)
+
+	switch {
+	case r.URL.Query().Has("serial"):
+		res, err = q.Serial(r.URL.Query().Get("serial")).Query()
+	case r.URL.Query().Has("email"):
+		res, err = q.Email(r.URL.Query().Get("email")).Query()
+	default:
+		res, err = q.All().Query()
+	}
+
+	if err != nil {
+		a.log.Err(err).Msg("getting exclusions")
+		writeError(w, http.StatusInternalServerError, errors.New("could not get exclusions"))
+		return
+	}
+
+	if res.Empty() {
+		res = exclusions.EI{}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// addExclusion excludes a device like `add exclusion`.
+func (a *API) addExclusion(w http.ResponseWriter, r *http.Request) {
+	var req exclusionRequest
+
+	if err := decode(w, r, &req); err != nil {
+		decodeError(w, "exclusion", err)
+		return
+	}
+
+	req.SerialNumber = strings.TrimSpace(req.SerialNumber)
+	if req.SerialNumber == "" || req.Reason == "" {
+		writeError(w, http.StatusBadRequest, errors.New("serial_number and reason are required"))
+		return
+	}
+
+	until, err := time.Parse("2006-01-02", req.Until)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("until %q is not a date (2006-01-02)", req.Until))
+		return
+	}
+
+	err = a.bot.AddExclusion(actor(r), req.SerialNumber, req.Reason, until)
+	if err != nil {
+		a.log.Err(err).Str("serial", req.SerialNumber).Msg("adding exclusion")
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("could not add exclusion: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, req)
+}
+
+// reportValue is a slice of a report.
+type reportValue struct {
+	Label string  `json:"label"`
+	Count float64 `json:"count"`
+}
+
+// reportResponse is the data `get report` charts.
+type reportResponse struct {
+	Report string        `json:"report"`
+	Title  string        `json:"title"`
+	Values []reportValue `json:"values"`
+}
+
+// report returns the data of a report like `get report`. Words in the name
+// may be joined by - or _, ex: /reports/manager-alerted.
+func (a *API) report(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, Prefix+"reports/")
+	name = strings.ToLower(strings.NewReplacer("-", " ", "_", " ").Replace(name))
+
+	if !helpers.Contains(bot.ReportOpts, name) {
+		writeError(w, http.StatusNotFound,
+			fmt.Errorf("%s is not a report. reports are %s", name, strings.Join(bot.ReportOpts, ", ")))
+		return
+	}
+
+	vis, err := a.bot.Report(name)
+	if err != nil {
+		a.log.Err(err).Str("report", name).Msg("building report")
+		writeError(w, http.StatusInternalServerError, errors.New("could not build report"))
+		return
+	}
+
+	res := reportResponse{Report: name, Title: vis.Text, Values: []reportValue{}}
+	for i := range vis.XAxis {
+		res.Values = append(res.Values, reportValue{Label: vis.XAxis[i], Count: vis.ValueList[i]})
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// userInfo returns what is known about a user like `get users info`, looked
+// up by the slack_id or email query parameter.
+func (a *API) userInfo(w http.ResponseWriter, r *http.Request) {
+	var by, value string
+
+	switch {
+	case r.URL.Query().Has("slack_id"):
+		by, value = "slackid", r.URL.Query().Get("slack_id")
+	case r.URL.Query().Has("email"):
+		by, value = "email", r.URL.Query().Get("email")
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("slack_id or email is required"))
+		return
+	}
+
+	info, err := a.bot.UserInfo(by, value)
+	if err != nil {
+		a.log.Err(err).Str(by, value).Msg("getting user info")
+		writeError(w, http.StatusInternalServerError, errors.New("could not get user info"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+// botResults returns the rows of the bot results table, filtered by the email
+// query parameter.
+func (a *API) botResults(w http.ResponseWriter, r *http.Request) {
+	t := a.tables.WithContext(r.Context())
+
+	var (
+		res dbot.BR
+		err error
+	)
+
+	if r.URL.Query().Has("email") {
+		res, err = t.GetBotTableInfoEmail(r.URL.Query().Get("email"))
+	} else {
+		res, err = t.GetBotTableInfo()
+	}
+
+	if err != nil {
+		a.log.Err(err).Msg("getting bot results")
+		writeError(w, http.StatusInternalServerError, errors.New("could not get bot results"))
+		return
+	}
+
+	if res.Empty() {
+		res = dbot.BR{}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// devices returns the devices, filtered by the serial or email query
+// parameter.
+func (a *API) devices(w http.ResponseWriter, r *http.Request) {
+	t := a.tables.WithContext(r.Context())
+
+	var (
+		res devices.DI
+		err error
+	)
+
+	switch {
+	case r.URL.Query().Has("serial"):
+		res, err = t.DeviceBySerial(r.URL.Query().Get("serial"))
+	case r.URL.Query().Has("email"):
+		res, err = t.DeviceByEmail(r.URL.Query().Get("email"))
+	default:
+		res, err = t.GetAllDevices()
+	}
+
+	if err != nil {
+		a.log.Err(err).Msg("getting devices")
+		writeError(w, http.StatusInternalServerError, errors.New("could not get devices"))
+		return
+	}
+
+	if res.Empty() {
+		res = devices.DI{}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
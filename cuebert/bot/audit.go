@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johnmikee/cuebert/db/audit"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/shomali11/slacker/v2"
+)
+
+var auditOpts = []string{"user", "serial", "since"}
+
+// auditLimit is the most events returned in a reply. the most recent are kept.
+const auditLimit = 25
+
+// Audit records the action in the audit log. A failure to record is logged
+// and does not stop the action.
+func (b *Bot) Audit(actor string, action audit.Action, user, serial string, before, after any) {
+	err := b.tables.Record(actor, action, user, serial, before, after)
+	if err != nil {
+		b.log.Err(err).
+			Str("actor", actor).
+			Str("action", string(action)).
+			Str("user", user).
+			Str("serial", serial).
+			Msg("recording audit event")
+	}
+}
+
+// auditCommands lets authorized users read the audit log.
+func (b *Bot) auditCommands() {
+	definition := &slacker.CommandDefinition{
+		Command:     "get audit {by} <value>",
+		Description: "Get the audit log for a user, a device or since a time",
+		Examples: []string{
+			"get audit user @user",
+			"get audit user user@example.com",
+			"get audit serial ABC123",
+			"get audit since 2023-06-01",
+			"get audit since 24h",
+		},
+		Middlewares: []slacker.CommandMiddlewareHandler{authorizationMiddleware(b.cfg.authUsers)},
+		Handler: func(ctx *slacker.CommandContext) {
+			msg := b.auditLog(ctx.Request().Param("by"), ctx.Request().Param("value"))
+
+			_, err := ctx.Response().Reply(msg)
+			if err != nil {
+				b.log.Debug().AnErr("sending audit response", err).Send()
+			}
+		},
+	}
+
+	b.bot.AddCommand(definition)
+}
+
+// auditLog queries the audit log and returns the reply for the user.
+func (b *Bot) auditLog(by, value string) string {
+	by = strings.ToLower(by)
+	value = strings.TrimSpace(value)
+
+	if !helpers.Contains(auditOpts, by) {
+		return fuzzyMatchNonOpt(by, auditOpts)
+	}
+
+	if value == "" {
+		return fmt.Sprintf("Which %s should I get the audit log for?", by)
+	}
+
+	var (
+		events audit.AI
+		err    error
+	)
+
+	switch by {
+	case "user":
+		events, err = b.tables.AuditByUser(b.auditUsers(value)...)
+	case "serial":
+		events, err = b.tables.AuditBySerial(value)
+	case "since":
+		var since time.Time
+		since, err = parseSince(value, time.Now())
+		if err != nil {
+			return err.Error()
+		}
+		events, err = b.tables.AuditSince(since)
+	}
+
+	if err != nil {
+		b.log.Err(err).Str("by", by).Str("value", value).Msg("getting audit log")
+		return "I could not get the audit log."
+	}
+
+	return formatAudit(events, auditLimit)
+}
+
+// auditUsers returns the ids the user may be recorded under. slack mentions
+// are reduced to the id and emails also match the slack id of the user.
+func (b *Bot) auditUsers(value string) []string {
+	if id := strings.TrimSuffix(strings.TrimPrefix(value, "<@"), ">"); id != value {
+		return []string{strings.Split(id, "|")[0]}
+	}
+
+	email := helpers.ExtractEmails(value)
+	if email == "" {
+		return []string{value}
+	}
+
+	ids := []string{email}
+
+	u, err := b.tables.UserByEmail(email)
+	if err != nil {
+		b.log.Debug().AnErr("getting user", err).Str("email", email).Send()
+	}
+	for i := range u {
+		ids = append(ids, u[i].UserSlackID)
+	}
+
+	return ids
+}
+
+// parseSince parses a date (2006-01-02) or a duration before now such as
+// 24h or 7d.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%s is not a date (2006-01-02) or a duration (24h, 7d)", s)
+}
+
+// formatAudit returns a line for each of the last limit events.
+func formatAudit(events audit.AI, limit int) string {
+	if events.Empty() {
+		return "There are no audit events."
+	}
+
+	var lines []string
+	if len(events) > limit {
+		lines = append(lines, fmt.Sprintf("Showing the last %d of %d events.", limit, len(events)))
+		events = events[len(events)-limit:]
+	}
+
+	for i := range events {
+		line := fmt.Sprintf("• %s `%s` by %s",
+			events[i].CreatedAt.UTC().Format("2006-01-02 15:04:05"), events[i].Action, auditActor(events[i].Actor))
+
+		if events[i].TargetUser != "" {
+			line += fmt.Sprintf(" for %s", auditActor(events[i].TargetUser))
+		}
+		if events[i].TargetSerial != "" {
+			line += fmt.Sprintf(" on %s", events[i].TargetSerial)
+		}
+		if events[i].After != "" {
+			line += fmt.Sprintf(" `%s`", events[i].After)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// auditActor mentions slack users and leaves the bot and emails as they are.
+func auditActor(id string) string {
+	if id == audit.System || strings.Contains(id, "@") {
+		return id
+	}
+
+	return fmt.Sprintf("<@%s>", id)
+}
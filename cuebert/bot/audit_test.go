@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/db/audit"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2023, time.June, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2023-06-01", time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC), false},
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"7d", now.AddDate(0, 0, -7), false},
+		{"-1h", time.Time{}, true},
+		{"06-01-2023", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSince(tt.in, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatAudit(t *testing.T) {
+	if got := formatAudit(nil, 2); got != "There are no audit events." {
+		t.Errorf("formatAudit(nil) = %q", got)
+	}
+
+	at := time.Date(2023, time.June, 1, 9, 0, 0, 0, time.UTC)
+	events := audit.AI{
+		{Actor: "U1", Action: audit.StopApproved, TargetUser: "U2", CreatedAt: at},
+		{Actor: audit.System, Action: audit.ReminderDelivered, TargetUser: "U2", TargetSerial: "S1", CreatedAt: at},
+		{Actor: "U1", Action: audit.ExclusionApproved, TargetUser: "U2", TargetSerial: "S1", After: `{"reason":"lab"}`, CreatedAt: at},
+	}
+
+	got := strings.Split(formatAudit(events, 2), "\n")
+	want := []string{
+		"Showing the last 2 of 3 events.",
+		"• 2023-06-01 09:00:00 `reminder_delivered` by cuebert for <@U2> on S1",
+		"• 2023-06-01 09:00:00 `exclusion_approved` by <@U1> for <@U2> on S1 `{\"reason\":\"lab\"}`",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("formatAudit() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/johnmikee/cuebert/db/bot"
 	"github.com/johnmikee/cuebert/pkg/helpers"
-	"github.com/johnmikee/cuebert/pkg/visual"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
 )
@@ -30,7 +29,7 @@ func (b *Bot) initSettings() {
 		Examples:    []string{"start cuebert"},
 		Middlewares: []slacker.CommandMiddlewareHandler{authorizationMiddleware(b.cfg.authUsers)},
 		Handler: func(ctx *slacker.CommandContext) {
-			b.loadPrompt(ctx.Event().UserID, Start)
+			b.loadPrompt(ctx.Context(), ctx.Event().UserID, Start)
 		},
 	}
 	b.bot.AddCommand(definition)
@@ -43,7 +42,7 @@ func (b *Bot) stopper() {
 		Description: "Stop cuebert",
 		Middlewares: []slacker.CommandMiddlewareHandler{authorizationMiddleware(b.cfg.authUsers)},
 		Handler: func(ctx *slacker.CommandContext) {
-			b.stopRequest(ctx.Event().UserID)
+			b.stopRequest(ctx.Context(), ctx.Event().UserID)
 		},
 		HideHelp: false,
 	}
@@ -58,7 +57,7 @@ func (b *Bot) updateConfig() {
 		Examples:    []string{"update config"},
 		Middlewares: []slacker.CommandMiddlewareHandler{authorizationMiddleware(b.cfg.authUsers)},
 		Handler: func(ctx *slacker.CommandContext) {
-			b.loadPrompt(ctx.Event().UserID, Reload)
+			b.loadPrompt(ctx.Context(), ctx.Event().UserID, Reload)
 		},
 	}
 
@@ -105,8 +104,6 @@ func (b *Bot) addExclusion() {
 
 // requestReport returns reports about the fleet
 func (b *Bot) requestReport() {
-	var reportOpts = []string{"os", "manager alerted", "first message sent", "requested reminder"}
-
 	definition := &slacker.CommandDefinition{
 		Command:     "get report <opt>",
 		Description: "Get reports about the fleet",
@@ -114,26 +111,9 @@ func (b *Bot) requestReport() {
 		Middlewares: []slacker.CommandMiddlewareHandler{authorizationMiddleware(b.cfg.authUsers)},
 		Handler: func(ctx *slacker.CommandContext) {
 			opt := ctx.Request().Param("opt")
-			var (
-				vis *visual.PieChartOption
-				err error
-			)
-
-			switch strings.ToLower(opt) {
-			case "os":
-				vis, err = b.BuildOSReport()
-
-			case "manager alerted":
-				vis, err = b.BuildSentReport(Manager)
-
-			case "first message sent":
-				vis, err = b.BuildSentReport(First)
 
-			case "requested reminder":
-				vis, err = b.BuildSentReport(ReminderRequested)
-
-			default:
-				msg := fuzzyMatchNonOpt(opt, reportOpts)
+			if !helpers.Contains(ReportOpts, strings.ToLower(opt)) {
+				msg := fuzzyMatchNonOpt(opt, ReportOpts)
 				_, err := ctx.Response().Reply(msg)
 
 				if err != nil {
@@ -143,6 +123,7 @@ func (b *Bot) requestReport() {
 				return
 			}
 
+			vis, err := b.Report(opt)
 			if err != nil {
 				b.log.Debug().AnErr("building report", err).
 					Send()
@@ -163,7 +144,6 @@ func (b *Bot) requestReport() {
 //
 // getUsersInfo returns information about a user when requested by an admin.
 func (b *Bot) getUsersInfo() {
-	var reportOpts = []string{"slackid", "email"}
 	definition := &slacker.CommandDefinition{
 		Command:     "get users info <opt> {input}",
 		Description: "Get info about a user",
@@ -189,7 +169,7 @@ func (b *Bot) getUsersInfo() {
 				which = helpers.ExtractEmails(which)
 				user, err = b.tables.UserEmail(which)
 			default:
-				msg := fuzzyMatchNonOpt(opt, reportOpts)
+				msg := fuzzyMatchNonOpt(opt, UserInfoOpts)
 				_, err := ctx.Response().Reply(msg)
 
 				if err != nil {
@@ -224,6 +204,16 @@ func (b *Bot) getUsersInfo() {
 					b.log.Err(err).Msg("error getting devices")
 				}
 				attachments = append(attachments, di...)
+			} else if strings.ToLower(opt) == "email" {
+				email = which
+			}
+
+			if email != "" {
+				hi, err := b.History(email)
+				if err != nil {
+					b.log.Err(err).Msg("error getting compliance history")
+				}
+				attachments = append(attachments, hi...)
 			}
 
 			_, err = ctx.Response().Reply(ctx.Event().UserID, slacker.WithAttachments(attachments))
@@ -244,7 +234,7 @@ func (b *Bot) updateUserInfoInteractive() {
 		Examples:    []string{"update user interactive"},
 		Middlewares: []slacker.CommandMiddlewareHandler{authorizationMiddleware(b.cfg.authUsers)},
 		Handler: func(ctx *slacker.CommandContext) {
-			b.wantUpdateUser(ctx.Event().ChannelID)
+			b.wantUpdateUser(ctx.Context(), ctx.Event().ChannelID)
 		},
 	}
 
@@ -3,12 +3,13 @@ package bot
 import (
 	"context"
 	"fmt"
-	"time"
-
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/handlers"
 	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/cuebert/tracing"
 	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/db/bot"
 	"github.com/johnmikee/cuebert/idp"
@@ -19,6 +20,7 @@ import (
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/socketmode"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Bot holds the configuration for the bot as well
@@ -32,6 +34,10 @@ type Bot struct {
 	method        Method
 	statusChan    chan handlers.StatusMessage
 	statusHandler *handlers.StatusHandler
+	socket        *socketState
+	// spans holds the context of the span each interaction is handled in,
+	// keyed by its *slacker.InteractionContext. see Context.
+	spans *sync.Map
 }
 
 type Method interface {
@@ -39,15 +45,18 @@ type Method interface {
 	Updates
 }
 
+// LifeCycle starts and stops the routines. Start and Stop report false
+// when the routines were already running or stopped.
 type LifeCycle interface {
-	Start()
-	Stop()
+	Start() bool
+	Stop() bool
 	Update()
+	Running() bool
 }
 
 type Messaging interface {
 	FirstMessage() string
-	ReminderMessage(rp *ReminderPayload) error
+	ReminderMessage(ctx context.Context, rp *ReminderPayload) error
 }
 
 type Updates interface {
@@ -60,7 +69,7 @@ type Config struct {
 	Cfg           *Cfg
 	SlackBotToken string
 	SlackAppToken string
-	DB            *db.DB
+	DB            db.Store
 	IDP           idp.Provider
 	MDM           mdm.Provider
 	Log           logger.Logger
@@ -82,13 +91,47 @@ func New(config *Config) *Bot {
 		tables:        config.Tables,
 		statusHandler: config.StatusHandler,
 		statusChan:    config.StatusChan,
+		socket:        &socketState{},
+		spans:         &sync.Map{},
+	}
+}
+
+// Campaign returns a copy of the bot that sends the messages for a campaign.
+// The copy shares the slack client with the bot and uses the campaign tables
+// and the options, ex: the campaign policy and deadline.
+func (b *Bot) Campaign(t *tables.Config, opts ...Option) *Bot {
+	cfg := *b.cfg
+	for _, opt := range opts {
+		opt(&cfg)
 	}
+
+	c := *b
+	c.cfg = &cfg
+	c.tables = t
+
+	return &c
+}
+
+// SetMethod sets the method the bot uses to build its messages.
+func (b *Bot) SetMethod(m Method) {
+	b.method = m
 }
 
 func (b *Bot) Client() *slack.Client {
 	return b.bot.SlackClient()
 }
 
+// Context returns the context of the span the interaction is handled in so
+// the calls made while handling it are its children. slacker does not let
+// middleware replace the context of an interaction.
+func (b *Bot) Context(ctx *slacker.InteractionContext) context.Context {
+	if c, ok := b.spans.Load(ctx); ok {
+		return c.(context.Context)
+	}
+
+	return ctx.Context()
+}
+
 func (b *Bot) respondUpdater(msg string, err error) {
 	status := b.statusHandler.GetStatus()
 
@@ -124,9 +167,12 @@ func (b *Bot) Respond() {
 	b.requestReport()
 	b.getUsersInfo()
 	b.updateUserInfoInteractive()
+	b.campaignCommands()
+	b.auditCommands()
 
 	// register the interactive commands and middleware
 	b.bot.AddInteractionMiddleware(b.loggingInteractionMiddleware())
+	b.bot.AddInteractionMiddleware(b.tracingInteractionMiddleware())
 	b.bot.AddInteraction(
 		&slacker.InteractionDefinition{
 			BlockID: RemindMeQuestion,
@@ -153,9 +199,20 @@ func (b *Bot) Respond() {
 		b.interactive(ctx)
 	})
 
+	b.bot.OnConnected(func(socketmode.Event) {
+		b.log.Info().Msg("connected")
+		b.socket.set(true, time.Now())
+	})
+
+	b.bot.OnConnectionError(func(event socketmode.Event) {
+		b.log.Info().Interface("event", event).Msg("connection error")
+		b.socket.set(false, time.Now())
+	})
+
 	// TODO: webhook this error
 	b.bot.OnDisconnected(func(event socketmode.Event) {
 		b.log.Info().Interface("event", event).Msg("disconnected")
+		b.socket.set(false, time.Now())
 	})
 
 	b.bot.UnsupportedCommandHandler(func(ctx *slacker.CommandContext) {
@@ -222,6 +279,34 @@ func (b *Bot) loggingInteractionMiddleware() slacker.InteractionMiddlewareHandle
 	}
 }
 
+// tracingInteractionMiddleware wraps each interaction in a span named for its
+// callback id, or the block id for interactions without one. The handler
+// gets the span from Context.
+func (b *Bot) tracingInteractionMiddleware() slacker.InteractionMiddlewareHandler {
+	return func(next slacker.InteractionHandler) slacker.InteractionHandler {
+		return func(ctx *slacker.InteractionContext) {
+			cb := ctx.Callback()
+
+			id := cb.CallbackID
+			if id == "" && len(cb.ActionCallback.BlockActions) > 0 {
+				id = cb.ActionCallback.BlockActions[0].BlockID
+			}
+
+			c, span := tracing.Start(ctx.Context(), "interaction "+id,
+				attribute.String("slack.callback_id", cb.CallbackID),
+				attribute.String("slack.interaction_type", string(cb.Type)),
+				attribute.String("slack.user", cb.User.ID),
+			)
+			defer span.End()
+
+			b.spans.Store(ctx, c)
+			defer b.spans.Delete(ctx)
+
+			next(ctx)
+		}
+	}
+}
+
 func fuzzyMatchNonOpt(opt string, opts []string) string {
 	maybeMatch := fuzzy.RankFind(opt, opts)
 	var msg string
@@ -241,10 +326,10 @@ func fuzzyMatchNonOpt(opt string, opts []string) string {
 	return msg
 }
 
-func (b *Bot) sendAttachment(attachment slack.Attachment, channel, msg string) {
+func (b *Bot) sendAttachment(ctx context.Context, attachment slack.Attachment, channel, msg string) {
 	message := slack.MsgOptionAttachments(attachment)
 
-	channelID, timestamp, err := b.bot.SlackClient().PostMessage(channel, message)
+	channelID, timestamp, err := b.bot.SlackClient().PostMessageContext(ctx, channel, message)
 	if err != nil {
 		b.log.Err(err).Msg("posting message")
 	}
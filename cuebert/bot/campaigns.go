@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/catalog"
+	"github.com/johnmikee/cuebert/cuebert/policy"
+	"github.com/johnmikee/cuebert/db/campaigns"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/shomali11/slacker/v2"
+)
+
+var campaignOpts = []string{"list", "create", "pause", "resume", "close"}
+
+// campaignCommands lets authorized users run update campaigns alongside the
+// one configured with flags.
+func (b *Bot) campaignCommands() {
+	definition := &slacker.CommandDefinition{
+		Command:     "campaign {action} <args>",
+		Description: "Create, pause, resume, close or list update campaigns",
+		Examples: []string{
+			"campaign list",
+			"campaign create <id> <required-os> <deadline> <cutoff>",
+			"campaign pause <id>",
+			"campaign resume <id>",
+			"campaign close <id>",
+		},
+		Middlewares: []slacker.CommandMiddlewareHandler{authorizationMiddleware(b.cfg.authUsers)},
+		Handler: func(ctx *slacker.CommandContext) {
+			msg := b.campaign(
+				ctx.Event().UserID,
+				ctx.Request().Param("action"),
+				strings.Fields(ctx.Request().Param("args")),
+			)
+
+			_, err := ctx.Response().Reply(msg)
+			if err != nil {
+				b.log.Debug().AnErr("sending campaign response", err).Send()
+			}
+		},
+	}
+
+	b.bot.AddCommand(definition)
+}
+
+// campaign runs the action and returns the reply for the user.
+func (b *Bot) campaign(user, action string, args []string) string {
+	action = strings.ToLower(action)
+
+	if action == "list" {
+		return b.listCampaigns()
+	}
+
+	if !helpers.Contains(campaignOpts, action) {
+		return fuzzyMatchNonOpt(action, campaignOpts)
+	}
+
+	if len(args) == 0 {
+		return fmt.Sprintf("Which campaign should I %s?", action)
+	}
+	id := args[0]
+
+	if action != "create" {
+		if msg := b.campaignChangeable(id, action); msg != "" {
+			return msg
+		}
+	}
+
+	var err error
+	switch action {
+	case "create":
+		var info *campaigns.Info
+		info, err = parseCampaign(args, b.cfg.cutoffTime)
+		if err != nil {
+			return err.Error()
+		}
+		if catalog.IsRequirement(info.RequiredOS) && b.cfg.policy.Catalog() == nil {
+			return fmt.Sprintf("%s needs an os catalog. Set one with `update config` first.", info.RequiredOS)
+		}
+		info.CreatedBy = user
+
+		err = b.tables.AddCampaign(info)
+	case "pause":
+		err = b.tables.SetCampaignStatus(id, campaigns.Paused)
+	case "resume":
+		err = b.tables.SetCampaignStatus(id, campaigns.Active)
+	case "close":
+		err = b.tables.CloseCampaign(id)
+	}
+
+	if err != nil {
+		b.log.Err(err).Str("campaign", id).Str("action", action).Msg("updating campaign")
+		return fmt.Sprintf("I could not %s campaign %s: %s", action, id, err)
+	}
+
+	b.log.Info().Str("campaign", id).Str("action", action).Str("user", user).Msg("campaign updated")
+
+	return fmt.Sprintf("Campaign `%s` is %s.", id, campaignState(action))
+}
+
+// campaignChangeable returns why the campaign cannot be paused, resumed or
+// closed, or an empty string if it can.
+func (b *Bot) campaignChangeable(id, action string) string {
+	cs, err := b.tables.CampaignByID(id)
+	if err != nil {
+		b.log.Err(err).Str("campaign", id).Msg("getting campaign")
+		return fmt.Sprintf("I could not get campaign %s.", id)
+	}
+
+	if cs.Empty() {
+		return fmt.Sprintf("There is no campaign `%s`. See `campaign list`.", id)
+	}
+
+	switch status := cs[0].Status; {
+	case status == campaigns.Closed:
+		return fmt.Sprintf("Campaign `%s` is closed.", id)
+	case action == "pause" && status == campaigns.Paused,
+		action == "resume" && status == campaigns.Active:
+		return fmt.Sprintf("Campaign `%s` is already %s.", id, status)
+	}
+
+	return ""
+}
+
+// listCampaigns returns a line for each campaign that has not been closed.
+func (b *Bot) listCampaigns() string {
+	cs, err := b.tables.Campaigns(campaigns.Active, campaigns.Paused)
+	if err != nil {
+		b.log.Err(err).Msg("getting campaigns")
+		return "I could not get the campaigns."
+	}
+
+	if cs.Empty() {
+		return "There are no open campaigns."
+	}
+
+	lines := make([]string, 0, len(cs))
+	for i := range cs {
+		lines = append(lines, fmt.Sprintf("• `%s` (%s) requires %s by %s %s",
+			cs[i].ID, cs[i].Status, cs[i].RequiredOS, cs[i].Deadline, cs[i].CutoffTime))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// parseCampaign builds an active campaign from `<id> <required-os> <deadline> [cutoff]`.
+// The cutoff time defaults to the one the bot is configured with.
+func parseCampaign(args []string, cutoff string) (*campaigns.Info, error) {
+	if len(args) < 3 || len(args) > 4 {
+		return nil, fmt.Errorf("usage: `campaign create <id> <required-os> <deadline> [cutoff]`")
+	}
+
+	info := &campaigns.Info{
+		ID:         args[0],
+		Name:       args[0],
+		RequiredOS: args[1],
+		Deadline:   args[2],
+		CutoffTime: cutoff,
+		Status:     campaigns.Active,
+	}
+	if len(args) == 4 {
+		info.CutoffTime = args[3]
+	}
+
+	if info.ID == campaigns.Default {
+		return nil, fmt.Errorf("the %s campaign is set with `update config`", campaigns.Default)
+	}
+
+	if _, err := helpers.CompareOSVer(info.RequiredOS, info.RequiredOS); err != nil && !catalog.IsRequirement(info.RequiredOS) {
+		return nil, fmt.Errorf("%s is not a valid version, ex: 13.4.1 or latest-1", info.RequiredOS)
+	}
+
+	due := fmt.Sprintf("%s %s", info.Deadline, info.CutoffTime)
+	if _, err := time.Parse(policy.DeadlineLayout, due); err != nil {
+		return nil, fmt.Errorf("%s is not a valid deadline, ex: 06-30-2023 17:00", due)
+	}
+
+	return info, nil
+}
+
+// campaignState is how the campaign is described after the action.
+func campaignState(action string) string {
+	switch action {
+	case "create":
+		return "active"
+	case "pause":
+		return "paused"
+	case "resume":
+		return "active again"
+	default:
+		return "closed"
+	}
+}
@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/db/campaigns"
+	"github.com/johnmikee/cuebert/db/sqlite"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+func TestParseCampaign(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCutoff string
+		wantErr    bool
+	}{
+		{"default cutoff", []string{"rsr", "13.4.1", "06-30-2023"}, "17:00", false},
+		{"cutoff", []string{"rsr", "13.4.1", "06-30-2023", "09:30"}, "09:30", false},
+		{"latest", []string{"sonoma", "latest-1", "06-30-2023"}, "17:00", false},
+		{"missing deadline", []string{"rsr", "13.4.1"}, "", true},
+		{"too many args", []string{"rsr", "13.4.1", "06-30-2023", "09:30", "extra"}, "", true},
+		{"invalid version", []string{"rsr", "newest", "06-30-2023"}, "", true},
+		{"invalid deadline", []string{"rsr", "13.4.1", "2023-06-30"}, "", true},
+		{"invalid cutoff", []string{"rsr", "13.4.1", "06-30-2023", "5pm"}, "", true},
+		{"default campaign", []string{campaigns.Default, "13.4.1", "06-30-2023"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseCampaign(tt.args, "17:00")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCampaign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if info.ID != tt.args[0] || info.RequiredOS != tt.args[1] || info.Deadline != tt.args[2] {
+				t.Errorf("parseCampaign() = %+v, want %v", info, tt.args)
+			}
+			if info.CutoffTime != tt.wantCutoff {
+				t.Errorf("parseCampaign() cutoff = %s, want %s", info.CutoffTime, tt.wantCutoff)
+			}
+			if info.Status != campaigns.Active {
+				t.Errorf("parseCampaign() status = %s, want %s", info.Status, campaigns.Active)
+			}
+		})
+	}
+}
+
+func TestCampaignStatus(t *testing.T) {
+	store, err := sqlite.Open(filepath.Join(t.TempDir(), "cue.db"))
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	t.Cleanup(store.Close)
+
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+	tbl := tables.New(store, &log)
+	b := New(&Config{Cfg: CfgSetter(), Log: log, Tables: tbl})
+
+	for _, info := range []*campaigns.Info{
+		{ID: "rsr", Name: "rsr", RequiredOS: "13.4.1", Deadline: "06-30-2023", CutoffTime: "17:00", Status: campaigns.Active},
+		{ID: "old", Name: "old", RequiredOS: "13.3", Deadline: "01-30-2023", CutoffTime: "17:00", Status: campaigns.Closed},
+	} {
+		if err := tbl.AddCampaign(info); err != nil {
+			t.Fatalf("adding campaign: %v", err)
+		}
+	}
+
+	tests := []struct {
+		action string
+		id     string
+		want   string
+		status campaigns.Status
+	}{
+		{"pause", "missing", "There is no campaign `missing`. See `campaign list`.", ""},
+		{"resume", "old", "Campaign `old` is closed.", campaigns.Closed},
+		{"close", "old", "Campaign `old` is closed.", campaigns.Closed},
+		{"resume", "rsr", "Campaign `rsr` is already active.", campaigns.Active},
+		{"pause", "rsr", "Campaign `rsr` is paused.", campaigns.Paused},
+		{"pause", "rsr", "Campaign `rsr` is already paused.", campaigns.Paused},
+		{"resume", "rsr", "Campaign `rsr` is active again.", campaigns.Active},
+	}
+
+	for _, tt := range tests {
+		if got := b.campaign("U1", tt.action, []string{tt.id}); got != tt.want {
+			t.Errorf("%s %s: got %q, want %q", tt.action, tt.id, got, tt.want)
+		}
+
+		cs, err := tbl.CampaignByID(tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.status == "" {
+			if !cs.Empty() {
+				t.Errorf("%s %s: got campaign %+v", tt.action, tt.id, cs)
+			}
+			continue
+		}
+		if cs.Empty() || cs[0].Status != tt.status {
+			t.Errorf("%s %s: got campaign %+v, want status %s", tt.action, tt.id, cs, tt.status)
+		}
+	}
+}
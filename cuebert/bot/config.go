@@ -1,27 +1,30 @@
 package bot
 
+import "github.com/johnmikee/cuebert/cuebert/policy"
+
 type Cfg struct {
-	authUsers          []string // comma separated list of users to perform authorized actions
-	authUsersFromIDP   bool     // pull authorized users from the idp. if false use the auth-users flag
-	checkInterval      int      // how often to check what cuebert messages need sending
-	clearTables        bool     // clear all tables
-	cutoffTime         string   // cutoffTime will be the time access is revoked
-	deadline           string   // the day the update is required
-	deviceDiffInterval int      // how often to check what devices we need to add/remove
-	helpDocsURL        string   // url to the help docs
-	helpRepoURL        string   // url to this repo for the help menu
-	helpTicketURL      string   // url to the help ticketing system
-	logLevel           string   // ex: debug
-	logToFile          bool     // log to file defaults to false
-	pollInterval       int      // how often to poll for reminders
-	requiredVers       string   // ex: 13.1
-	slackAlertChannel  string   // the slack channel to send alerts to
-	slackBotID         string   // the slack bot id
-	tableNames         string   // comma separated list of tables to clear
-	testing            bool     // run in testing mode
-	testingEndTime     string   // the hour the messaging should end
-	testingStartTime   string   // the hour the messaging should start
-	testUsers          []string // comma separated list of users to test with
+	authUsers          []string       // comma separated list of users to perform authorized actions
+	authUsersFromIDP   bool           // pull authorized users from the idp. if false use the auth-users flag
+	checkInterval      int            // how often to check what cuebert messages need sending
+	clearTables        bool           // clear all tables
+	cutoffTime         string         // cutoffTime will be the time access is revoked
+	deadline           string         // the day the update is required
+	deviceDiffInterval int            // how often to check what devices we need to add/remove
+	helpDocsURL        string         // url to the help docs
+	helpRepoURL        string         // url to this repo for the help menu
+	helpTicketURL      string         // url to the help ticketing system
+	logLevel           string         // ex: debug
+	logToFile          bool           // log to file defaults to false
+	policy             *policy.Policy // minimum versions and deadlines per model or major version
+	pollInterval       int            // how often to poll for reminders
+	requiredVers       string         // ex: 13.1
+	slackAlertChannel  string         // the slack channel to send alerts to
+	slackBotID         string         // the slack bot id
+	tableNames         string         // comma separated list of tables to clear
+	testing            bool           // run in testing mode
+	testingEndTime     string         // the hour the messaging should end
+	testingStartTime   string         // the hour the messaging should start
+	testUsers          []string       // comma separated list of users to test with
 }
 
 type Option func(*Cfg)
@@ -112,6 +115,12 @@ func WithLogToFile(logToFile bool) Option {
 	}
 }
 
+func WithPolicy(p *policy.Policy) Option {
+	return func(cfg *Cfg) {
+		cfg.policy = p
+	}
+}
+
 func WithPollInterval(interval int) Option {
 	return func(cfg *Cfg) {
 		cfg.pollInterval = interval
@@ -1,10 +1,13 @@
 package bot
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/db/audit"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
@@ -14,26 +17,29 @@ import (
 func (b *Bot) loadInput(ctx *slacker.InteractionContext, loadType string) {
 	values := ctx.Callback().View.State.Values
 	tables := values["table_names"]["table_names_opt"].SelectedOptions
-	level := values["log_level"]["log_level_opt"].SelectedOption.Text.Text
-	b.cfg.authUsers = values["auth_users"]["auth_users_opt"].SelectedUsers
-	b.cfg.authUsersFromIDP = helpers.YNToBool(values["auth_idp"]["auth_idp"].SelectedOption.Text.Text)
-	b.cfg.clearTables = helpers.YNToBool(values["clear_db"]["clear_db"].SelectedOption.Text.Text)
-	b.cfg.tableNames = helpers.OptsToStrs(tables)
-	b.cfg.logLevel = level
-	b.cfg.logToFile = helpers.YNToBool(values["log_to_file"]["log_to_file"].SelectedOption.Text.Text)
-	b.cfg.requiredVers = values["required_version"]["required_version"].Value
-	b.cfg.testing = helpers.YNToBool(values["testing"]["testing"].SelectedOption.Text.Text)
-	b.cfg.testingStartTime = values["testing_start"]["testing_start"].SelectedTime
-	b.cfg.testingEndTime = values["testing_end"]["testing_end"].SelectedTime
-	b.cfg.testUsers = values["testing_users"]["testing_users_opt"].SelectedUsers
-	b.cfg.deadline = values["date_picker"]["date_picker"].SelectedDate
-	b.cfg.cutoffTime = values["cutoff_time"]["cutoff_time_picker"].SelectedTime
-	b.cfg.deviceDiffInterval = helpers.ValToInt(values["device_diff_interval"]["device_diff_interval"].Value)
-	b.cfg.checkInterval = helpers.ValToInt(values["check_interval"]["check_interval"].Value)
-	b.cfg.pollInterval = helpers.ValToInt(values["poll_interval"]["poll_interval"].Value)
-	b.cfg.helpDocsURL = values["docs_url"]["docs_url"].Value
-	b.cfg.helpRepoURL = values["help_url"]["help_url"].Value
-	b.cfg.helpTicketURL = values["repo_url"]["repo_url"].Value
+
+	b.setConfig(ctx.Callback().User.ID, ConfigState{
+		AuthUsers:          values["auth_users"]["auth_users_opt"].SelectedUsers,
+		AuthUsersFromIDP:   helpers.YNToBool(values["auth_idp"]["auth_idp"].SelectedOption.Text.Text),
+		ClearTables:        helpers.YNToBool(values["clear_db"]["clear_db"].SelectedOption.Text.Text),
+		TableNames:         helpers.OptsToStrs(tables),
+		LogLevel:           values["log_level"]["log_level_opt"].SelectedOption.Text.Text,
+		LogToFile:          helpers.YNToBool(values["log_to_file"]["log_to_file"].SelectedOption.Text.Text),
+		RequiredVers:       values["required_version"]["required_version"].Value,
+		Testing:            helpers.YNToBool(values["testing"]["testing"].SelectedOption.Text.Text),
+		TestingStartTime:   values["testing_start"]["testing_start"].SelectedTime,
+		TestingEndTime:     values["testing_end"]["testing_end"].SelectedTime,
+		TestUsers:          values["testing_users"]["testing_users_opt"].SelectedUsers,
+		Deadline:           values["date_picker"]["date_picker"].SelectedDate,
+		CutoffTime:         values["cutoff_time"]["cutoff_time_picker"].SelectedTime,
+		DeviceDiffInterval: helpers.ValToInt(values["device_diff_interval"]["device_diff_interval"].Value),
+		CheckInterval:      helpers.ValToInt(values["check_interval"]["check_interval"].Value),
+		PollInterval:       helpers.ValToInt(values["poll_interval"]["poll_interval"].Value),
+		HelpDocsURL:        values["docs_url"]["docs_url"].Value,
+		HelpRepoURL:        values["help_url"]["help_url"].Value,
+		HelpTicketURL:      values["repo_url"]["repo_url"].Value,
+		OSCatalog:          values["os_catalog"]["os_catalog"].Value,
+	})
 
 	switch loadType {
 	case Start:
@@ -43,9 +49,141 @@ func (b *Bot) loadInput(ctx *slacker.InteractionContext, loadType string) {
 	}
 }
 
-func (b *Bot) loadProgram(triggerID, loadType string) {
+// UpdateConfig replaces the config on behalf of the actor and reloads it.
+func (b *Bot) UpdateConfig(actor string, c ConfigState) {
+	b.setConfig(actor, c)
+	b.lifecycle.Update()
+}
+
+// setConfig replaces the config and records the change in the audit log.
+func (b *Bot) setConfig(actor string, c ConfigState) {
+	before := b.ConfigState()
+
+	b.cfg.authUsers = c.AuthUsers
+	b.cfg.authUsersFromIDP = c.AuthUsersFromIDP
+	b.cfg.checkInterval = c.CheckInterval
+	b.cfg.clearTables = c.ClearTables
+	b.cfg.cutoffTime = c.CutoffTime
+	b.cfg.deadline = c.Deadline
+	b.cfg.deviceDiffInterval = c.DeviceDiffInterval
+	b.cfg.helpDocsURL = c.HelpDocsURL
+	b.cfg.helpRepoURL = c.HelpRepoURL
+	b.cfg.helpTicketURL = c.HelpTicketURL
+	b.cfg.logLevel = c.LogLevel
+	b.cfg.logToFile = c.LogToFile
+	b.cfg.pollInterval = c.PollInterval
+	b.cfg.requiredVers = c.RequiredVers
+	b.cfg.tableNames = c.TableNames
+	b.cfg.testing = c.Testing
+	b.cfg.testingEndTime = c.TestingEndTime
+	b.cfg.testingStartTime = c.TestingStartTime
+	b.cfg.testUsers = c.TestUsers
+	b.loadCatalog(c.OSCatalog)
+
+	b.Audit(actor, audit.ConfigUpdated, "", "", before, b.ConfigState())
+}
+
+// ConfigState is the part of the config the modal and the admin api can
+// change. it is recorded before and after every change in the audit log.
+type ConfigState struct {
+	AuthUsers          []string `json:"auth_users"`
+	AuthUsersFromIDP   bool     `json:"auth_users_from_idp"`
+	CheckInterval      int      `json:"check_interval"`
+	ClearTables        bool     `json:"clear_tables"`
+	CutoffTime         string   `json:"cutoff_time"`
+	Deadline           string   `json:"deadline"`
+	DeviceDiffInterval int      `json:"device_diff_interval"`
+	HelpDocsURL        string   `json:"help_docs_url"`
+	HelpRepoURL        string   `json:"help_repo_url"`
+	HelpTicketURL      string   `json:"help_ticket_url"`
+	LogLevel           string   `json:"log_level"`
+	LogToFile          bool     `json:"log_to_file"`
+	OSCatalog          string   `json:"os_catalog,omitempty"`
+	PollInterval       int      `json:"poll_interval"`
+	RequiredVers       string   `json:"required_version"`
+	TableNames         string   `json:"table_names"`
+	Testing            bool     `json:"testing"`
+	TestingEndTime     string   `json:"testing_end_time"`
+	TestingStartTime   string   `json:"testing_start_time"`
+	TestUsers          []string `json:"test_users"`
+}
+
+// ConfigState returns the config the modal and the admin api can change.
+func (b *Bot) ConfigState() ConfigState {
+	c := ConfigState{
+		AuthUsers:          b.cfg.authUsers,
+		AuthUsersFromIDP:   b.cfg.authUsersFromIDP,
+		CheckInterval:      b.cfg.checkInterval,
+		ClearTables:        b.cfg.clearTables,
+		CutoffTime:         b.cfg.cutoffTime,
+		Deadline:           b.cfg.deadline,
+		DeviceDiffInterval: b.cfg.deviceDiffInterval,
+		HelpDocsURL:        b.cfg.helpDocsURL,
+		HelpRepoURL:        b.cfg.helpRepoURL,
+		HelpTicketURL:      b.cfg.helpTicketURL,
+		LogLevel:           b.cfg.logLevel,
+		LogToFile:          b.cfg.logToFile,
+		PollInterval:       b.cfg.pollInterval,
+		RequiredVers:       b.cfg.requiredVers,
+		TableNames:         b.cfg.tableNames,
+		Testing:            b.cfg.testing,
+		TestingEndTime:     b.cfg.testingEndTime,
+		TestingStartTime:   b.cfg.testingStartTime,
+		TestUsers:          b.cfg.testUsers,
+	}
+
+	if b.cfg.policy != nil {
+		if cat := b.cfg.policy.Catalog(); cat != nil {
+			c.OSCatalog = cat.Source
+		}
+	}
+
+	return c
+}
+
+// loadCatalog switches to the OS release catalog at source if it changed.
+func (b *Bot) loadCatalog(source string) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return
+	}
+
+	if cur := b.cfg.policy.Catalog(); cur != nil && cur.Source == source {
+		return
+	}
+
+	if err := b.cfg.policy.LoadCatalog(source); err != nil {
+		b.log.Err(err).Str("source", source).Msg("could not load os catalog")
+		return
+	}
+
+	b.log.Info().Str("source", source).Msg("loaded os catalog")
+}
+
+// requiredVersions describes the required version and, when it comes from
+// the catalog, the version chosen for each major version.
+func (b *Bot) requiredVersions() string {
+	text := fmt.Sprintf("*Required Version:* %s", b.cfg.policy.Default.Minimum)
+
+	cat := b.cfg.policy.Catalog()
+	if cat == nil {
+		return text
+	}
+
+	if mins := b.cfg.policy.Minimums(); mins != nil {
+		for _, m := range cat.Majors() {
+			text += fmt.Sprintf("\n• %s.x ➜ %s", m, mins[m])
+		}
+	}
+
+	return text + fmt.Sprintf("\n*OS Catalog:* %s (loaded %s)", cat.Source, cat.Loaded.Format(time.RFC1123))
+}
+
+func (b *Bot) loadProgram(ctx context.Context, triggerID, loadType string) {
 	headerText := slack.NewTextBlockObject(slack.MarkdownType, "Modify the program", false, false)
 	headerSection := slack.NewSectionBlock(headerText, nil, nil)
+	requiredText := slack.NewTextBlockObject(slack.MarkdownType, b.requiredVersions(), false, false)
+	requiredSection := slack.NewSectionBlock(requiredText, nil, nil)
 	// yes/no options
 	//
 	// auth from IDP
@@ -113,6 +251,15 @@ func (b *Bot) loadProgram(triggerID, loadType string) {
 	requiredVersionPlaceHolder := slack.NewTextBlockObject(slack.PlainTextType, "ex: 13.4", false, false)
 	requiredVersionBlock := slack.NewPlainTextInputBlockElement(requiredVersionPlaceHolder, "required_version")
 	requiredVersionInput := slack.NewInputBlock("required_version", requiredVersion, nil, requiredVersionBlock)
+	// os catalog path or url. leave empty to keep the current one
+	osCatalog := slack.NewTextBlockObject(slack.PlainTextType, "OS Catalog", false, false)
+	osCatalogPlaceHolder := slack.NewTextBlockObject(slack.PlainTextType, "ex: /etc/cuebert/macos_data_feed.json", false, false)
+	osCatalogBlock := slack.NewPlainTextInputBlockElement(osCatalogPlaceHolder, "os_catalog")
+	if cat := b.cfg.policy.Catalog(); cat != nil {
+		osCatalogBlock.InitialValue = cat.Source
+	}
+	osCatalogInput := slack.NewInputBlock("os_catalog", osCatalog, nil, osCatalogBlock)
+	osCatalogInput.Optional = true
 	// testing start time
 	testingStart := slack.NewTextBlockObject(slack.PlainTextType, "Testing Start Time", false, false)
 	testingStartPlaceHolder := slack.NewTextBlockObject(slack.PlainTextType, "ex: 9:00 AM", false, false)
@@ -161,6 +308,7 @@ func (b *Bot) loadProgram(triggerID, loadType string) {
 	blocks := slack.Blocks{
 		BlockSet: []slack.Block{
 			headerSection,
+			requiredSection,
 			slack.NewDividerBlock(),
 			authIDPBlock,
 			clearDBBlock,
@@ -175,6 +323,7 @@ func (b *Bot) loadProgram(triggerID, loadType string) {
 			cutoffTimeInput,
 			deadlineBlock,
 			requiredVersionInput,
+			osCatalogInput,
 			testingStartInput,
 			testingEndInput,
 			checkIntervalInput,
@@ -197,7 +346,7 @@ func (b *Bot) loadProgram(triggerID, loadType string) {
 	}
 
 	// open the modal
-	vr, err := b.bot.SlackClient().OpenView(triggerID, modalRequest)
+	vr, err := b.bot.SlackClient().OpenViewContext(ctx, triggerID, modalRequest)
 	if err != nil {
 		b.log.Error().Err(err).Msg("error opening modal")
 	}
@@ -206,8 +355,9 @@ func (b *Bot) loadProgram(triggerID, loadType string) {
 }
 
 // loadPrompt leads us to the modal to update cuebert
-func (b *Bot) loadPrompt(user, callback string) {
+func (b *Bot) loadPrompt(ctx context.Context, user, callback string) {
 	b.modalGateway(
+		ctx,
 		&modalGateway{
 			text:       "Do you want to update the configuration?",
 			callbackID: callback,
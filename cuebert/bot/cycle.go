@@ -1,17 +1,63 @@
 package bot
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
+	"github.com/johnmikee/cuebert/db/audit"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
 )
 
+var (
+	// ErrRunning is returned when starting cuebert while it runs.
+	ErrRunning = errors.New("cuebert is already running")
+	// ErrNotRunning is returned when stopping cuebert while it is stopped.
+	ErrNotRunning = errors.New("cuebert is not running")
+)
+
+// Start starts cuebert on behalf of the actor.
+func (b *Bot) Start(actor string) error {
+	if !b.lifecycle.Start() {
+		return ErrRunning
+	}
+
+	b.Audit(actor, audit.Started, "", "", nil, nil)
+
+	return nil
+}
+
+// Stop stops cuebert on behalf of the actor without the approval asked for
+// in slack. The alert channel is told who stopped it.
+func (b *Bot) Stop(actor string) error {
+	if !b.lifecycle.Stop() {
+		return ErrNotRunning
+	}
+
+	_, _, err := b.bot.SlackClient().PostMessage(
+		b.cfg.slackAlertChannel,
+		slack.MsgOptionText(fmt.Sprintf("Cuebert stopped by %s from the admin api. :white_check_mark:", actor), false),
+	)
+	if err != nil {
+		b.log.Err(err).Msg("posting message")
+	}
+
+	b.Audit(actor, audit.Stopped, "", "", nil, nil)
+
+	return nil
+}
+
 // overrideSubmit acknowledges the stop request and stops cuebert
 func (b *Bot) overrideSubmit(ctx *slacker.InteractionContext) {
-	_, _, _ = b.bot.SlackClient().DeleteMessage(ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
+	_, _, _ = b.bot.SlackClient().DeleteMessageContext(b.Context(ctx), ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
 
-	_, _, err := b.bot.SlackClient().PostMessage(
+	if !b.overrideStop(ctx.Callback().User.ID, ctx.Callback().ActionCallback.AttachmentActions[0].Value) {
+		return
+	}
+
+	_, _, err := b.bot.SlackClient().PostMessageContext(
+		b.Context(ctx),
 		b.cfg.slackAlertChannel,
 		slack.MsgOptionText(
 			fmt.Sprintf(
@@ -22,17 +68,36 @@ func (b *Bot) overrideSubmit(ctx *slacker.InteractionContext) {
 		b.log.Err(err).Msg("posting message")
 	}
 
-	b.lifecycle.Stop()
 	b.log.Info().Msg("cuebert stop approved")
 }
 
+// overrideStop stops cuebert on behalf of the user without a second approval
+// when the override was confirmed, not cancelled. It reports if cuebert was
+// stopped, the override is only audited then.
+func (b *Bot) overrideStop(user, value string) bool {
+	if value != BypassOverrideYes {
+		b.log.Info().Msg("cuebert stop override cancelled")
+		return false
+	}
+
+	if !b.lifecycle.Stop() {
+		b.log.Info().Msg("cuebert is not running")
+		return false
+	}
+
+	b.Audit(user, audit.StopOverride, "", "", nil, nil)
+
+	return true
+}
+
 // note who submitted the stop request and present an approval message
 func (b *Bot) stopApprover(ctx *slacker.InteractionContext) {
-	_, _, _ = b.bot.SlackClient().DeleteMessage(ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
+	_, _, _ = b.bot.SlackClient().DeleteMessageContext(b.Context(ctx), ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
 
 	user := ctx.Callback().User.ID
 
 	b.modalGateway(
+		b.Context(ctx),
 		&modalGateway{
 			text:       fmt.Sprintf("Do you approve stopping cuebert? Requested by: <@%s>", user),
 			fallback:   user,
@@ -53,8 +118,9 @@ func (b *Bot) stopApprover(ctx *slacker.InteractionContext) {
 
 // sometimes you just gotta break the rules. this allows us to bypass the approval process.
 // maybe cuebert went sentient. maybe we just need to stop cuebert now.
-func (b *Bot) stopApprovalOverride() {
+func (b *Bot) stopApprovalOverride(ctx context.Context) {
 	b.modalGateway(
+		ctx,
 		&modalGateway{
 			text:       "Are you sure you want to bypass the approval process and stop cuebert?",
 			callbackID: StopCuebertOverride,
@@ -73,8 +139,9 @@ func (b *Bot) stopApprovalOverride() {
 }
 
 // send the stop request
-func (b *Bot) stopRequest(user string) {
+func (b *Bot) stopRequest(ctx context.Context, user string) {
 	b.modalGateway(
+		ctx,
 		&modalGateway{
 			text:       "Are you sure you want to stop cuebert?",
 			callbackID: StopCuebertRequest,
@@ -105,23 +172,26 @@ func (b *Bot) stopSubmit(ctx *slacker.InteractionContext) {
 
 	switch action.Value {
 	case ApproveStop:
-		_, _, _ = b.bot.SlackClient().DeleteMessage(ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
+		_, _, _ = b.bot.SlackClient().DeleteMessageContext(b.Context(ctx), ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
 		if approver != requester {
-			b.log.Info().Msg("cuebert stop approved")
-			b.lifecycle.Stop()
+			if b.lifecycle.Stop() {
+				b.log.Info().Msg("cuebert stop approved")
+				b.Audit(approver, audit.StopApproved, requester, "", nil, nil)
+			}
 		} else {
-			b.stopApprovalOverride()
+			b.stopApprovalOverride(b.Context(ctx))
 		}
 
 	case DenyStop:
-		_, _, _ = b.bot.SlackClient().DeleteMessage(ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
+		_, _, _ = b.bot.SlackClient().DeleteMessageContext(b.Context(ctx), ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
 
-		_, _, err := b.bot.SlackClient().PostMessage(ctx.Callback().User.ID,
+		_, _, err := b.bot.SlackClient().PostMessageContext(b.Context(ctx), ctx.Callback().User.ID,
 			slack.MsgOptionText("Cuebert stop denied :white_check_mark:", false))
 		if err != nil {
 			b.log.Err(err).Msg("posting message")
 		}
 
+		b.Audit(approver, audit.StopDenied, requester, "", nil, nil)
 		b.log.Info().Msg("cuebert stop denied")
 	}
 }
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/db/audit"
+	"github.com/johnmikee/cuebert/db/sqlite"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+type lifeCycle struct {
+	running bool
+}
+
+func (l *lifeCycle) Start() bool {
+	started := !l.running
+	l.running = true
+
+	return started
+}
+
+func (l *lifeCycle) Stop() bool {
+	stopped := l.running
+	l.running = false
+
+	return stopped
+}
+
+func (l *lifeCycle) Update()       {}
+func (l *lifeCycle) Running() bool { return l.running }
+
+func TestOverrideStop(t *testing.T) {
+	store, err := sqlite.Open(filepath.Join(t.TempDir(), "cue.db"))
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	t.Cleanup(store.Close)
+
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+	tbl := tables.New(store, &log)
+	lc := &lifeCycle{running: true}
+	b := New(&Config{Cfg: CfgSetter(), Log: log, Tables: tbl, LifeCycle: lc})
+
+	if b.overrideStop("U1", BypassOverrideNo) || !lc.running {
+		t.Fatal("cancelling the override stopped cuebert")
+	}
+
+	if !b.overrideStop("U1", BypassOverrideYes) || lc.running {
+		t.Fatal("confirming the override did not stop cuebert")
+	}
+
+	// cuebert is already stopped.
+	if b.overrideStop("U1", BypassOverrideYes) {
+		t.Error("stopping a stopped cuebert reported a stop")
+	}
+
+	events, err := tbl.AuditSince(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Action != audit.StopOverride || events[0].Actor != "U1" {
+		t.Errorf("got audit events: %+v", events)
+	}
+}
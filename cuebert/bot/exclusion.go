@@ -1,9 +1,12 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/johnmikee/cuebert/cuebert/metrics"
+	"github.com/johnmikee/cuebert/db/audit"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
 )
@@ -44,7 +47,7 @@ func (b *Bot) exclusionHelp() {
 
 // exclusionRequest is the modal the user will see when they request an exclusion
 // the results of this modal will be sent to exclusionRequestDecision
-func (b *Bot) exclusionRequest(devices []string, triggerID string) {
+func (b *Bot) exclusionRequest(ctx context.Context, devices []string, triggerID string) {
 	headerSection := slack.SectionBlock{
 		Type: slack.MBTSection,
 		Text: &slack.TextBlockObject{
@@ -147,7 +150,7 @@ func (b *Bot) exclusionRequest(devices []string, triggerID string) {
 		NotifyOnClose: false,
 	}
 
-	vr, err := b.bot.SlackClient().OpenView(triggerID, modalRequest)
+	vr, err := b.bot.SlackClient().OpenViewContext(ctx, triggerID, modalRequest)
 	if err != nil {
 		b.log.Err(err).Send()
 	}
@@ -181,15 +184,21 @@ func (b *Bot) userExclusionSubmit(ctx *slacker.InteractionContext) {
 	err = b.tables.RequestExclusion(ctx.Callback().User.ID, reason, serials, ts)
 	if err != nil {
 		b.log.Err(err).Msg("adding exclusion request to db")
+	} else {
+		for _, serial := range serials {
+			metrics.ExclusionDecision(metrics.ExclusionRequested)
+			b.Audit(user, audit.ExclusionRequested, user, serial, nil,
+				map[string]any{"reason": reason, "until": dv})
+		}
 	}
 
-	_, _, err = b.bot.SlackClient().PostMessage(ctx.Callback().User.ID,
+	_, _, err = b.bot.SlackClient().PostMessageContext(b.Context(ctx), ctx.Callback().User.ID,
 		slack.MsgOptionText("Your request has been submitted :white_check_mark:", false))
 	if err != nil {
 		b.log.Err(err).Msg("posting ack for exclusion request")
 	}
 
-	b.exclusionApprove(user, reason, dv, serials)
+	b.exclusionApprove(b.Context(ctx), user, reason, dv, serials)
 }
 
 func exclusionSubmitSerials(callback *slack.InteractionCallback) []string {
@@ -1,18 +1,22 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/johnmikee/cuebert/cuebert/metrics"
+	"github.com/johnmikee/cuebert/db/audit"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
 )
 
 // exclusionApprove allows those in the approver channel to approve or deny the exclusion.
-func (b *Bot) exclusionApprove(user, reason, until string, serials []string) {
+func (b *Bot) exclusionApprove(ctx context.Context, user, reason, until string, serials []string) {
 	attachment := slack.Attachment{
 		Title:      fmt.Sprintf("<@%s> is requesting an exclusion.", user),
+		Fallback:   user,
 		CallbackID: ExclusionApprover,
 		Color:      "#3AA3E3",
 		Fields: []slack.AttachmentField{
@@ -49,7 +53,7 @@ func (b *Bot) exclusionApprove(user, reason, until string, serials []string) {
 
 	message := slack.MsgOptionAttachments(attachment)
 
-	channelID, timestamp, err := b.bot.SlackClient().PostMessage(b.cfg.slackAlertChannel, message)
+	channelID, timestamp, err := b.bot.SlackClient().PostMessageContext(ctx, b.cfg.slackAlertChannel, message)
 	if err != nil {
 		b.log.Err(err).Msg("posting message")
 	}
@@ -60,11 +64,24 @@ func (b *Bot) exclusionApprove(user, reason, until string, serials []string) {
 		Msg("exclusion approval message sent")
 }
 
+// exclusionRequester returns who asked for the exclusion, kept in the
+// fallback of the request so the denial is sent to them and not to the
+// approver. Requests posted before it was are answered to the approver.
+func exclusionRequester(cb *slack.InteractionCallback) string {
+	if len(cb.OriginalMessage.Attachments) > 0 && cb.OriginalMessage.Attachments[0].Fallback != "" {
+		return cb.OriginalMessage.Attachments[0].Fallback
+	}
+
+	return cb.User.ID
+}
+
 // exclusionRequestDecision handles the decision to approve or deny an exclusion
 func (b *Bot) exclusionRequestDecision(ctx *slacker.InteractionContext) {
 	action := ctx.Callback().ActionCallback.AttachmentActions[0].Value
 
 	vals := ctx.Callback().OriginalMessage.Attachments[0].Fields
+	approver := ctx.Callback().User.ID
+	requester := exclusionRequester(ctx.Callback())
 
 	var (
 		reason  string
@@ -95,12 +112,14 @@ func (b *Bot) exclusionRequestDecision(ctx *slacker.InteractionContext) {
 
 	switch action {
 	case "approve_exclusion":
-		b.log.Info().Msgf("%s - approving exclusion", ctx.Callback().User.ID)
+		b.log.Info().Msgf("%s - approving exclusion", approver)
 
-		_, _, _ = b.bot.SlackClient().DeleteMessage(ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
+		_, _, _ = b.bot.SlackClient().DeleteMessageContext(b.Context(ctx), ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
 
 		for _, serial := range serialSlice {
-			info, err := b.tables.DeviceBySerial(strings.Trim(serial, " "))
+			serial = strings.TrimSpace(serial)
+
+			info, err := b.tables.DeviceBySerial(serial)
 			if err != nil {
 				b.log.Err(err).Msgf("could not get serial info for %s", serial)
 				continue
@@ -115,13 +134,22 @@ func (b *Bot) exclusionRequestDecision(ctx *slacker.InteractionContext) {
 				return
 			}
 
+			before, err := b.tables.SerialExcluded(serial)
+			if err != nil {
+				b.log.Err(err).Msgf("could not get exclusion for %s", serial)
+			}
+
 			err = b.tables.ApproveExclusion(reason, serial, ts)
 
 			if err != nil {
 				b.log.Err(err).Msgf("could not approve exclusion for %s", serial)
+			} else {
+				metrics.ExclusionDecision(metrics.ExclusionApproved)
+				b.Audit(approver, audit.ExclusionApproved, requester, serial, before,
+					map[string]any{"reason": reason, "until": until, "approved": true})
 			}
 
-			_, _, err = b.bot.SlackClient().PostMessage(slackid[0].UserSlackID,
+			_, _, err = b.bot.SlackClient().PostMessageContext(b.Context(ctx), slackid[0].UserSlackID,
 				slack.MsgOptionText("Your request for an exclusion has been approved :white_check_mark:", false))
 			if err != nil {
 				b.log.Err(err).Msg("posting exclusion approval")
@@ -129,9 +157,15 @@ func (b *Bot) exclusionRequestDecision(ctx *slacker.InteractionContext) {
 		}
 
 	case "deny_exclusion":
-		_, _, _ = b.bot.SlackClient().DeleteMessage(ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
+		_, _, _ = b.bot.SlackClient().DeleteMessageContext(b.Context(ctx), ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
+
+		for _, serial := range serialSlice {
+			metrics.ExclusionDecision(metrics.ExclusionDenied)
+			b.Audit(approver, audit.ExclusionDenied, requester, strings.TrimSpace(serial), nil,
+				map[string]any{"reason": reason, "until": until})
+		}
 
-		_, _, err = b.bot.SlackClient().PostMessage(ctx.Callback().User.ID,
+		_, _, err = b.bot.SlackClient().PostMessageContext(b.Context(ctx), requester,
 			slack.MsgOptionText("Your request for an exclusion has been denied :octagonal_sign:", false))
 		if err != nil {
 			b.log.Err(err).Msg("posting exclusion denial")
@@ -140,14 +174,14 @@ func (b *Bot) exclusionRequestDecision(ctx *slacker.InteractionContext) {
 	default:
 		b.log.Debug().Msgf("got an unknown response from exclusion approval")
 
-		_, _, _ = b.bot.SlackClient().DeleteMessage(ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
+		_, _, _ = b.bot.SlackClient().DeleteMessageContext(b.Context(ctx), ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
 	}
 
 }
 
 // exclusionRequest is the modal presented to the admins who requested to add
 // an exclusion to the exclusion list to load the exclusion into the database.
-func (b *Bot) exclusionAdd(triggerID string) {
+func (b *Bot) exclusionAdd(ctx context.Context, triggerID string) {
 	headerText := slack.NewTextBlockObject(slack.MarkdownType, "Add Exclusion", false, false)
 	headerSection := slack.NewSectionBlock(headerText, nil, nil)
 
@@ -250,7 +284,7 @@ func (b *Bot) exclusionAdd(triggerID string) {
 		CallbackID: AdminExclusionModal,
 	}
 
-	vr, err := b.bot.SlackClient().OpenView(triggerID, modalRequest)
+	vr, err := b.bot.SlackClient().OpenViewContext(ctx, triggerID, modalRequest)
 	if err != nil {
 		b.log.Err(err).Send()
 	}
@@ -261,7 +295,7 @@ func (b *Bot) exclusionAdd(triggerID string) {
 func (b *Bot) exclusionRequested(ctx *slacker.InteractionContext) {
 	action := ctx.Callback().ActionCallback.BlockActions[0]
 
-	_, _, err := b.bot.SlackClient().DeleteMessage(ctx.Callback().Channel.ID, ctx.Callback().Message.Timestamp)
+	_, _, err := b.bot.SlackClient().DeleteMessageContext(b.Context(ctx), ctx.Callback().Channel.ID, ctx.Callback().Message.Timestamp)
 	if err != nil {
 		b.log.Err(err).Msg("deleting exclusion request message")
 	}
@@ -272,7 +306,7 @@ func (b *Bot) exclusionRequested(ctx *slacker.InteractionContext) {
 		b.log.Info().Msgf("%s wants to request an exclusion", ctx.Callback().User.ID)
 
 		if len(devices) == 0 {
-			_, _, err := b.bot.SlackClient().PostMessage(ctx.Callback().User.ID,
+			_, _, err := b.bot.SlackClient().PostMessageContext(b.Context(ctx), ctx.Callback().User.ID,
 				slack.MsgOptionText("You have no devices to request an exclusion for", false))
 			if err != nil {
 				b.log.Err(err).Msg("posting exclusion denial")
@@ -280,11 +314,11 @@ func (b *Bot) exclusionRequested(ctx *slacker.InteractionContext) {
 			return
 		}
 
-		b.exclusionRequest(devices, ctx.Callback().TriggerID)
+		b.exclusionRequest(b.Context(ctx), devices, ctx.Callback().TriggerID)
 	case YesAddExclusion:
 		b.log.Debug().Msgf("%s wants to add an exclusion", ctx.Callback().User.ID)
 
-		b.exclusionAdd(ctx.Callback().TriggerID)
+		b.exclusionAdd(b.Context(ctx), ctx.Callback().TriggerID)
 	}
 }
 
@@ -313,14 +347,50 @@ func (b *Bot) exclusionSubmit(ctx *slacker.InteractionContext) {
 	}
 
 	// first we need to check if the serial exists
-	err = b.tables.AddExclusion(serial, reason, ts)
+	err = b.AddExclusion(ctx.Callback().User.ID, serial, reason, ts)
 	if err != nil {
 		b.log.Err(err).Str("adding exclusion", "failed").Send()
 	}
 
-	_, _, err = b.bot.SlackClient().PostMessage(ctx.Callback().User.ID,
+	_, _, err = b.bot.SlackClient().PostMessageContext(b.Context(ctx), ctx.Callback().User.ID,
 		slack.MsgOptionText("exclusion has been submitted :white_check_mark:", false))
 	if err != nil {
 		b.log.Err(err).Msg("posting ack for exclusion request")
 	}
 }
+
+// AddExclusion excludes the device until the time on behalf of the actor.
+func (b *Bot) AddExclusion(actor, serial, reason string, until time.Time) error {
+	err := b.tables.AddExclusion(serial, reason, until)
+	if err != nil {
+		return err
+	}
+
+	metrics.ExclusionDecision(metrics.ExclusionAdded)
+	b.Audit(actor, audit.ExclusionAdded, "", serial, nil,
+		map[string]any{"reason": reason, "until": until.Format("2006-01-02")})
+
+	return nil
+}
+
+// RevokeExclusion removes the exclusions of the device on behalf of the
+// actor so it is reminded again.
+func (b *Bot) RevokeExclusion(actor, serial string) error {
+	before, err := b.tables.ExclusionBy().Serial(serial).Query()
+	if err != nil {
+		return err
+	}
+	if before.Empty() {
+		return fmt.Errorf("%s is not excluded", serial)
+	}
+
+	_, err = b.tables.RemoveExclusion().Serial(serial).Execute()
+	if err != nil {
+		return err
+	}
+
+	metrics.ExclusionDecision(metrics.ExclusionRevoked)
+	b.Audit(actor, audit.ExclusionRevoked, before[0].UserEmail, serial, before, nil)
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/db/audit"
+	"github.com/johnmikee/cuebert/db/devices"
+	"github.com/johnmikee/cuebert/db/sqlite"
+	"github.com/johnmikee/cuebert/pkg/logger"
+	"github.com/slack-go/slack"
+)
+
+func TestRevokeExclusion(t *testing.T) {
+	store, err := sqlite.Open(filepath.Join(t.TempDir(), "cue.db"))
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	t.Cleanup(store.Close)
+
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+
+	_, err = devices.Device(store, &log).AddAllDevices(devices.DI{
+		{DeviceID: "d1", DeviceName: "old", Model: "Mac14,2", SerialNumber: "S1", Platform: "Mac", OSVersion: "13.3", User: "old@example.com", UserMDMID: "1"},
+	})
+	if err != nil {
+		t.Fatalf("adding devices: %v", err)
+	}
+
+	tbl := tables.New(store, &log)
+	b := New(&Config{Cfg: CfgSetter(), Log: log, Tables: tbl})
+
+	if err := b.RevokeExclusion("cli", "S1"); err == nil {
+		t.Error("revoking a device that is not excluded did not fail")
+	}
+
+	if err := b.AddExclusion("cli", "S1", "lab", time.Now().AddDate(0, 1, 0)); err != nil {
+		t.Fatalf("adding exclusion: %v", err)
+	}
+	if err := b.RevokeExclusion("cli", "S1"); err != nil {
+		t.Fatalf("revoking exclusion: %v", err)
+	}
+
+	ex, err := tbl.SerialExcluded("S1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ex.Empty() {
+		t.Errorf("got exclusions after revoking: %+v", ex)
+	}
+
+	events, err := tbl.AuditSince(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[1].Action != audit.ExclusionRevoked || events[1].TargetUser != "old@example.com" {
+		t.Errorf("got audit events: %+v", events)
+	}
+}
+
+func TestExclusionRequester(t *testing.T) {
+	cb := &slack.InteractionCallback{User: slack.User{ID: "UAPPROVER"}}
+	cb.OriginalMessage.Attachments = []slack.Attachment{{Fallback: "UREQUESTER"}}
+
+	if got := exclusionRequester(cb); got != "UREQUESTER" {
+		t.Errorf("got requester %s, want UREQUESTER", got)
+	}
+
+	// requests posted before the requester was kept.
+	cb.OriginalMessage.Attachments[0].Fallback = ""
+	if got := exclusionRequester(cb); got != "UAPPROVER" {
+		t.Errorf("got requester %s, want UAPPROVER", got)
+	}
+}
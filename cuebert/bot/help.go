@@ -67,6 +67,9 @@ func (b *Bot) help() *slacker.CommandDefinition {
 			reportGet := []*slack.TextBlockObject{
 				slack.NewTextBlockObject(slack.MarkdownType, "Get Report:\n`cuebert get report`\n", false, false),
 			}
+			auditGet := []*slack.TextBlockObject{
+				slack.NewTextBlockObject(slack.MarkdownType, "Audit Log:\n`cuebert get audit user|serial|since <value>`\n", false, false),
+			}
 
 			// user commands for admins
 			userAdmin := slack.NewTextBlockObject(slack.MarkdownType, "*User Commands*\n", false, false)
@@ -77,6 +80,12 @@ func (b *Bot) help() *slacker.CommandDefinition {
 				slack.NewTextBlockObject(slack.MarkdownType, "Update User Info:\n`cuebert update user interactive`\n", false, false),
 			}
 
+			// campaigns
+			campaignAdmin := slack.NewTextBlockObject(slack.MarkdownType, "*Campaigns*\n", false, false)
+			campaignCmds := []*slack.TextBlockObject{
+				slack.NewTextBlockObject(slack.MarkdownType, "Manage Campaigns:\n`cuebert campaign list|create|pause|resume|close`\n", false, false),
+			}
+
 			// lifecycle
 			lifecycle := slack.NewTextBlockObject(slack.MarkdownType, "*Lifecycle*\n", false, false)
 			start := []*slack.TextBlockObject{
@@ -174,11 +183,15 @@ func (b *Bot) help() *slacker.CommandDefinition {
 					slack.NewDividerBlock(),
 					slack.NewSectionBlock(reports, nil, nil),
 					slack.NewSectionBlock(nil, reportGet, nil),
+					slack.NewSectionBlock(nil, auditGet, nil),
 					slack.NewDividerBlock(),
 					slack.NewSectionBlock(userAdmin, nil, nil),
 					slack.NewSectionBlock(nil, getUser, nil),
 					slack.NewSectionBlock(nil, updateUser, nil),
 					slack.NewDividerBlock(),
+					slack.NewSectionBlock(campaignAdmin, nil, nil),
+					slack.NewSectionBlock(nil, campaignCmds, nil),
+					slack.NewDividerBlock(),
 					slack.NewSectionBlock(lifecycle, nil, nil),
 					slack.NewSectionBlock(nil, start, nil),
 					slack.NewSectionBlock(nil, stop, nil),
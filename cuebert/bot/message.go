@@ -1,13 +1,16 @@
 package bot
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"math/big"
 	"strconv"
 	"time"
 
+	"github.com/johnmikee/cuebert/cuebert/metrics"
 	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/db/audit"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
@@ -20,11 +23,13 @@ func (b *Bot) ack(ctx *slacker.InteractionContext) {
 	if err != nil {
 		b.log.Err(err).Msg("could not record the first ack time")
 	}
+	metrics.Acknowledged()
 
 	response := fmt.Sprintf("Acknowledged at %v", time.Now().Local().Format(time.RFC1123))
 
 	_, _, err = b.bot.SlackClient().
-		PostMessage(
+		PostMessageContext(
+			b.Context(ctx),
 			ctx.Callback().Channel.ID,
 			slack.MsgOptionText(response, false),
 			slack.MsgOptionTS(ctx.Callback().MessageTs),
@@ -33,14 +38,17 @@ func (b *Bot) ack(ctx *slacker.InteractionContext) {
 		b.log.Err(err).Msg("could not post message")
 	}
 
-	if err = b.bot.SlackClient().AddReaction("white_check_mark",
+	if err = b.bot.SlackClient().AddReactionContext(b.Context(ctx), "white_check_mark",
 		slack.NewRefToMessage(ctx.Callback().Channel.ID, ctx.Callback().MessageTs)); err != nil {
 		b.log.Err(err).Msg("could not add reaction")
 	}
 
 }
 
-func (b *Bot) BaseMessage(rp *ReminderPayload, splay int64) {
+// BaseMessage sends the first message to the user after waiting splay seconds.
+func (b *Bot) BaseMessage(ctx context.Context, rp *ReminderPayload, splay int64) {
+	t := b.tables.WithContext(ctx)
+
 	b.log.Debug().
 		Int64("splay", splay).
 		Str("user", rp.UserName).
@@ -67,9 +75,13 @@ func (b *Bot) BaseMessage(rp *ReminderPayload, splay int64) {
 
 	message := slack.MsgOptionAttachments(attachment)
 
-	channelID, timestamp, err := b.bot.SlackClient().PostMessage(rp.UserSlackID, message)
+	channelID, timestamp, err := b.bot.SlackClient().PostMessageContext(ctx, rp.UserSlackID, message)
 	if err != nil {
 		b.log.Err(err).Msg("error posting message")
+	} else {
+		metrics.MessageSent(metrics.First)
+		b.Audit(audit.System, audit.FirstMessageSent, rp.UserSlackID, rp.Serial, nil,
+			map[string]any{"model": rp.Model, "os": rp.OS})
 	}
 
 	// the timestamp is returned as an epoch string so we need to convert that
@@ -84,13 +96,15 @@ func (b *Bot) BaseMessage(rp *ReminderPayload, splay int64) {
 		Str("channel", channelID).
 		Msg("first message sent")
 
-	err = b.tables.FirstMessageSent(rp.UserSlackID, rp.Serial, time.Unix(int64(sec), 0).UTC())
+	err = t.FirstMessageSent(rp.UserSlackID, rp.Serial, time.Unix(int64(sec), 0).UTC())
 	if err != nil {
 		b.log.Info().Msgf("error adding ack: %s", err.Error())
 	}
 }
 
-func (b *Bot) sendMSG(rp *ReminderPayload, count int) {
+func (b *Bot) sendMSG(ctx context.Context, rp *ReminderPayload, count int) {
+	t := b.tables.WithContext(ctx)
+
 	// we need to make sure this is sending at a time the user is active.
 	// ensure that it is between 9-10am based off the users timezone.
 	userLocale := helpers.GenLocation(rp.TZOffset)
@@ -135,7 +149,7 @@ func (b *Bot) sendMSG(rp *ReminderPayload, count int) {
 			Msg("sending message")
 	}
 
-	ex, app := b.tables.IsExcluded(rp.Serial)
+	ex, app := t.IsExcluded(rp.Serial)
 	if ex {
 		b.log.Info().
 			Str("serial", rp.Serial).
@@ -152,7 +166,7 @@ func (b *Bot) sendMSG(rp *ReminderPayload, count int) {
 
 	switch count {
 	case 1:
-		_, err := b.tables.FirstMessageWaiting(tables.Setter, rp.Serial)
+		_, err := t.FirstMessageWaiting(tables.Setter, rp.Serial)
 		if err != nil {
 			b.log.Err(err).Msg("error setting first message waiting")
 		}
@@ -161,24 +175,28 @@ func (b *Bot) sendMSG(rp *ReminderPayload, count int) {
 		if err != nil {
 			b.log.Err(err).Msg("could not generate random delay")
 		}
-		go b.BaseMessage(rp, n.Int64())
+		go b.BaseMessage(ctx, rp, n.Int64())
 	case 2:
-		err := b.deliverReminder(
+		rule := b.cfg.policy.Match(rp.Model, rp.OS)
+		err := b.deliverReminder(ctx,
 			&ReminderInfo{
-				Deadline: b.cfg.deadline,
+				Deadline: rule.Deadline,
 				Cutoff:   b.cfg.cutoffTime,
 				User:     rp.UserSlackID,
 				Serial:   rp.Serial,
-				Version:  b.cfg.requiredVers,
+				Version:  rule.Minimum,
 				OS:       rp.OS,
 				Text:     "Hi there! Just a gentle reminder to acknowledge the previous message about updating.",
+				Policy:   rule.String(),
 			},
 		)
 		if err != nil {
 			b.log.Err(err).Msg("could not deliver reminder")
+		} else {
+			metrics.MessageSent(metrics.Second)
 		}
 	case 3:
-		err := b.method.ReminderMessage(rp)
+		err := b.method.ReminderMessage(ctx, rp)
 		if err != nil {
 			b.log.Err(err).Msg("could not send reminder message")
 		}
@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"context"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
 )
@@ -30,7 +31,7 @@ var (
 )
 
 // modalGateway is a helper function to send a request to invoke a modal with two buttons.
-func (b *Bot) modalGateway(m *modalGateway) {
+func (b *Bot) modalGateway(ctx context.Context, m *modalGateway) {
 	attachment := slack.Attachment{
 		Text:       m.text,
 		Fallback:   m.fallback,
@@ -54,7 +55,7 @@ func (b *Bot) modalGateway(m *modalGateway) {
 		},
 	}
 
-	b.sendAttachment(attachment, m.channel, m.msg)
+	b.sendAttachment(ctx, attachment, m.channel, m.msg)
 }
 
 // interactiveHelper is the handler for all modal requests
@@ -65,15 +66,15 @@ func (b *Bot) interactiveHelper(ctx *slacker.InteractionContext) {
 	case Accept:
 		b.ack(ctx)
 	case UpdateUserYes:
-		b.userSelector(ctx.Callback().TriggerID, ctx.Callback().Channel.ID)
+		b.userSelector(b.Context(ctx), ctx.Callback().TriggerID, ctx.Callback().Channel.ID)
 	case UpdateUserSubmit:
 		// maybe this is fixed now?
 		// it was b.UserBRUpdate(s, event, callback, callback.Channel.ID)
 		b.method.UserUpdate(ctx)
 	case UpdateConfigYes:
-		b.loadProgram(ctx.Callback().TriggerID, ctx.Callback().CallbackID)
+		b.loadProgram(b.Context(ctx), ctx.Callback().TriggerID, ctx.Callback().CallbackID)
 	}
-	_, _, _ = b.Client().DeleteMessage(ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
+	_, _, _ = b.Client().DeleteMessageContext(b.Context(ctx), ctx.Callback().Channel.ID, ctx.Callback().MessageTs)
 }
 
 // modalSubmit is the handler for all modal submissions
@@ -1,6 +1,8 @@
 package bot
 
 import (
+	"context"
+	"github.com/johnmikee/cuebert/db/audit"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
 )
@@ -13,6 +15,8 @@ type ReminderInfo struct {
 	Version  string
 	OS       string
 	Text     string
+	// Policy describes the rule the device was evaluated against.
+	Policy string
 }
 
 type ReminderPayload struct {
@@ -62,7 +66,7 @@ func (b *Bot) reminderHelp() {
 }
 
 // deliverReminder delivers the reminder to the user
-func (b *Bot) deliverReminder(ri *ReminderInfo) error {
+func (b *Bot) deliverReminder(ctx context.Context, ri *ReminderInfo) error {
 	attachment := slack.Attachment{
 		Title:      "Cuebert Update Reminder",
 		Text:       ri.Text,
@@ -88,9 +92,16 @@ func (b *Bot) deliverReminder(ri *ReminderInfo) error {
 		},
 	}
 
+	if ri.Policy != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Policy",
+			Value: ri.Policy,
+		})
+	}
+
 	message := slack.MsgOptionAttachments(attachment)
 
-	channelID, timestamp, err := b.bot.SlackClient().PostMessage(ri.User, message)
+	channelID, timestamp, err := b.bot.SlackClient().PostMessageContext(ctx, ri.User, message)
 	if err != nil {
 		b.log.Err(err).Msg("posting message")
 		return err
@@ -105,5 +116,8 @@ func (b *Bot) deliverReminder(ri *ReminderInfo) error {
 		Bool("sent", true).
 		Msg("delivered reminder")
 
+	b.Audit(audit.System, audit.ReminderDelivered, ri.User, ri.Serial, nil,
+		map[string]any{"version": ri.Version, "os": ri.OS})
+
 	return nil
 }
@@ -1,9 +1,11 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/johnmikee/cuebert/cuebert/metrics"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
 )
@@ -12,7 +14,7 @@ import (
 //
 // does a quick check to make sure the date selected is not in the past. if it is,
 // the modal will be re-opened letting the user know to pick a date in the future.
-func (b *Bot) reminderPicker(triggerID, title string) {
+func (b *Bot) reminderPicker(ctx context.Context, triggerID, title string) {
 	headerSection := slack.SectionBlock{
 		Type: slack.MBTSection,
 		Text: &slack.TextBlockObject{
@@ -89,7 +91,7 @@ func (b *Bot) reminderPicker(triggerID, title string) {
 		NotifyOnClose: false,
 	}
 
-	vr, err := b.bot.SlackClient().OpenView(triggerID, modalRequest)
+	vr, err := b.bot.SlackClient().OpenViewContext(ctx, triggerID, modalRequest)
 	if err != nil {
 		b.log.Err(err).Send()
 	}
@@ -109,17 +111,19 @@ func (b *Bot) reminderRequested(ctx *slacker.InteractionContext) {
 		}
 	}
 
-	_, _, err := b.bot.SlackClient().DeleteMessage(ctx.Callback().Channel.ID, ctx.Callback().Message.Timestamp)
+	_, _, err := b.bot.SlackClient().DeleteMessageContext(b.Context(ctx), ctx.Callback().Channel.ID, ctx.Callback().Message.Timestamp)
 	if err != nil {
 		b.log.Err(err).Msg("could not delete the reminder request message")
 	}
 
-	b.reminderPicker(ctx.Callback().TriggerID, "Please enter a time to be reminded")
+	b.reminderPicker(b.Context(ctx), ctx.Callback().TriggerID, "Please enter a time to be reminded")
 }
 
 // ScheduleReminder will execute the scheduled reminder set by the user.
-func (b *Bot) ScheduleReminder(t time.Duration, ri *ReminderInfo) {
-	sent, err := b.tables.ReminderSentCheck(ri.User)
+func (b *Bot) ScheduleReminder(ctx context.Context, t time.Duration, ri *ReminderInfo) {
+	tables := b.tables.WithContext(ctx)
+
+	sent, err := tables.ReminderSentCheck(ri.User)
 
 	if err != nil {
 		b.log.Info().
@@ -149,7 +153,7 @@ func (b *Bot) ScheduleReminder(t time.Duration, ri *ReminderInfo) {
 		Str("serial", ri.Serial).
 		Msg("sending requested reminder")
 
-	err = b.deliverReminder(ri)
+	err = b.deliverReminder(ctx, ri)
 
 	if err != nil {
 		b.log.Info().
@@ -158,8 +162,9 @@ func (b *Bot) ScheduleReminder(t time.Duration, ri *ReminderInfo) {
 			Send()
 		return
 	}
+	metrics.MessageSent(metrics.Reminder)
 
-	err = b.tables.ReminderSent(true, ri.Serial)
+	err = tables.ReminderSent(true, ri.Serial)
 
 	if err != nil {
 		b.log.Info().
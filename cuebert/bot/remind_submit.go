@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/johnmikee/cuebert/db/bot"
@@ -33,7 +34,7 @@ func (b *Bot) reminderSubmit(ctx *slacker.InteractionContext) {
 			dv.SelectedDate,
 			tv.SelectedTime,
 		)
-		_, _, err := b.bot.SlackClient().PostMessage(ctx.Callback().User.ID, slack.MsgOptionText(update, false))
+		_, _, err := b.bot.SlackClient().PostMessageContext(b.Context(ctx), ctx.Callback().User.ID, slack.MsgOptionText(update, false))
 
 		if err != nil {
 			b.log.Err(err).Msg("posting time fix message")
@@ -45,15 +46,15 @@ func (b *Bot) reminderSubmit(ctx *slacker.InteractionContext) {
 
 	update := fmt.Sprintf("Your reminder has been set for %s %s :clock1:", dv.SelectedDate, tv.SelectedTime)
 
-	_, _, err = b.bot.SlackClient().PostMessage(ctx.Callback().User.ID, slack.MsgOptionText(update, false))
+	_, _, err = b.bot.SlackClient().PostMessageContext(b.Context(ctx), ctx.Callback().User.ID, slack.MsgOptionText(update, false))
 	if err != nil {
 		b.log.Err(err).Msg("posting time fix message")
 	}
 }
 
 // SendReminder sends a reminder to the user based on their input
-func (b *Bot) SendReminder(count int, x *bot.Info) {
-	dev, err := b.tables.DeviceBySerial(x.SerialNumber)
+func (b *Bot) SendReminder(ctx context.Context, count int, x *bot.Info) {
+	dev, err := b.tables.WithContext(ctx).DeviceBySerial(x.SerialNumber)
 	if err != nil {
 		b.log.Info().
 			AnErr("getting devices", err).
@@ -63,7 +64,7 @@ func (b *Bot) SendReminder(count int, x *bot.Info) {
 		return
 	}
 
-	b.sendMSG(
+	b.sendMSG(ctx,
 		&ReminderPayload{
 			UserSlackID: x.SlackID,
 			UserName:    x.FullName,
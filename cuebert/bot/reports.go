@@ -1,11 +1,37 @@
 package bot
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/johnmikee/cuebert/db/bot"
+	"github.com/johnmikee/cuebert/db/history"
 	"github.com/johnmikee/cuebert/pkg/visual"
 	"github.com/slack-go/slack"
 )
 
+// ReportOpts are the reports that can be built.
+var ReportOpts = []string{"os", "manager alerted", "first message sent", "requested reminder", "updated"}
+
+// Report builds the report named by one of ReportOpts.
+func (b *Bot) Report(opt string) (*visual.PieChartOption, error) {
+	switch strings.ToLower(opt) {
+	case "os":
+		return b.BuildOSReport()
+	case "manager alerted":
+		return b.BuildSentReport(Manager)
+	case "first message sent":
+		return b.BuildSentReport(First)
+	case "requested reminder":
+		return b.BuildSentReport(ReminderRequested)
+	case "updated":
+		return b.BuildUpdatedReport()
+	default:
+		return nil, fmt.Errorf("%s is not a report", opt)
+	}
+}
+
 func generateReport(fileName, title string) slack.FileUploadParameters {
 	return slack.FileUploadParameters{
 		Title:    title,
@@ -122,6 +148,50 @@ func (b *Bot) BuildOSReport() (*visual.PieChartOption, error) {
 	return v, nil
 }
 
+// BuildUpdatedReport builds a report of the number of devices that completed
+// their update each day over the last 30 days.
+func (b *Bot) BuildUpdatedReport() (*visual.PieChartOption, error) {
+	h, err := b.tables.HistorySince(time.Now().AddDate(0, 0, -updatedReportDays))
+	if err != nil {
+		return nil, err
+	}
+
+	v := &visual.PieChartOption{}
+
+	days, counts := countByDay(h)
+	for i := range days {
+		v.ValueList = append(v.ValueList, float64(counts[i]))
+		v.XAxis = append(v.XAxis, days[i])
+	}
+
+	v.Query = "CompletedAt"
+	v.Text = "Updated"
+
+	return v, nil
+}
+
+// updatedReportDays is how far back the updated report looks.
+const updatedReportDays = 30
+
+// countByDay counts the archived devices by the day they completed. the days
+// are returned oldest first.
+func countByDay(h history.HI) (days []string, counts []int) {
+	idx := map[string]int{}
+	for i := range h {
+		day := h[i].CompletedAt.Format("2006-01-02")
+		n, ok := idx[day]
+		if !ok {
+			n = len(days)
+			idx[day] = n
+			days = append(days, day)
+			counts = append(counts, 0)
+		}
+		counts[n]++
+	}
+
+	return days, counts
+}
+
 func countSentStatus(br bot.BR, which Report) (sent, notSent int) {
 	for i := range br {
 		switch which {
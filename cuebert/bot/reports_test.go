@@ -0,0 +1,39 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/db/history"
+)
+
+func TestCountByDay(t *testing.T) {
+	day := func(d, h int) time.Time {
+		return time.Date(2023, time.June, d, h, 0, 0, 0, time.UTC)
+	}
+
+	h := history.HI{
+		{SerialNumber: "A", CompletedAt: day(1, 9)},
+		{SerialNumber: "B", CompletedAt: day(1, 17)},
+		{SerialNumber: "C", CompletedAt: day(3, 12)},
+		{SerialNumber: "D", CompletedAt: day(4, 8)},
+		{SerialNumber: "E", CompletedAt: day(4, 23)},
+	}
+
+	days, counts := countByDay(h)
+
+	wantDays := []string{"2023-06-01", "2023-06-03", "2023-06-04"}
+	wantCounts := []int{2, 1, 2}
+
+	if !reflect.DeepEqual(days, wantDays) {
+		t.Errorf("days = %v, want %v", days, wantDays)
+	}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("counts = %v, want %v", counts, wantCounts)
+	}
+
+	if days, counts := countByDay(nil); len(days) != 0 || len(counts) != 0 {
+		t.Errorf("countByDay(nil) = %v, %v, want empty", days, counts)
+	}
+}
@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// socketState is the state of the socket mode connection to slack.
+type socketState struct {
+	lock      sync.RWMutex
+	connected bool
+	since     time.Time
+}
+
+// set records the connection state if it changed.
+func (s *socketState) set(connected bool, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.connected == connected && !s.since.IsZero() {
+		return
+	}
+	s.connected = connected
+	s.since = now
+}
+
+// ready returns an error while the socket is not connected.
+func (s *socketState) ready() error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.connected {
+		return nil
+	}
+	if s.since.IsZero() {
+		return errors.New("socket not connected yet")
+	}
+
+	return fmt.Errorf("socket disconnected since %s", s.since.UTC().Format(time.RFC3339))
+}
+
+// SocketReady reports if the socket mode connection to slack is up.
+func (b *Bot) SocketReady(context.Context) error {
+	return b.socket.ready()
+}
@@ -0,0 +1,28 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSocketState(t *testing.T) {
+	s := &socketState{}
+	if err := s.ready(); err == nil {
+		t.Error("ready() before connecting should fail")
+	}
+
+	up := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.set(true, up)
+	if err := s.ready(); err != nil {
+		t.Errorf("ready() got %v, want nil", err)
+	}
+
+	down := up.Add(time.Hour)
+	s.set(false, down)
+	s.set(false, down.Add(time.Minute))
+
+	want := "socket disconnected since 2023-06-01T13:00:00Z"
+	if err := s.ready(); err == nil || err.Error() != want {
+		t.Errorf("ready() got %v, want %s", err, want)
+	}
+}
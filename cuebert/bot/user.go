@@ -1,10 +1,16 @@
 package bot
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/johnmikee/cuebert/db/bot"
+	"github.com/johnmikee/cuebert/db/devices"
+	"github.com/johnmikee/cuebert/db/exclusions"
+	"github.com/johnmikee/cuebert/db/history"
+	"github.com/johnmikee/cuebert/db/users"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
@@ -268,3 +274,128 @@ func (b *Bot) Device(email string) ([]slack.Attachment, error) {
 	attachments = append(attachments, attch)
 	return attachments, nil
 }
+
+// History lists the devices of the user that have been archived once they
+// met a campaign.
+func (b *Bot) History(email string) ([]slack.Attachment, error) {
+	h, err := b.tables.HistoryByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Empty() {
+		return []slack.Attachment{{Title: "No compliance history Found"}}, nil
+	}
+
+	attachments := make([]slack.Attachment, 0, len(h))
+	for i := range h {
+		attachments = append(attachments, slack.Attachment{
+			Title: "Compliance History",
+			Fields: []slack.AttachmentField{
+				{
+					Title: "Serial Number",
+					Value: h[i].SerialNumber,
+				},
+				{
+					Title: "Campaign",
+					Value: h[i].CampaignID,
+				},
+				{
+					Title: "Required OS",
+					Value: h[i].RequiredOS,
+				},
+				{
+					Title: "OS Version",
+					Value: h[i].OSVersion,
+				},
+				{
+					Title: "First Message Sent At",
+					Value: formatTime(h[i].FirstMessageSentAt),
+				},
+				{
+					Title: "First ACK Time",
+					Value: formatTime(h[i].FirstACKTime),
+				},
+				{
+					Title: "Manager Message Sent At",
+					Value: formatTime(h[i].ManagerMessageSentAt),
+				},
+				{
+					Title: "Completed At",
+					Value: h[i].CompletedAt.Format(time.RFC3339),
+				},
+			},
+		})
+	}
+
+	return attachments, nil
+}
+
+// formatTime formats the time or returns never when it is unset.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// UserInfo is what the tables hold about a user.
+type UserInfo struct {
+	Email      string        `json:"email"`
+	Users      users.UI      `json:"users"`
+	BotResults bot.BR        `json:"bot_results"`
+	Exclusions exclusions.EI `json:"exclusions"`
+	Devices    devices.DI    `json:"devices"`
+	History    history.HI    `json:"history"`
+}
+
+// UserInfoOpts are what a user can be looked up by.
+var UserInfoOpts = []string{"slackid", "email"}
+
+// UserInfo returns what the tables hold about the user with the slack id or
+// email.
+func (b *Bot) UserInfo(by, value string) (*UserInfo, error) {
+	var (
+		br  bot.BR
+		err error
+	)
+
+	info := &UserInfo{}
+
+	switch strings.ToLower(by) {
+	case "slackid":
+		br, err = b.tables.UserBySlackID(value)
+	case "email":
+		info.Email = helpers.ExtractEmails(value)
+		br, err = b.tables.UserEmail(info.Email)
+	default:
+		return nil, fmt.Errorf("%s is not one of %s", by, strings.Join(UserInfoOpts, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info.BotResults = br
+	if !br.Empty() {
+		info.Email = br[0].UserEmail
+	}
+	if info.Email == "" {
+		return info, nil
+	}
+
+	if info.Users, err = b.tables.UserByEmail(info.Email); err != nil {
+		return nil, err
+	}
+	if info.Exclusions, err = b.tables.ExclusionBy().Email(info.Email).Query(); err != nil {
+		return nil, err
+	}
+	if info.Devices, err = b.tables.QueryDeviceBy().User(info.Email).Query(); err != nil {
+		return nil, err
+	}
+	if info.History, err = b.tables.HistoryByEmail(info.Email); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"context"
 	"github.com/johnmikee/cuebert/db/bot"
 	"github.com/shomali11/slacker/v2"
 	"github.com/slack-go/slack"
@@ -44,7 +45,7 @@ func (b *Bot) userUpdateSubmit(ctx *slacker.InteractionContext) {
 		slack.MsgOptionAttachments(*original, *updated),
 	}
 
-	_, _, err = b.bot.SlackClient().PostMessage(ctx.Callback().View.ExternalID, opts...)
+	_, _, err = b.bot.SlackClient().PostMessageContext(b.Context(ctx), ctx.Callback().View.ExternalID, opts...)
 	if err != nil {
 		b.log.Err(err).Msg("error responding")
 	}
@@ -52,7 +53,7 @@ func (b *Bot) userUpdateSubmit(ctx *slacker.InteractionContext) {
 }
 
 // userSelector will display a list of users to select from.
-func (b *Bot) userSelector(triggerID, channel string) {
+func (b *Bot) userSelector(ctx context.Context, triggerID, channel string) {
 	headerText := slack.NewTextBlockObject(slack.MarkdownType, "Select User", false, false)
 	headerSection := slack.NewSectionBlock(headerText, nil, nil)
 
@@ -80,7 +81,7 @@ func (b *Bot) userSelector(triggerID, channel string) {
 		ExternalID:      channel,
 	}
 
-	vr, err := b.bot.SlackClient().OpenView(triggerID, modalRequest)
+	vr, err := b.bot.SlackClient().OpenViewContext(ctx, triggerID, modalRequest)
 	if err != nil {
 		b.log.Error().Err(err).Msg("error opening modal")
 	}
@@ -88,8 +89,9 @@ func (b *Bot) userSelector(triggerID, channel string) {
 	b.log.Trace().Interface("view_response", vr).Msg("modal opened")
 }
 
-func (b *Bot) wantUpdateUser(ch string) {
+func (b *Bot) wantUpdateUser(ctx context.Context, ch string) {
 	b.modalGateway(
+		ctx,
 		&modalGateway{
 			text:       "Do you want to update a users values?",
 			callbackID: UpdateUserQuestion,
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/bot"
+	"github.com/johnmikee/cuebert/cuebert/method"
+	mc "github.com/johnmikee/cuebert/cuebert/method/config"
+	"github.com/johnmikee/cuebert/cuebert/policy"
+	"github.com/johnmikee/cuebert/cuebert/tables"
+	dbot "github.com/johnmikee/cuebert/db/bot"
+	"github.com/johnmikee/cuebert/db/campaigns"
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+// campaign is an update campaign with the policy, tables and method its
+// routines run with.
+type campaign struct {
+	info   campaigns.Info
+	policy *policy.Policy
+	tables *tables.Config
+	method method.Actions
+}
+
+// campaignSet is every active campaign. A device is compliant once it meets
+// all of them.
+type campaignSet []*campaign
+
+// Compliant implements tables.Compliance.
+func (s campaignSet) Compliant(model, osVersion string) bool {
+	for _, camp := range s {
+		if !camp.policy.Compliant(model, osVersion) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// defaultCampaign is the campaign built from the flags.
+func (c *Cuebert) defaultCampaign() *campaigns.Info {
+	return &campaigns.Info{
+		ID:         campaigns.Default,
+		Name:       campaigns.Default,
+		RequiredOS: c.flags.requiredVers,
+		Deadline:   c.flags.deadline,
+		CutoffTime: c.flags.cutoffTime,
+		Status:     campaigns.Active,
+		CreatedBy:  c.flags.serviceName,
+	}
+}
+
+// openDefaultCampaign records the default campaign so it runs next to the
+// campaigns created from slack. Its settings are updated with the flag
+// values each time the routines start, a default campaign paused or closed
+// from slack is left that way.
+func (c *Cuebert) openDefaultCampaign() {
+	err := c.tables.OpenCampaign(c.defaultCampaign())
+	if err != nil {
+		c.log.Err(err).Msg("could not record the default campaign")
+	}
+}
+
+// activeCampaigns returns the active campaigns. The policy and method of a
+// campaign are kept between calls so in memory state, ex: the timebound
+// reminder intervals, survives until the campaign settings change. Only the
+// default campaign is returned if the campaigns cannot be read.
+func (c *Cuebert) activeCampaigns() campaignSet {
+	infos, err := c.tables.Campaigns(campaigns.Active)
+	if err != nil {
+		c.log.Err(err).Msg("could not get campaigns")
+		infos = campaigns.CI{*c.defaultCampaign()}
+	}
+
+	c.campaignLock.Lock()
+	defer c.campaignLock.Unlock()
+
+	if c.campaigns == nil {
+		c.campaigns = map[string]*campaign{}
+	}
+
+	set := campaignSet{}
+	for i := range infos {
+		camp, ok := c.campaigns[infos[i].ID]
+		if !ok || !sameSettings(&camp.info, &infos[i]) {
+			camp = c.newCampaign(&infos[i])
+			c.campaigns[infos[i].ID] = camp
+		}
+
+		// campaigns share the release catalog of the default policy.
+		if camp.policy != c.policy {
+			camp.policy.SetCatalog(c.policy.Catalog())
+		}
+
+		set = append(set, camp)
+	}
+
+	return set
+}
+
+// newCampaign builds the policy, tables and method for a campaign. The
+// default campaign uses the ones built from the flags.
+func (c *Cuebert) newCampaign(info *campaigns.Info) *campaign {
+	if info.ID == campaigns.Default {
+		return &campaign{
+			info:   *info,
+			policy: c.policy,
+			tables: c.tables.ForCampaign(campaigns.Default),
+			method: c.method,
+		}
+	}
+
+	p := policy.New(info.RequiredOS, info.Deadline)
+	p.SetCatalog(c.policy.Catalog())
+
+	t := c.tables.ForCampaign(info.ID)
+	b := c.bot.Campaign(t,
+		bot.WithCutoffTime(info.CutoffTime),
+		bot.WithDeadline(info.Deadline),
+		bot.WithPolicy(p),
+		bot.WithRequiredVers(info.RequiredOS),
+	)
+
+	m := mc.New(
+		&mc.Method{
+			Method: method.Option(c.flags.method),
+			Config: method.Config{
+				Log:               c.log,
+				Tables:            t,
+				Bot:               b,
+				StatusHandler:     c.statusHandler,
+				SlackClient:       c.bot.Client(),
+				IDP:               c.idp,
+				MDM:               c.mdm,
+				SlackAlertChannel: c.config.SlackAlertChannel,
+				CutoffTime:        info.CutoffTime,
+				Deadline:          info.Deadline,
+				DeadlineAction:    mdm.Action(c.flags.deadlineAction),
+				DeadlineGroup:     c.flags.deadlineGroup,
+				RequiredVers:      info.RequiredOS,
+				Policy:            p,
+				Testing:           c.flags.testing,
+				TestingUsers:      c.testUsers,
+				PollInterval:      c.flags.pollInterval,
+			},
+		},
+	)
+	b.SetMethod(m)
+
+	c.log.Info().
+		Str("campaign", info.ID).
+		Str("required_os", info.RequiredOS).
+		Str("deadline", info.Deadline).
+		Msg("loaded campaign")
+
+	return &campaign{
+		info:   *info,
+		policy: p,
+		tables: t,
+		method: m,
+	}
+}
+
+// sameSettings reports if the campaign can keep its policy and method.
+func sameSettings(a, b *campaigns.Info) bool {
+	return a.RequiredOS == b.RequiredOS &&
+		a.Deadline == b.Deadline &&
+		a.CutoffTime == b.CutoffTime
+}
+
+// buildCampaignTables adds the bot results for the campaigns other than the
+// default, which is built with the rest of the tables.
+func (c *Cuebert) buildCampaignTables() {
+	for _, camp := range c.activeCampaigns() {
+		if camp.info.ID != campaigns.Default {
+			camp.tables.BuildBotResTable(camp.policy)
+		}
+	}
+}
+
+// checkCampaigns runs the method check for each active campaign.
+func (c *Cuebert) checkCampaigns(ctx context.Context, t time.Time) {
+	for _, camp := range c.activeCampaigns() {
+		camp.method.Check(ctx, t)
+	}
+}
+
+// pollCampaigns runs the method poll for each active campaign.
+func (c *Cuebert) pollCampaigns(ctx context.Context, t time.Time) {
+	for _, camp := range c.activeCampaigns() {
+		camp.method.Poll(ctx, t)
+	}
+}
+
+// campaignDiff archives and removes the results of devices that meet the
+// campaign and adds the devices that have fallen out of compliance since the
+// last diff.
+func (c *Cuebert) campaignDiff(ctx context.Context, camp *campaign, md mdm.DeviceResults) {
+	t := camp.tables.WithContext(ctx)
+
+	br, err := t.GetBotTableInfo()
+	if err != nil {
+		c.log.Debug().AnErr("getting bot results", err).Str("campaign", camp.info.ID).Send()
+		return
+	}
+
+	versCheck := make([]tables.Check, 0, len(br))
+	for i := range br {
+		versCheck = append(versCheck, tables.Check{Serial: br[i].SerialNumber})
+	}
+
+	done := tables.CheckStaleDevices(camp.policy, versCheck, md)
+	if len(done) > 0 {
+		c.log.Trace().Strs("devices", done).Str("campaign", camp.info.ID).Msg("removing results")
+		camp.method.DeviceDiff(done)
+
+		err = t.ArchiveResults(completed(br, done), md, camp.policy)
+		if err != nil {
+			c.log.Err(err).Str("campaign", camp.info.ID).Msg("could not archive results")
+		}
+
+		_, err = t.RemoveBRBy().Serial(done...).Execute()
+		if err != nil {
+			c.log.Debug().AnErr("removing results", err).Str("campaign", camp.info.ID).Send()
+		}
+	}
+
+	t.BuildBotResTable(camp.policy)
+}
+
+// completed returns the results for the serials.
+func completed(br dbot.BR, serials []string) dbot.BR {
+	done := make(map[string]bool, len(serials))
+	for _, s := range serials {
+		done[s] = true
+	}
+
+	out := dbot.BR{}
+	for i := range br {
+		if done[br[i].SerialNumber] {
+			out = append(out, br[i])
+		}
+	}
+
+	return out
+}
+
+// campaignDeadline runs the deadline action once the campaign is due and
+// closes it. It reports if the campaign was closed.
+func (c *Cuebert) campaignDeadline(ctx context.Context, camp *campaign, now time.Time) bool {
+	due, err := camp.policy.Default.Due(camp.info.CutoffTime)
+	if err != nil {
+		c.log.Err(err).Str("campaign", camp.info.ID).Msg("error parsing campaign deadline")
+		return false
+	}
+
+	if !now.After(due) {
+		return false
+	}
+
+	camp.method.Deadline(ctx)
+	c.closeCampaign(camp.info.ID)
+
+	return true
+}
+
+// closeCampaign closes the campaign once it has nothing left to do.
+func (c *Cuebert) closeCampaign(id string) {
+	c.log.Info().Str("campaign", id).Msg("closing campaign")
+
+	if err := c.tables.CloseCampaign(id); err != nil {
+		c.log.Err(err).Str("campaign", id).Msg("could not close campaign")
+	}
+}
+
+// campaignsOpen reports if any campaign is still active or paused.
+func (c *Cuebert) campaignsOpen() bool {
+	open, err := c.tables.Campaigns(campaigns.Active, campaigns.Paused)
+	if err != nil {
+		c.log.Err(err).Msg("could not get campaigns")
+		return false
+	}
+
+	return !open.Empty()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/handlers"
+)
+
+// reloadCatalog rereads the OS release catalog so new releases are picked up
+// without a restart. The previous catalog is kept if the reload fails.
+func (c *Cuebert) reloadCatalog() {
+	err := c.policy.ReloadCatalog()
+	if err != nil {
+		c.log.Err(err).Msg("could not reload os catalog")
+	}
+
+	c.setCatalogStatus(err)
+}
+
+// setCatalogStatus shows the catalog and the versions it resolved on /health.
+func (c *Cuebert) setCatalogStatus(err error) {
+	cat := c.policy.Catalog()
+	if cat == nil {
+		return
+	}
+
+	cs := &handlers.CatalogStatus{
+		Source:   cat.Source,
+		Loaded:   cat.Loaded.Format(time.RFC3339),
+		Required: c.policy.Default.Minimum,
+		Versions: c.policy.Minimums(),
+	}
+	if err != nil {
+		cs.Error = err.Error()
+	}
+
+	status := c.statusHandler.GetStatus()
+	status.Catalog = cs
+	c.statusHandler.SetStatus(status)
+}
@@ -0,0 +1,235 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Feed is the SOFA macOS data feed. Only the fields cuebert uses are decoded.
+//   - https://sofa.macadmins.io
+type Feed struct {
+	UpdateHash string      `json:"UpdateHash"`
+	OSVersions []OSVersion `json:"OSVersions"`
+}
+
+// OSVersion holds the releases for a major version, ex: Sonoma 14.
+type OSVersion struct {
+	OSVersion        string    `json:"OSVersion"`
+	Latest           Release   `json:"Latest"`
+	SecurityReleases []Release `json:"SecurityReleases"`
+}
+
+// Release is a single OS release.
+type Release struct {
+	ProductVersion string    `json:"ProductVersion"`
+	Build          string    `json:"Build"`
+	ReleaseDate    time.Time `json:"ReleaseDate"`
+}
+
+// Catalog is a loaded feed with the latest release of each major version
+// sorted newest first.
+type Catalog struct {
+	Source string
+	Loaded time.Time
+	Feed   Feed
+	majors []major
+}
+
+type major struct {
+	name   string
+	number int
+	latest string
+}
+
+// requirement matches latest or latest-N.
+var requirement = regexp.MustCompile(`^latest(?:\s*-\s*(\d+))?$`)
+
+// ParseRequirement returns how many major versions behind the newest a
+// latest or latest-N requirement allows. ok is false when s is not one.
+func ParseRequirement(s string) (n int, ok bool) {
+	m := requirement.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return 0, false
+	}
+
+	if m[1] == "" {
+		return 0, true
+	}
+
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// IsRequirement reports whether s is latest or latest-N.
+func IsRequirement(s string) bool {
+	_, ok := ParseRequirement(s)
+	return ok
+}
+
+// Load reads the feed from a file path or an http(s) URL.
+func Load(source string) (*Catalog, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		b, err = fetch(source)
+	} else {
+		b, err = os.ReadFile(filepath.Clean(source))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+	c.Source = source
+
+	return c, nil
+}
+
+// Parse decodes a feed.
+func Parse(b []byte) (*Catalog, error) {
+	var f Feed
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, errors.Wrap(err, "decoding catalog")
+	}
+
+	c := &Catalog{
+		Loaded: time.Now(),
+		Feed:   f,
+	}
+
+	for i := range f.OSVersions {
+		latest := f.OSVersions[i].Latest.ProductVersion
+		name := majorOf(latest)
+		n, err := strconv.Atoi(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q for %s", latest, f.OSVersions[i].OSVersion)
+		}
+
+		c.majors = append(c.majors, major{name: name, number: n, latest: latest})
+	}
+
+	if len(c.majors) == 0 {
+		return nil, errors.New("catalog has no os versions")
+	}
+
+	sort.Slice(c.majors, func(i, j int) bool { return c.majors[i].number > c.majors[j].number })
+
+	return c, nil
+}
+
+// Majors returns the major versions in the catalog, newest first.
+func (c *Catalog) Majors() []string {
+	m := make([]string, 0, len(c.majors))
+	for i := range c.majors {
+		m = append(m, c.majors[i].name)
+	}
+
+	return m
+}
+
+// Latest returns the latest release of the major version.
+func (c *Catalog) Latest(majorVersion string) (string, bool) {
+	for i := range c.majors {
+		if c.majors[i].name == majorVersion {
+			return c.majors[i].latest, true
+		}
+	}
+
+	return "", false
+}
+
+// Minimum returns the version a device running osVersion must reach to meet
+// a latest-N requirement. Devices on one of the newest N+1 major versions
+// need the latest release of their major, the rest need the latest release
+// of the oldest major that is still allowed.
+func (c *Catalog) Minimum(req, osVersion string) (string, error) {
+	n, ok := ParseRequirement(req)
+	if !ok {
+		return "", fmt.Errorf("invalid requirement %q", req)
+	}
+
+	if n >= len(c.majors) {
+		n = len(c.majors) - 1
+	}
+
+	m := majorOf(osVersion)
+	for i := 0; i <= n; i++ {
+		if c.majors[i].name == m {
+			return c.majors[i].latest, nil
+		}
+	}
+
+	return c.majors[n].latest, nil
+}
+
+// Minimums returns the version required for a device on each major version
+// in the catalog for a latest-N requirement.
+func (c *Catalog) Minimums(req string) (map[string]string, error) {
+	mins := map[string]string{}
+	for i := range c.majors {
+		v, err := c.Minimum(req, c.majors[i].name)
+		if err != nil {
+			return nil, err
+		}
+		mins[c.majors[i].name] = v
+	}
+
+	return mins, nil
+}
+
+func fetch(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("error during request. status code=%d error: %s", resp.StatusCode, string(b))
+	}
+
+	return b, nil
+}
+
+// majorOf returns the major version, ex: 14 for 14.4.1.
+func majorOf(v string) string {
+	v = strings.TrimSpace(v)
+	if i := strings.Index(v, "."); i >= 0 {
+		return v[:i]
+	}
+
+	return v
+}
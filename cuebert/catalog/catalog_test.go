@@ -0,0 +1,125 @@
+package catalog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testFeed = `{
+  "UpdateHash": "abc123",
+  "OSVersions": [
+    {
+      "OSVersion": "Ventura 13",
+      "Latest": {"ProductVersion": "13.6.6", "Build": "22G630", "ReleaseDate": "2024-03-25T00:00:00Z"},
+      "SecurityReleases": [{"ProductVersion": "13.6.6"}, {"ProductVersion": "13.6.5"}]
+    },
+    {
+      "OSVersion": "Sonoma 14",
+      "Latest": {"ProductVersion": "14.4.1", "Build": "23E224", "ReleaseDate": "2024-03-25T00:00:00Z"},
+      "SecurityReleases": [{"ProductVersion": "14.4.1"}, {"ProductVersion": "14.4"}]
+    },
+    {
+      "OSVersion": "Monterey 12",
+      "Latest": {"ProductVersion": "12.7.4", "Build": "21H1123", "ReleaseDate": "2024-03-07T00:00:00Z"}
+    }
+  ]
+}`
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		in string
+		n  int
+		ok bool
+	}{
+		{"latest", 0, true},
+		{"Latest-1", 1, true},
+		{"latest - 2", 2, true},
+		{"13.6.4", 0, false},
+		{"latest+1", 0, false},
+	}
+
+	for _, tt := range tests {
+		n, ok := ParseRequirement(tt.in)
+		if n != tt.n || ok != tt.ok {
+			t.Errorf("ParseRequirement(%q) = %d, %v. want %d, %v", tt.in, n, ok, tt.n, tt.ok)
+		}
+	}
+}
+
+func TestMinimum(t *testing.T) {
+	c, err := Parse([]byte(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.Majors(); len(got) != 3 || got[0] != "14" || got[2] != "12" {
+		t.Fatalf("majors should be sorted newest first, got %v", got)
+	}
+
+	tests := []struct {
+		req, os string
+		want    string
+	}{
+		{"latest", "14.2", "14.4.1"},
+		{"latest", "13.6", "14.4.1"},
+		{"latest-1", "14.2", "14.4.1"},
+		{"latest-1", "13.5", "13.6.6"},
+		{"latest-1", "12.7.4", "13.6.6"},
+		{"latest-5", "11.7", "12.7.4"},
+		{"latest-1", "15.0", "13.6.6"},
+	}
+
+	for _, tt := range tests {
+		got, err := c.Minimum(tt.req, tt.os)
+		if err != nil || got != tt.want {
+			t.Errorf("Minimum(%q, %q) = %q, %v. want %q", tt.req, tt.os, got, err, tt.want)
+		}
+	}
+
+	mins, err := c.Minimums("latest-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mins["14"] != "14.4.1" || mins["13"] != "13.6.6" || mins["12"] != "13.6.6" {
+		t.Errorf("unexpected minimums: %v", mins)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "feed.json")
+	if err := os.WriteFile(f, []byte(testFeed), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Source != f {
+		t.Errorf("source is %q, want %q", c.Source, f)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(testFeed))
+	}))
+	defer srv.Close()
+
+	c, err = Load(srv.URL + "/feed.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := c.Latest("13"); !ok || v != "13.6.6" {
+		t.Errorf("Latest(13) = %q, %v", v, ok)
+	}
+
+	if _, err := Load(srv.URL + "/missing.json"); err == nil {
+		t.Error("expected an error for a missing feed")
+	}
+}
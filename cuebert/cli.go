@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	osuser "os/user"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/bot"
+	"github.com/johnmikee/cuebert/cuebert/tables"
+)
+
+// commands are run in place of the bot, ex: `cuebert status`. each returns
+// the exit code. they read the same flags, env and keyring as the bot so ops
+// can inspect and fix the state from a shell when slack is down.
+var commands = map[string]func(args []string) int{
+	"migrate":    migrateCommand,
+	"status":     statusCommand,
+	"exclusions": exclusionsCommand,
+	"users":      usersCommand,
+	"report":     reportCommand,
+	"tables":     tablesCommand,
+}
+
+const (
+	exclusionsUsage = "usage: cuebert exclusions list|add <serial> <until> <reason>|revoke <serial> [flags]"
+	usersUsage      = "usage: cuebert users show <email> [flags]"
+	reportUsage     = "usage: cuebert report <name> [-format table|csv|json] [flags]"
+	tablesUsage     = "usage: cuebert tables rebuild [flags]"
+)
+
+// cutArgs splits the arguments of a subcommand from the flags after them.
+func cutArgs(args []string) (pos, flags []string) {
+	for i := range args {
+		if strings.HasPrefix(args[i], "-") {
+			return args[:i], args[i:]
+		}
+	}
+
+	return args, nil
+}
+
+// cutFlag removes a flag only the subcommand knows from the flags so they
+// can be parsed as usual. returns def when the flag is not set.
+func cutFlag(flags []string, name, def string) (string, []string) {
+	rest := []string{}
+	for i := 0; i < len(flags); i++ {
+		f := strings.TrimLeft(flags[i], "-")
+		switch {
+		case f == name && i+1 < len(flags):
+			def = flags[i+1]
+			i++
+		case strings.HasPrefix(f, name+"="):
+			def = strings.TrimPrefix(f, name+"=")
+		default:
+			rest = append(rest, flags[i])
+		}
+	}
+
+	return def, rest
+}
+
+// cli loads the env and connects to the database the way the bot does. the
+// bot it sets is only used to read the tables and record the audit log, it
+// does not connect to slack.
+func cli(flags []string) *Cuebert {
+	cb, _ := loadEnv(flags)
+	cb.db = cb.connect()
+	cb.tables = tables.New(cb.db, &cb.log)
+	cb.bot = bot.New(
+		&bot.Config{
+			Log:       cb.log,
+			Tables:    cb.tables,
+			LifeCycle: cb,
+			Cfg: bot.CfgSetter(
+				bot.WithPolicy(cb.policy),
+				bot.WithTableNames(cb.flags.tableNames),
+			),
+		},
+	)
+
+	return cb
+}
+
+// cliActor is who changes from the cli are recorded under in the audit log.
+func cliActor() string {
+	u, err := osuser.Current()
+	if err != nil || u.Username == "" {
+		return "cli"
+	}
+
+	return "cli:" + u.Username
+}
+
+// statusCommand runs `cuebert status`.
+func statusCommand(args []string) int {
+	pos, flags := cutArgs(args)
+	if len(pos) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: cuebert status [flags]")
+		return 1
+	}
+
+	cb := cli(flags)
+	if err := cb.status(os.Stdout); err != nil {
+		cb.log.Err(err).Msg("could not get status")
+		return 1
+	}
+
+	return 0
+}
+
+// status writes the state of the database: the schema, campaigns and how
+// many rows are in the tables the bot works from.
+func (cb *Cuebert) status(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	if err := cb.db.Ping(context.Background()); err != nil {
+		fmt.Fprintf(w, "database\t%s: %v\n", cb.db.Driver(), err)
+		w.Flush()
+		return err
+	}
+	fmt.Fprintf(w, "database\t%s: connected\n", cb.db.Driver())
+
+	m, err := cb.migrator()
+	if err != nil {
+		return err
+	}
+	if m != nil {
+		ms, err := m.Status(context.Background())
+		if err != nil {
+			return err
+		}
+		pending := 0
+		for i := range ms {
+			if !ms[i].Applied {
+				pending++
+			}
+		}
+		fmt.Fprintf(w, "migrations\t%d applied, %d pending\n", len(ms)-pending, pending)
+	}
+
+	camps, err := cb.tables.Campaigns()
+	if err != nil {
+		return err
+	}
+	for i := range camps {
+		fmt.Fprintf(w, "campaign\t%s (%s) requires %s\n", camps[i].ID, camps[i].Status, camps[i].RequiredOS)
+	}
+
+	devs, err := cb.tables.GetAllDevices()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "devices\t%d\n", len(devs))
+
+	br, err := cb.tables.GetBotTableInfo()
+	if err != nil {
+		return err
+	}
+	ackd := 0
+	for i := range br {
+		if br[i].FirstACK {
+			ackd++
+		}
+	}
+	fmt.Fprintf(w, "bot results\t%d, %d acknowledged\n", len(br), ackd)
+
+	ex, err := cb.tables.ExclusionBy().All().Query()
+	if err != nil {
+		return err
+	}
+	active := 0
+	for i := range ex {
+		if ex[i].Approved && ex[i].Until.After(time.Now()) {
+			active++
+		}
+	}
+	fmt.Fprintf(w, "exclusions\t%d, %d active\n", len(ex), active)
+
+	events, err := cb.tables.AuditSince(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "audit events\t%d in the last day\n", len(events))
+
+	return w.Flush()
+}
+
+// exclusionsCommand runs `cuebert exclusions list|add|revoke`.
+func exclusionsCommand(args []string) int {
+	pos, flags := cutArgs(args)
+
+	action := "list"
+	if len(pos) > 0 {
+		action, pos = pos[0], pos[1:]
+	}
+
+	var (
+		serial, reason string
+		until          time.Time
+	)
+
+	switch {
+	case action == "list" && len(pos) == 0:
+	case action == "add" && len(pos) >= 3:
+		t, err := time.Parse("2006-01-02", pos[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "until %q is not a date (2006-01-02)\n", pos[1])
+			return 1
+		}
+		serial, until, reason = pos[0], t, strings.Join(pos[2:], " ")
+	case action == "revoke" && len(pos) == 1:
+		serial = pos[0]
+	default:
+		fmt.Fprintln(os.Stderr, exclusionsUsage)
+		return 1
+	}
+
+	cb := cli(flags)
+
+	var err error
+	switch action {
+	case "list":
+		err = cb.listExclusions(os.Stdout)
+	case "add":
+		err = cb.bot.AddExclusion(cliActor(), serial, reason, until)
+	case "revoke":
+		err = cb.bot.RevokeExclusion(cliActor(), serial)
+	}
+	if err != nil {
+		cb.log.Err(err).Str("action", action).Str("serial", serial).Msg("could not change exclusions")
+		return 1
+	}
+
+	return 0
+}
+
+// listExclusions writes every exclusion.
+func (cb *Cuebert) listExclusions(out io.Writer) error {
+	ex, err := cb.tables.ExclusionBy().All().Query()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERIAL\tUSER\tAPPROVED\tUNTIL\tREASON")
+	for i := range ex {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\n",
+			ex[i].SerialNumber, ex[i].UserEmail, ex[i].Approved, ex[i].Until.Format("2006-01-02"), ex[i].Reason)
+	}
+
+	return w.Flush()
+}
+
+// usersCommand runs `cuebert users show <email>`.
+func usersCommand(args []string) int {
+	pos, flags := cutArgs(args)
+	if len(pos) != 2 || pos[0] != "show" {
+		fmt.Fprintln(os.Stderr, usersUsage)
+		return 1
+	}
+
+	cb := cli(flags)
+	if err := cb.showUser(os.Stdout, pos[1]); err != nil {
+		cb.log.Err(err).Str("email", pos[1]).Msg("could not get user info")
+		return 1
+	}
+
+	return 0
+}
+
+// showUser writes what is known about the user like `get users info`.
+func (cb *Cuebert) showUser(out io.Writer, email string) error {
+	info, err := cb.bot.UserInfo("email", email)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for i := range info.Users {
+		fmt.Fprintf(w, "user\t%s\t%s\t%s\n", info.Users[i].UserEmail, info.Users[i].UserLongName, info.Users[i].UserSlackID)
+	}
+	for i := range info.Devices {
+		fmt.Fprintf(w, "device\t%s\t%s\t%s\n", info.Devices[i].SerialNumber, info.Devices[i].Model, info.Devices[i].OSVersion)
+	}
+	for i := range info.BotResults {
+		r := info.BotResults[i]
+		fmt.Fprintf(w, "bot result\t%s\tcampaign %s\tmessaged %t\tacknowledged %t\tmanager alerted %t\n",
+			r.SerialNumber, r.CampaignID, r.FirstMessageSent, r.FirstACK, r.ManagerMessageSent)
+	}
+	for i := range info.Exclusions {
+		e := info.Exclusions[i]
+		fmt.Fprintf(w, "exclusion\t%s\tapproved %t\tuntil %s\t%s\n",
+			e.SerialNumber, e.Approved, e.Until.Format("2006-01-02"), e.Reason)
+	}
+	for i := range info.History {
+		h := info.History[i]
+		fmt.Fprintf(w, "history\t%s\tcampaign %s\t%s -> %s\tcompleted %s\n",
+			h.SerialNumber, h.CampaignID, h.OSVersion, h.RequiredOS, h.CompletedAt.Format(time.RFC3339))
+	}
+	if info.Email == "" || (info.Users.Empty() && info.Devices.Empty() && info.BotResults.Empty()) {
+		fmt.Fprintf(w, "no user found for %s\n", email)
+	}
+
+	return w.Flush()
+}
+
+// reportCommand runs `cuebert report <name>`. words in the name may be
+// joined by - or _ like the api, ex: manager-alerted.
+func reportCommand(args []string) int {
+	pos, flags := cutArgs(args)
+	format, flags := cutFlag(flags, "format", "table")
+	if len(pos) == 0 {
+		fmt.Fprintln(os.Stderr, reportUsage)
+		return 1
+	}
+
+	name := strings.Join(pos, " ")
+	name = strings.ToLower(strings.NewReplacer("-", " ", "_", " ").Replace(name))
+
+	switch format {
+	case "table", "csv", "json":
+	default:
+		fmt.Fprintln(os.Stderr, reportUsage)
+		return 1
+	}
+
+	cb := cli(flags)
+	if err := cb.report(os.Stdout, name, format); err != nil {
+		cb.log.Err(err).Str("report", name).Msg("could not build report")
+		return 1
+	}
+
+	return 0
+}
+
+// report writes the data of a report like `get report` in the format.
+func (cb *Cuebert) report(out io.Writer, name, format string) error {
+	vis, err := cb.bot.Report(name)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(vis.XAxis))
+	for i := range vis.XAxis {
+		rows = append(rows, []string{vis.XAxis[i], fmt.Sprint(vis.ValueList[i])})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+
+	switch format {
+	case "csv":
+		w := csv.NewWriter(out)
+		_ = w.Write([]string{"label", "count"})
+		_ = w.WriteAll(rows)
+		return w.Error()
+	case "json":
+		values := make([]map[string]any, 0, len(rows))
+		for i := range rows {
+			values = append(values, map[string]any{"label": rows[i][0], "count": rows[i][1]})
+		}
+		return json.NewEncoder(out).Encode(map[string]any{"report": name, "title": vis.Text, "values": values})
+	default:
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%s\tCOUNT\n", strings.ToUpper(vis.Text))
+		for i := range rows {
+			fmt.Fprintf(w, "%s\t%s\n", rows[i][0], rows[i][1])
+		}
+		return w.Flush()
+	}
+}
+
+// tablesCommand runs `cuebert tables rebuild`. the tables in -table-names
+// are cleared and built again from the mdm like starting with
+// -rebuild-tables-on-failure.
+func tablesCommand(args []string) int {
+	pos, flags := cutArgs(args)
+	if len(pos) != 1 || pos[0] != "rebuild" {
+		fmt.Fprintln(os.Stderr, tablesUsage)
+		return 1
+	}
+
+	cb := cli(flags)
+
+	client, err := cb.mdmClient()
+	if err != nil {
+		cb.log.Err(err).Msg("could not create mdm client")
+		return 1
+	}
+	cb.tables = cb.newTables(client)
+
+	cb.log.Info().Str("tables", cb.flags.tableNames).Msg("clearing tables")
+	if err := cb.tables.DeleteTables(cb.flags.tableNames); err != nil {
+		cb.log.Err(err).Msg("could not clear tables")
+		return 1
+	}
+
+	cb.log.Info().Msg("initializing tables")
+	check, err := cb.tables.InitTables(cb.policy)
+	if err != nil {
+		cb.log.Err(err).Msg("could not initialize tables")
+		return 1
+	}
+
+	if len(check) > 0 {
+		fmt.Fprintf(os.Stdout, "mdm users not matched in slack: %s\n", strings.Join(check, ", "))
+	}
+
+	return 0
+}
@@ -1,9 +1,17 @@
 package main
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/johnmikee/cuebert/cuebert/bot"
+	"github.com/johnmikee/cuebert/cuebert/events"
 	"github.com/johnmikee/cuebert/cuebert/handlers"
 	"github.com/johnmikee/cuebert/cuebert/method"
+	"github.com/johnmikee/cuebert/cuebert/playbook"
+	"github.com/johnmikee/cuebert/cuebert/policy"
 	"github.com/johnmikee/cuebert/cuebert/tables"
 	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/idp"
@@ -15,12 +23,14 @@ import (
 type Cuebert struct {
 	bot           *bot.Bot
 	config        *Config
-	db            *db.DB
+	db            db.Store
 	flags         *Flags
 	log           logger.Logger
 	idp           idp.Provider
 	mdm           mdm.Provider
 	method        method.Actions
+	playbook      *playbook.Engine
+	policy        *policy.Policy
 	tables        *tables.Config
 	authUsers     []string
 	testUsers     []string
@@ -29,26 +39,41 @@ type Cuebert struct {
 	statusHandler *handlers.StatusHandler
 	startSignal   chan struct{}
 	stopSignal    chan struct{}
-	isRunning     bool
+	running       atomic.Bool // the routines are running
+	enforcedAt    time.Time   // last time the deadline action ran
+	campaigns     map[string]*campaign
+	campaignLock  sync.Mutex
+	events        *events.Server // relays slack's requests in http mode
+	mdmPulledAt   atomic.Int64   // unix nanoseconds of the last successful mdm pull
+
+	shutdownTracing func(context.Context) error // flushes spans not yet exported
 }
 
 // Config holds the sensitive values for the program
 type Config struct {
-	AdminGroupID      string `json:"admin_group_id"`
-	DBAddress         string `json:"db_address"`
-	DBName            string `json:"db_name"`
-	DBPass            string `json:"db_pass"`
-	DBPort            string `json:"db_port"`
-	DBUser            string `json:"db_user"`
-	IDPDomain         string `json:"idp_domain"`
-	IDPToken          string `json:"idp_token"`
-	IDPURL            string `json:"idp_url"`
-	MDMKey            string `json:"mdm_key"`
-	MDMURL            string `json:"mdm_url"`
-	SlackAppToken     string `json:"slack_app_token"`
-	SlackAlertChannel string `json:"slack_alert_channel"`
-	SlackBotToken     string `json:"slack_bot_token"`
-	SlackBotID        string `json:"slack_bot_id"`
+	AdminGroupID       string `json:"admin_group_id"`
+	APIToken           string `json:"api_token"`
+	DBAddress          string `json:"db_address"`
+	DBName             string `json:"db_name"`
+	DBPass             string `json:"db_pass"`
+	DBPort             string `json:"db_port"`
+	DBUser             string `json:"db_user"`
+	IDPDomain          string `json:"idp_domain"`
+	IDPPassword        string `json:"idp_password"`
+	IDPToken           string `json:"idp_token"`
+	IDPTokenURL        string `json:"idp_token_url"`
+	IDPURL             string `json:"idp_url"`
+	IDPUser            string `json:"idp_user"`
+	MDMKey             string `json:"mdm_key"`
+	MDMPassword        string `json:"mdm_password"`
+	MDMTokenURL        string `json:"mdm_token_url"`
+	MDMURL             string `json:"mdm_url"`
+	MDMUser            string `json:"mdm_user"`
+	SlackAppToken      string `json:"slack_app_token"`
+	SlackAlertChannel  string `json:"slack_alert_channel"`
+	SlackBotToken      string `json:"slack_bot_token"`
+	SlackBotID         string `json:"slack_bot_id"`
+	SlackSigningSecret string `json:"slack_signing_secret"`
 }
 
 // Flags holds the args for the program
@@ -56,63 +81,105 @@ type Config struct {
 // init is particularly useful when running in a container and you want
 // be able to start, stop, and change the config from the bot itself.
 type Flags struct {
-	method                  string // ex: manager or time-bound. this sets the cadence for the flow of the program
-	authUsers               string // comma separated list of users to perform authorized actions
-	authUsersFromIDP        bool   // pull authorized users from the idp. if false use the auth-users flag
-	checkInterval           int    // how often to check what cuebert messages need sending
-	clearTables             bool   // clear all tables
-	cutoffTime              string // cutoffTime will be the time access is revoked
-	dailyReport             bool   // send a daily report to the slack channel
-	deadline                string // the day the update is required
-	defaultReminderInterval int    // how often to remind users to update their devices (time-bound only)
-	deviceDiffInterval      int    // how often to check what devices we need to add/remove
-	envType                 string // ex: dev, prod
-	helpDocsURL             string // url to the help docs
-	helpRepoURL             string // url to this repo for the help menu
-	helpTicketURL           string // url to the help ticketing system
-	idp                     string // ex: okta, onelogin
-	init                    bool   // initialize the program and wait for input.
-	logLevel                string // ex: debug, trace, info, warn, error
-	logToFile               bool   // log to file defaults to false
-	mdm                     string // ex: jamf, kandji
-	pollInterval            int    // how often to poll for reminders
-	requiredVers            string // ex: 13.1
-	rebuildTablesOnFailure  bool   // rebuild tables on an abnormal exit.
-	sendManagerMissing      bool   // send a message to the alert channel of missing managers
-	serviceName             string // ex: cuebert
-	tableNames              string // comma separated list of tables to clear
-	testing                 bool   // run in testing mode
-	testingEndTime          string // the hour the messaging should end
-	testingStartTime        string // the hour the messaging should start
-	testingUsers            string // comma separated list of users to test with
+	method                  string  // ex: manager or time-bound. this sets the cadence for the flow of the program
+	apiAllowStop            bool    // let the admin api stop cuebert without the second approval
+	authUsers               string  // comma separated list of users to perform authorized actions
+	authUsersFromIDP        bool    // pull authorized users from the idp. if false use the auth-users flag
+	checkInterval           int     // how often to check what cuebert messages need sending
+	clearTables             bool    // clear all tables
+	cutoffTime              string  // cutoffTime will be the time access is revoked
+	dailyReport             bool    // send a daily report to the slack channel
+	dbDriver                string  // ex: postgres, sqlite
+	dbPath                  string  // the sqlite database file
+	deadline                string  // the day the update is required
+	deadlineAction          string  // ex: schedule_update, force_install, remediate
+	deadlineGroup           string  // blueprint, group or team to remediate devices into
+	defaultReminderInterval int     // how often to remind users to update their devices (time-bound only)
+	deviceDiffInterval      int     // how often to check what devices we need to add/remove
+	envType                 string  // ex: dev, prod
+	healthAddr              string  // address the health, readiness and metrics endpoints listen on
+	fleetLabelID            uint    // limit fleet hosts to a label
+	fleetTeamID             uint    // limit fleet hosts to a team
+	helpDocsURL             string  // url to the help docs
+	helpRepoURL             string  // url to this repo for the help menu
+	helpTicketURL           string  // url to the help ticketing system
+	idp                     string  // ex: entra, google, ldap, okta, scim
+	init                    bool    // initialize the program and wait for input.
+	ldapBaseDN              string  // where ldap searches start. defaults to the idp domain
+	ldapUserFilter          string  // the ldap filter used to find users
+	logLevel                string  // ex: debug, trace, info, warn, error
+	logToFile               bool    // log to file defaults to false
+	mdm                     string  // ex: file, fleet, intune, jamf, kandji
+	mdmBurst                int     // requests sent to the mdm at once before limiting
+	mdmCacheTTL             int     // seconds mdm results are reused
+	mdmMaxRetries           int     // retries for throttled or failed mdm requests
+	mdmRateLimit            float64 // requests per second sent to the mdm
+	osCatalog               string  // path or url to the SOFA release feed
+	otlpEndpoint            string  // host:port of the OTLP/HTTP trace collector
+	otlpInsecure            bool    // send traces over http instead of https
+	osPolicy                string  // path to the per model and major version os policy
+	playbook                string  // path to the escalation playbook
+	pollInterval            int     // how often to poll for reminders
+	requiredVers            string  // ex: 13.1
+	rebuildTablesOnFailure  bool    // rebuild tables on an abnormal exit.
+	sendManagerMissing      bool    // send a message to the alert channel of missing managers
+	serviceName             string  // ex: cuebert
+	slackAddr               string  // address slack sends requests to in http mode
+	slackMode               string  // ex: socket, http
+	tableNames              string  // comma separated list of tables to clear
+	testing                 bool    // run in testing mode
+	testingEndTime          string  // the hour the messaging should end
+	testingStartTime        string  // the hour the messaging should start
+	testingUsers            string  // comma separated list of users to test with
 }
 
 // TODO: this needs to log the bot flags via an interface
 func (c *Cuebert) logFlags() {
 	c.log.Trace().
+		Bool("apiAllowStop", c.flags.apiAllowStop).
 		Str("authUsers", c.flags.authUsers).
 		Bool("authUsersFromIDP", c.flags.authUsersFromIDP).
 		Int("checkInterval", c.flags.checkInterval).
 		Bool("clearTables", c.flags.clearTables).
 		Str("cutoffTime", c.flags.cutoffTime).
 		Bool("dailyReport", c.flags.dailyReport).
+		Str("dbDriver", c.flags.dbDriver).
+		Str("dbPath", c.flags.dbPath).
 		Str("deadline", c.flags.deadline).
+		Str("deadlineAction", c.flags.deadlineAction).
+		Str("deadlineGroup", c.flags.deadlineGroup).
 		Int("deviceDiffInterval", c.flags.deviceDiffInterval).
 		Str("envType", c.flags.envType).
+		Str("healthAddr", c.flags.healthAddr).
+		Uint("fleetLabelID", c.flags.fleetLabelID).
+		Uint("fleetTeamID", c.flags.fleetTeamID).
 		Str("helpDocsURL", c.flags.helpDocsURL).
 		Str("helpRepoURL", c.flags.helpRepoURL).
 		Str("helpTicketURL", c.flags.helpTicketURL).
 		Str("idp", c.flags.idp).
 		Bool("init", c.flags.init).
+		Str("ldapBaseDN", c.flags.ldapBaseDN).
+		Str("ldapUserFilter", c.flags.ldapUserFilter).
 		Str("tableNames", c.flags.tableNames).
 		Str("logLevel", c.flags.logLevel).
 		Bool("logToFile", c.flags.logToFile).
 		Str("mdm", c.flags.mdm).
+		Int("mdmBurst", c.flags.mdmBurst).
+		Int("mdmCacheTTL", c.flags.mdmCacheTTL).
+		Int("mdmMaxRetries", c.flags.mdmMaxRetries).
+		Float64("mdmRateLimit", c.flags.mdmRateLimit).
+		Str("osCatalog", c.flags.osCatalog).
+		Str("otlpEndpoint", c.flags.otlpEndpoint).
+		Bool("otlpInsecure", c.flags.otlpInsecure).
+		Str("osPolicy", c.flags.osPolicy).
+		Str("playbook", c.flags.playbook).
 		Int("pollInterval", c.flags.pollInterval).
 		Str("requiredVersion", c.flags.requiredVers).
 		Bool("rebuildTablesOnFailure", c.flags.rebuildTablesOnFailure).
 		Bool("sendManagerMissing", c.flags.sendManagerMissing).
 		Str("serviceName", c.flags.serviceName).
+		Str("slackAddr", c.flags.slackAddr).
+		Str("slackMode", c.flags.slackMode).
 		Str("tableNames", c.flags.tableNames).
 		Bool("testing", c.flags.testing).
 		Str("testingEndTime", c.flags.testingEndTime).
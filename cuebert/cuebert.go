@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/handlers"
-	"github.com/johnmikee/cuebert/db/create"
+	"github.com/johnmikee/cuebert/cuebert/tracing"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
 	c := setup()
 	// suppress slacker logs
 	os.Stdout = nil
@@ -17,7 +25,9 @@ func main() {
 	c.log.Info().Msg("cuebert time!")
 
 	c.log.Info().Msg("starting health handler...")
-	go c.statusHandler.StartHealthHandler()
+	c.addReadyChecks()
+	c.serveAPI()
+	go c.statusHandler.StartHealthHandler(c.flags.healthAddr)
 
 	if c.flags.dailyReport {
 		err := c.bot.SendDailyAdminReport()
@@ -39,34 +49,47 @@ func main() {
 
 	// send cuebert off to handle questions
 	go c.bot.Respond()
+	go c.serveEvents()
 	// wait for a signal to start
 	go c.handler()
 
 	if !c.flags.init {
-		c.start()
+		c.Start()
 	} else {
 		var check []string
 		if c.flags.rebuildTablesOnFailure {
-			c.log.Info().Msg("building tables")
-			err := create.Build(c.db, &c.log)
+			c.log.Info().Msg("clearing tables")
+			err := c.tables.DeleteTables(c.flags.tableNames)
 			if err != nil {
-				c.log.Err(err).Msg("could not build tables")
+				c.log.Err(err).Msg("could not clear tables")
 				os.Exit(3)
 			}
 			c.log.Info().Msg("initializing tables")
-			check, err = c.tables.InitTables(c.flags.requiredVers)
+			check, err = c.tables.InitTables(c.policy)
 			if err != nil {
 				c.log.Err(err).Msg("could not initialize tables")
 				os.Exit(3)
 			}
 		}
-		go c.method.PostCheck(check)
+		go func() {
+			ctx, span := tracing.Start(context.Background(), "postCheck")
+			defer span.End()
+
+			c.method.PostCheck(ctx, check)
+		}()
 	}
 
 	quitChannel := make(chan os.Signal, 1)
 	signal.Notify(quitChannel, syscall.SIGINT, syscall.SIGTERM)
 	<-quitChannel
 
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.shutdownTracing(ctx); err != nil {
+		c.log.Err(err).Msg("could not flush traces")
+	}
+
 	c.log.Info().Msg("cuebye!")
 }
 
@@ -86,7 +109,6 @@ func (c *Cuebert) handler() {
 		case <-c.stopSignal:
 			// Stop executing subsequent functions and wait for start signal again
 			c.log.Info().Msg("stopping routines")
-			c.isRunning = false
 		}
 	}
 }
@@ -1,23 +1,78 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"time"
+
+	"github.com/johnmikee/cuebert/db/campaigns"
 )
 
-func (c *Cuebert) checkDeadline(time.Time) {
+// deadlineTime returns the deadline date and cutoff time as a time.
+func (c *Cuebert) deadlineTime() (time.Time, error) {
+	return c.policy.Default.Due(c.flags.cutoffTime)
+}
+
+// checkDeadline runs the deadline of each active campaign. Campaigns are
+// closed once their deadline has nothing left to do and the routines are
+// stopped when no campaign is left open.
+func (c *Cuebert) checkDeadline(ctx context.Context, _ time.Time) {
 	now := time.Now()
-	deadline := fmt.Sprintf("%s %s", c.flags.deadline, c.flags.cutoffTime)
 
-	layout := "01-02-2006 15:04"
-	t, err := time.Parse(layout, deadline)
+	closed := false
+	for _, camp := range c.activeCampaigns() {
+		if camp.info.ID != campaigns.Default {
+			closed = c.campaignDeadline(ctx, camp, now) || closed
+			continue
+		}
+
+		if c.defaultDeadline(ctx, now) {
+			c.closeCampaign(campaigns.Default)
+			closed = true
+		}
+	}
+
+	if closed && !c.campaignsOpen() {
+		c.log.Info().Msg("every campaign is closed")
+		c.Stop()
+	}
+}
+
+// defaultDeadline runs the playbook steps that are due, or the method deadline
+// action each time a policy deadline passes when there is no playbook. It
+// reports if the latest deadline has nothing left to do.
+func (c *Cuebert) defaultDeadline(ctx context.Context, now time.Time) bool {
+	t, err := c.deadlineTime()
+	if err != nil {
+		c.log.Err(err).Msg("error parsing deadline")
+		return false
+	}
+
+	deadlines, err := c.policy.Deadlines(c.flags.cutoffTime)
 	if err != nil {
-		fmt.Println("Error parsing time:", err)
-		return
+		c.log.Err(err).Msg("error parsing policy deadlines")
+		return false
 	}
+	first, last := deadlines[0], deadlines[len(deadlines)-1]
 
-	if now.After(t) {
-		c.method.Deadline()
+	if c.playbook != nil {
+		c.playbook.Run(ctx, now, first, t)
+		if c.playbook.Done(now, last) {
+			c.log.Info().Msg("every playbook step has run")
+			return true
+		}
+		return false
 	}
-	c.stop()
+
+	// enforcement skips devices whose rule is not due yet or that were
+	// already enforced, so it only needs to run again once another deadline
+	// has passed. after a restart it runs once more for the devices left.
+	for _, d := range deadlines {
+		if now.After(d) && d.After(c.enforcedAt) {
+			c.method.Deadline(ctx)
+			c.enforcedAt = now
+			break
+		}
+	}
+
+	return now.After(last)
 }
@@ -9,7 +9,7 @@ import (
 
 // Device is the internal struct for the device package
 type Device struct {
-	db     *db.DB
+	db     db.Store
 	client mdm.Provider
 	log    logger.Logger
 }
@@ -17,7 +17,7 @@ type Device struct {
 // Config is the configuration for the Device struct.
 type Config struct {
 	Client mdm.Provider
-	DB     *db.DB
+	DB     db.Store
 	Log    *logger.Logger
 }
 
@@ -47,7 +47,7 @@ func (c *Device) AddAllDevices() error {
 	machines := devices.DI{}
 
 	for i := range res {
-		if res[i].Platform == "Mac" {
+		if mdm.NormalizePlatform(res[i].Platform) == mdm.MacOS {
 			di := devices.Info{
 				DeviceID:     res[i].DeviceID,
 				DeviceName:   res[i].DeviceName,
@@ -1,20 +1,23 @@
 package main
 
 import (
-	"strings"
+	"context"
 	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/handlers"
+	"github.com/johnmikee/cuebert/cuebert/metrics"
 	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/cuebert/tracing"
 	"github.com/johnmikee/cuebert/db/devices"
 	"github.com/johnmikee/cuebert/mdm"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 )
 
-var checkPlatforms = []string{"mac", "macos"}
+// checkPlatforms are the normalized platforms cuebert will track.
+var checkPlatforms = []string{mdm.MacOS}
 
 // pull info from db and compare to mdm and update where necessary
-func (c *Cuebert) deviceDiff(time.Time) {
+func (c *Cuebert) deviceDiff(ctx context.Context, _ time.Time) {
 	c.log.Trace().Msg("checking if we need to add any devices..")
 	go c.statusHandler.UpdateStatus(
 		&handlers.RoutineUpdate{
@@ -28,39 +31,55 @@ func (c *Cuebert) deviceDiff(time.Time) {
 			Err:    false,
 		}, "diff")
 
-	md, err := c.mdm.ListDevices()
+	// pick up new releases before checking who needs to update.
+	c.reloadCatalog()
+
+	md, err := tracing.MDM(ctx, c.mdm).ListDevices()
 	if err != nil {
 		c.log.Debug().AnErr("getting devices from mdm", err).Send()
+		metrics.RoutineFailed(metrics.Diff)
 		return
 	}
+	c.mdmPulled(time.Now())
+
+	t := c.tables.WithContext(ctx)
 
-	ds, versCheck, err := c.tables.GatherDiffDevicesDB()
+	ds, versCheck, err := t.GatherDiffDevicesDB()
 
 	if err != nil {
 		c.log.Debug().AnErr("getting devices from db", err).Send()
+		metrics.RoutineFailed(metrics.Diff)
 		return
 	}
 
+	// the devices table holds every device that misses an active campaign.
+	camps := c.activeCampaigns()
+
+	metrics.SetOutOfCompliance(outOfCompliance(camps, md))
+
 	c.log.Debug().Msg("checking for missing devices")
-	updates := checkMissingDevices(ds, c.flags.requiredVers, md)
+	updates := checkMissingDevices(ds, camps, md)
 
 	c.log.Debug().Msg("checking for devices needing to be removed")
-	remove := tables.CheckStaleDevices(c.flags.requiredVers, versCheck, md)
+	remove := tables.CheckStaleDevices(camps, versCheck, md)
 
 	c.log.Trace().Interface("devices", updates).Msg("adding devices")
 
-	err = c.tables.AddAll(updates)
+	err = t.AddAll(updates)
 	if err != nil {
 		c.log.Debug().AnErr("adding devices", err).Send()
 	}
 
 	c.log.Trace().Strs("devices", remove).Msg("removing devices")
-	c.method.DeviceDiff(remove)
-	_, err = c.tables.RemoveDeviceBy().Serial(remove...).Execute()
+	_, err = t.RemoveDeviceBy().Serial(remove...).Execute()
 	if err != nil {
 		c.log.Debug().AnErr("removing devices", err).Send()
 	}
 
+	for _, camp := range camps {
+		c.campaignDiff(ctx, camp, md)
+	}
+
 	go c.statusHandler.UpdateStatus(
 		&handlers.RoutineUpdate{
 			Routine: &handlers.RoutineStatus{
@@ -79,18 +98,17 @@ func (c *Cuebert) deviceDiff(time.Time) {
 
 // checkMissingDevices checks for devices that are missing by polling
 // the MDM and comparing the results to the DB. If the device is missing
-// and the OS version is lower than the required version, it will be added.
-func checkMissingDevices(ds []string, reqVers string, mdmDevices mdm.DeviceResults) devices.DI {
+// and the OS version does not meet its policy rule, it will be added.
+func checkMissingDevices(ds []string, p tables.Compliance, mdmDevices mdm.DeviceResults) devices.DI {
 	updates := devices.DI{}
 
 	for i := range mdmDevices {
 		// check missing
 		if !helpers.Contains(ds, mdmDevices[i].SerialNumber) {
-			ok, _ := helpers.CompareOSVer(mdmDevices[i].OSVersion, reqVers)
-			if !ok {
+			if !p.Compliant(mdmDevices[i].Model, mdmDevices[i].OSVersion) {
 				// check that the platform is macOS
 				// V2: Add multiple OS support
-				if helpers.Contains(checkPlatforms, strings.ToLower(mdmDevices[i].Platform)) {
+				if helpers.Contains(checkPlatforms, mdm.NormalizePlatform(mdmDevices[i].Platform)) {
 					di := devices.Info{
 						DeviceID:     mdmDevices[i].DeviceID,
 						DeviceName:   mdmDevices[i].DeviceName,
@@ -111,3 +129,21 @@ func checkMissingDevices(ds []string, reqVers string, mdmDevices mdm.DeviceResul
 
 	return updates
 }
+
+// outOfCompliance counts the tracked devices that miss an active campaign by
+// os version.
+func outOfCompliance(p tables.Compliance, mdmDevices mdm.DeviceResults) map[string]int {
+	counts := map[string]int{}
+
+	for i := range mdmDevices {
+		if !helpers.Contains(checkPlatforms, mdm.NormalizePlatform(mdmDevices[i].Platform)) {
+			continue
+		}
+
+		if !p.Compliant(mdmDevices[i].Model, mdmDevices[i].OSVersion) {
+			counts[mdmDevices[i].OSVersion]++
+		}
+	}
+
+	return counts
+}
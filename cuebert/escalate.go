@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/method"
+	"github.com/johnmikee/cuebert/cuebert/playbook"
+	"github.com/johnmikee/cuebert/cuebert/policy"
+	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/cuebert/tracing"
+	"github.com/johnmikee/cuebert/db/campaigns"
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/logger"
+	"github.com/slack-go/slack"
+)
+
+// playbookSource returns the devices left in bot_results without an active
+// exclusion along with the deadline of the policy rule each device matches.
+type playbookSource struct {
+	log    logger.Logger
+	tables *tables.Config
+	policy *policy.Policy
+	cutoff string
+}
+
+func (p *playbookSource) Targets(now time.Time) ([]playbook.Target, error) {
+	br, err := p.tables.GetBotTableInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []playbook.Target{}
+	for i := range br {
+		excluded, err := p.tables.ExclusionActive(br[i].SerialNumber, now)
+		if err != nil {
+			p.log.Err(err).
+				Str("serial", br[i].SerialNumber).
+				Msg("could not check for an exclusion. skipping the device")
+			continue
+		}
+		if excluded {
+			continue
+		}
+
+		targets = append(targets, playbook.Target{
+			SerialNumber:   br[i].SerialNumber,
+			SlackID:        br[i].SlackID,
+			ManagerSlackID: br[i].ManagerSlackID,
+			UserEmail:      br[i].UserEmail,
+			FullName:       br[i].FullName,
+			Deadline:       p.deadline(br[i].SerialNumber),
+		})
+	}
+
+	return targets, nil
+}
+
+// deadline returns when the rule the device matches is due. The zero time is
+// returned if the device cannot be found so the default deadline is used.
+func (p *playbookSource) deadline(serial string) time.Time {
+	dev, err := p.tables.DeviceBySerial(serial)
+	if err != nil || dev.Empty() {
+		return time.Time{}
+	}
+
+	due, err := p.policy.Match(dev[0].Model, dev[0].OSVersion).Due(p.cutoff)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return due
+}
+
+// playbookStore persists the completed steps in the playbook_steps table.
+type playbookStore struct {
+	tables *tables.Config
+}
+
+func (p *playbookStore) Completed(serials []string) (map[string]map[string]bool, error) {
+	done := map[string]map[string]bool{}
+	if len(serials) == 0 {
+		return done, nil
+	}
+
+	steps, err := p.tables.CompletedSteps(serials...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range steps {
+		if done[steps[i].SerialNumber] == nil {
+			done[steps[i].SerialNumber] = map[string]bool{}
+		}
+		done[steps[i].SerialNumber][steps[i].Step] = true
+	}
+
+	return done, nil
+}
+
+func (p *playbookStore) Complete(serial, step string, at time.Time) error {
+	return p.tables.CompleteStep(serial, step, at)
+}
+
+// playbookRunner carries out the steps through Slack, the MDM and the ticket webhook.
+type playbookRunner struct {
+	sc           *slack.Client
+	alertChannel string
+	mdm          mdm.Provider
+	tables       *tables.Config
+	policy       *policy.Policy
+	ticketURL    string
+	client       *http.Client
+}
+
+// ticket is the body posted to the ticket webhook.
+type ticket struct {
+	Step           string `json:"step"`
+	SerialNumber   string `json:"serial_number"`
+	UserEmail      string `json:"user_email"`
+	FullName       string `json:"full_name"`
+	SlackID        string `json:"slack_id"`
+	ManagerSlackID string `json:"manager_slack_id"`
+	Deadline       string `json:"deadline"`
+	Message        string `json:"message"`
+}
+
+func (p *playbookRunner) Run(ctx context.Context, step *playbook.Step, t *playbook.Target, deadline time.Time) error {
+	msg, err := step.Render(t, deadline)
+	if err != nil {
+		return err
+	}
+
+	switch step.Action {
+	case playbook.Message:
+		return p.message(ctx, step.Audience, t, msg)
+	case playbook.Enforce:
+		enforcer, ok := tracing.Enforcer(ctx, p.mdm)
+		if !ok {
+			return mdm.ErrActionNotSupported
+		}
+		return method.EnforceDevice(enforcer, p.tables, p.policy, t.SerialNumber, step.MDMAction, &mdm.EnforceOpts{
+			Group: step.Group,
+		})
+	case playbook.Ticket:
+		return p.ticket(ctx, &ticket{
+			Step:           step.Name,
+			SerialNumber:   t.SerialNumber,
+			UserEmail:      t.UserEmail,
+			FullName:       t.FullName,
+			SlackID:        t.SlackID,
+			ManagerSlackID: t.ManagerSlackID,
+			Deadline:       deadline.Format(time.RFC3339),
+			Message:        msg,
+		})
+	default:
+		return fmt.Errorf("unknown playbook action %q", step.Action)
+	}
+}
+
+func (p *playbookRunner) message(ctx context.Context, audience playbook.Audience, t *playbook.Target, msg string) error {
+	var channel string
+	switch audience {
+	case playbook.User:
+		channel = t.SlackID
+	case playbook.Manager:
+		channel = t.ManagerSlackID
+	case playbook.Channel:
+		channel = p.alertChannel
+	}
+
+	if channel == "" {
+		return fmt.Errorf("no %s to message for %s", audience, t.SerialNumber)
+	}
+
+	_, _, err := p.sc.PostMessageContext(ctx, channel, slack.MsgOptionText(msg, false))
+
+	return err
+}
+
+func (p *playbookRunner) ticket(ctx context.Context, t *ticket) error {
+	body, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.ticketURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error creating ticket. status code=%d error: %s", resp.StatusCode, string(b))
+	}
+
+	return nil
+}
+
+// newPlaybook loads the playbook and wires it to the tables, Slack and the MDM.
+// The playbook escalates the devices of the default campaign.
+func (c *Cuebert) newPlaybook(path string) (*playbook.Engine, error) {
+	pb, err := playbook.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &playbook.Engine{
+		Log:      logger.ChildLogger("playbook", &c.log),
+		Playbook: pb,
+		Source: &playbookSource{
+			log:    c.log,
+			tables: c.tables.ForCampaign(campaigns.Default),
+			policy: c.policy,
+			cutoff: c.flags.cutoffTime,
+		},
+		Store: &playbookStore{tables: c.tables},
+		Runner: &playbookRunner{
+			sc:           c.bot.Client(),
+			alertChannel: c.config.SlackAlertChannel,
+			mdm:          c.mdm,
+			tables:       c.tables,
+			policy:       c.policy,
+			ticketURL:    pb.TicketURL,
+			client:       &http.Client{Timeout: 30 * time.Second},
+		},
+		Testing:      c.flags.testing,
+		TestingUsers: c.testUsers,
+	}, nil
+}
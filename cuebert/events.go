@@ -0,0 +1,20 @@
+package main
+
+import (
+	"context"
+	"os"
+)
+
+// serveEvents receives slack's requests when -slack-mode is http. cuebert
+// cannot hear from slack without it so a failure to listen exits.
+func (c *Cuebert) serveEvents() {
+	if c.events == nil {
+		return
+	}
+
+	err := c.events.ListenAndServe(context.Background())
+	if err != nil {
+		c.log.Err(err).Str("addr", c.flags.slackAddr).Msg("could not listen for slack requests")
+		os.Exit(3)
+	}
+}
@@ -0,0 +1,373 @@
+// Package events serves slack's Events API, interactivity and slash commands
+// over http for workspaces that do not allow socket mode apps.
+//
+// slacker only speaks socket mode, so every request slack signs is relayed to
+// it over a socket mode connection on the loopback interface. Respond routes
+// the commands and interactions the same way in both modes.
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/johnmikee/cuebert/pkg/logger"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// The paths to set as the request urls of the slack app.
+const (
+	EventsPath        = "/slack/events"
+	InteractivityPath = "/slack/interactivity"
+	CommandsPath      = "/slack/commands"
+)
+
+const (
+	// maxBody is the largest request read from slack.
+	maxBody = 1 << 20
+	// pingInterval is how often the relay pings slacker. socket mode
+	// reconnects when it does not hear a ping for 30 seconds.
+	pingInterval = 10 * time.Second
+)
+
+// errNotConnected is returned while slacker is not connected to the relay.
+// slack retries the request when it is answered with an error.
+var errNotConnected = errors.New("slacker is not connected")
+
+// Config holds what the server needs to receive requests from slack.
+type Config struct {
+	Addr          string // address slack sends requests to, ex: :3000
+	SigningSecret string
+	Log           logger.Logger
+}
+
+// Server verifies the requests slack sends and relays them to slacker.
+type Server struct {
+	addr     string
+	secret   string
+	log      logger.Logger
+	socket   net.Listener // loopback listener slacker connects to
+	path     string       // random path slacker is given to connect on
+	upgrader websocket.Upgrader
+
+	lock sync.Mutex
+	conn *websocket.Conn
+}
+
+// New returns the server for the config. The loopback listener is opened so
+// the url slacker connects to is known before the bot starts.
+func New(c *Config) (*Server, error) {
+	if c.SigningSecret == "" {
+		return nil, errors.New("a signing secret is needed to verify requests from slack")
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("opening the relay listener: %w", err)
+	}
+
+	return &Server{
+		addr:   c.Addr,
+		secret: c.SigningSecret,
+		log:    logger.ChildLogger("events", &c.Log),
+		socket: l,
+		path:   "/" + randomID(),
+		upgrader: websocket.Upgrader{
+			// socket mode dials with slack's origin. the random path keeps
+			// other local processes out.
+			CheckOrigin: func(r *http.Request) bool {
+				return r.Header.Get("Origin") == "https://api.slack.com"
+			},
+		},
+	}, nil
+}
+
+// URL is the socket mode url slacker is given in place of slack's.
+func (s *Server) URL() string {
+	return "ws://" + s.socket.Addr().String() + s.path
+}
+
+// Transport answers apps.connections.open, which socket mode calls to get
+// the url to connect to, with the url of the relay. Every other request is
+// sent with next.
+func (s *Server) Transport(next http.RoundTripper) http.RoundTripper {
+	return roundTripper(func(r *http.Request) (*http.Response, error) {
+		if !strings.HasSuffix(r.URL.Path, "/apps.connections.open") {
+			return next.RoundTrip(r)
+		}
+
+		body, err := json.Marshal(map[string]any{"ok": true, "url": s.URL()})
+		if err != nil {
+			return nil, err
+		}
+
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{"application/json"}},
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       r,
+		}, nil
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Handler returns the routes slack sends requests to. Requests that are not
+// signed with the signing secret, or were signed more than five minutes ago,
+// get a 401.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(EventsPath, s.verify(s.events))
+	mux.Handle(InteractivityPath, s.verify(s.interactivity))
+	mux.Handle(CommandsPath, s.verify(s.commands))
+
+	return mux
+}
+
+// ListenAndServe serves the relay and the routes on the address until the
+// context is done.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	relay := &http.Server{Handler: http.HandlerFunc(s.accept), ReadHeaderTimeout: 10 * time.Second}
+	srv := &http.Server{Addr: s.addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		_ = relay.Close()
+		_ = srv.Close()
+	}()
+
+	go func() {
+		err := relay.Serve(s.socket)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Err(err).Msg("serving the relay")
+		}
+	}()
+
+	s.log.Info().Str("addr", s.addr).Msg("listening for slack requests")
+
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// verify checks the signature and timestamp of the request before passing
+// the body on.
+func (s *Server) verify(next func(http.ResponseWriter, *http.Request, []byte)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBody))
+		if err != nil {
+			http.Error(w, "could not read request", http.StatusBadRequest)
+			return
+		}
+
+		sv, err := slack.NewSecretsVerifier(r.Header, s.secret)
+		if err == nil {
+			_, _ = sv.Write(body)
+			err = sv.Ensure()
+		}
+		if err != nil {
+			s.log.Debug().AnErr("verifying request", err).Str("path", r.URL.Path).Send()
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r, body)
+	})
+}
+
+// events relays the Events API callbacks. the url verification slack sends
+// when the request url is set is answered here.
+func (s *Server) events(w http.ResponseWriter, r *http.Request, body []byte) {
+	event, err := slackevents.ParseEvent(body, slackevents.OptionNoVerifyToken())
+	if err != nil {
+		http.Error(w, "could not parse event", http.StatusBadRequest)
+		return
+	}
+
+	if event.Type == slackevents.URLVerification {
+		var challenge slackevents.ChallengeResponse
+		if err := json.Unmarshal(body, &challenge); err != nil {
+			http.Error(w, "could not parse challenge", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(challenge.Challenge))
+		return
+	}
+
+	s.respond(w, s.relay(socketmode.RequestTypeEventsAPI, body))
+}
+
+// interactivity relays the button presses and modal submissions. slack sends
+// them as a form with the callback in the payload field.
+func (s *Server) interactivity(w http.ResponseWriter, r *http.Request, body []byte) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil || form.Get("payload") == "" {
+		http.Error(w, "could not parse payload", http.StatusBadRequest)
+		return
+	}
+
+	s.respond(w, s.relay(socketmode.RequestTypeInteractive, json.RawMessage(form.Get("payload"))))
+}
+
+// commands relays the slash commands. slack sends them as a form, socket
+// mode as json.
+func (s *Server) commands(w http.ResponseWriter, r *http.Request, body []byte) {
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	cmd, err := slack.SlashCommandParse(r)
+	if err != nil {
+		http.Error(w, "could not parse command", http.StatusBadRequest)
+		return
+	}
+
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		http.Error(w, "could not parse command", http.StatusBadRequest)
+		return
+	}
+
+	s.respond(w, s.relay(socketmode.RequestTypeSlashCommands, payload))
+}
+
+// respond acknowledges the request like socket mode does, before the bot
+// handles it. slack retries requests answered with an error.
+func (s *Server) respond(w http.ResponseWriter, err error) {
+	if err != nil {
+		s.log.Err(err).Msg("relaying request")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// relay sends the payload to slacker in a socket mode envelope.
+func (s *Server) relay(typ string, payload json.RawMessage) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn == nil {
+		return errNotConnected
+	}
+
+	return s.conn.WriteJSON(socketmode.Request{
+		Type:       typ,
+		EnvelopeID: randomID(),
+		Payload:    payload,
+	})
+}
+
+// accept takes the socket mode connection from slacker. a new connection
+// replaces the last one, like slack does when socket mode reconnects.
+func (s *Server) accept(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != s.path {
+		http.NotFound(w, r)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.log.Err(err).Msg("upgrading the relay connection")
+		return
+	}
+
+	s.lock.Lock()
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+	s.conn = conn
+	err = conn.WriteJSON(socketmode.Request{Type: socketmode.RequestTypeHello, NumConnections: 1})
+	s.lock.Unlock()
+	if err != nil {
+		s.drop(conn, err)
+		return
+	}
+
+	s.log.Debug().Msg("slacker connected to the relay")
+
+	done := make(chan struct{})
+	go s.ping(conn, done)
+
+	// the acknowledgements slacker sends back are not needed, slack was
+	// answered when the request was relayed. reading handles the pongs and
+	// notices when the connection closes.
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			close(done)
+			s.drop(conn, err)
+			return
+		}
+	}
+}
+
+// ping keeps the socket mode connection from timing out.
+func (s *Server) ping(conn *websocket.Conn, done chan struct{}) {
+	t := time.NewTicker(pingInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingInterval))
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// drop forgets the connection if it is still the current one.
+func (s *Server) drop(conn *websocket.Conn, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn == conn {
+		s.conn = nil
+	}
+	_ = conn.Close()
+
+	s.log.Debug().AnErr("reason", err).Msg("slacker disconnected from the relay")
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
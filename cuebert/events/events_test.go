@@ -0,0 +1,153 @@
+package events
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/pkg/logger"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+)
+
+const secret = "signing-secret"
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	s, err := New(&Config{SigningSecret: secret, Log: logger.NewLogger(&logger.Config{Level: "error"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	relay := &http.Server{Handler: http.HandlerFunc(s.accept)}
+	go func() { _ = relay.Serve(s.socket) }()
+	t.Cleanup(func() { _ = relay.Close() })
+
+	return s
+}
+
+func signed(path, body string, at time.Time) *http.Request {
+	ts := strconv.FormatInt(at.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req
+}
+
+func TestVerify(t *testing.T) {
+	h := newTestServer(t).Handler()
+
+	body := `{"type":"url_verification","challenge":"abc"}`
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		code int
+	}{
+		{"signed", signed(EventsPath, body, time.Now()), http.StatusOK},
+		{"expired", signed(EventsPath, body, time.Now().Add(-6*time.Minute)), http.StatusUnauthorized},
+		{"future", signed(EventsPath, body, time.Now().Add(6*time.Minute)), http.StatusUnauthorized},
+		{"unsigned", httptest.NewRequest(http.MethodPost, EventsPath, strings.NewReader(body)), http.StatusUnauthorized},
+	}
+
+	tampered := signed(EventsPath, body, time.Now())
+	tampered.Body = httptest.NewRequest(http.MethodPost, EventsPath, strings.NewReader(`{"type":"url_verification","challenge":"xyz"}`)).Body
+	tests = append(tests, struct {
+		name string
+		req  *http.Request
+		code int
+	}{"tampered", tampered, http.StatusUnauthorized})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, tt.req)
+
+			if rr.Code != tt.code {
+				t.Errorf("got status code: %d, want: %d", rr.Code, tt.code)
+			}
+			if tt.code == http.StatusOK && rr.Body.String() != "abc" {
+				t.Errorf("got challenge: %q, want: %q", rr.Body, "abc")
+			}
+		})
+	}
+}
+
+func TestRelay(t *testing.T) {
+	s := newTestServer(t)
+	h := s.Handler()
+
+	payload := `{"type":"block_actions","user":{"id":"U1"},"callback_id":"ack"}`
+	form := url.Values{"payload": {payload}}.Encode()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, signed(InteractivityPath, form, time.Now()))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status code before connecting: %d, want: %d", rr.Code, http.StatusServiceUnavailable)
+	}
+
+	// connect the way slacker does, asking slack for the url.
+	api := slack.New("xoxb-test",
+		slack.OptionAppLevelToken("xapp-test"),
+		slack.OptionHTTPClient(&http.Client{Transport: s.Transport(http.DefaultTransport)}),
+	)
+	client := socketmode.New(api)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = client.RunContext(ctx) }()
+
+	wait := func(typ socketmode.EventType) socketmode.Event {
+		t.Helper()
+		for {
+			select {
+			case evt := <-client.Events:
+				if evt.Type == typ {
+					return evt
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for %s", typ)
+			}
+		}
+	}
+	wait(socketmode.EventTypeHello)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, signed(InteractivityPath, form, time.Now()))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status code: %d, want: %d", rr.Code, http.StatusOK)
+	}
+
+	evt := wait(socketmode.EventTypeInteractive)
+	callback, ok := evt.Data.(slack.InteractionCallback)
+	if !ok || callback.CallbackID != "ack" || callback.User.ID != "U1" {
+		t.Errorf("got interaction: %+v", evt.Data)
+	}
+
+	cmd := url.Values{"command": {"/cuebert"}, "text": {"help"}, "user_id": {"U1"}}.Encode()
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, signed(CommandsPath, cmd, time.Now()))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status code: %d, want: %d", rr.Code, http.StatusOK)
+	}
+
+	evt = wait(socketmode.EventTypeSlashCommand)
+	if sc, ok := evt.Data.(slack.SlashCommand); !ok || sc.Command != "/cuebert" || sc.Text != "help" {
+		t.Errorf("got slash command: %+v", evt.Data)
+	}
+}
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/metrics"
+	"github.com/johnmikee/cuebert/cuebert/tracing"
 )
 
 func (c *Cuebert) run() {
 	c.log.Info().Msg("starting run")
-	c.isRunning = true
 	stop := make(chan struct{})
 
 	c.logFlags()
@@ -18,46 +21,62 @@ func (c *Cuebert) run() {
 
 	c.statusHandler.SetStatus(status)
 
-	var check []string
 	if c.flags.clearTables {
 		err := c.tables.DeleteTables(c.flags.tableNames)
 		if err != nil {
 			c.log.Err(err).Msg("could not delete all tables")
 		}
+	}
 
-		check, err = c.tables.InitTables(c.flags.requiredVers)
-		if err != nil {
-			c.log.Err(err).Msg("could not initialize tables")
-			c.stop()
-			status := c.statusHandler.GetStatus()
-			status.Message = fmt.Sprintf("stopping %s. could not build tables", c.flags.serviceName)
-			status.Code = 400
+	// the mdm and slack snapshots are reconciled into the existing rows so
+	// acknowledgements, manager notifications and exclusions are kept.
+	check, err := c.tables.InitTables(c.policy)
+	if err != nil {
+		c.log.Err(err).Msg("could not initialize tables")
+		c.Stop()
+		status := c.statusHandler.GetStatus()
+		status.Message = fmt.Sprintf("stopping %s. could not build tables", c.flags.serviceName)
+		status.Code = 400
+
+		c.statusHandler.SetStatus(status)
+	} else {
+		// the tables are built from a pull of the mdm devices.
+		c.mdmPulled(time.Now())
+	}
 
-			c.statusHandler.SetStatus(status)
-		}
+	go func() {
+		ctx, span := tracing.Start(context.Background(), "tableAssociations")
+		defer span.End()
 
-		go c.method.TableAssociations(check)
-	}
+		c.method.TableAssociations(ctx, check)
+	}()
+
+	c.openDefaultCampaign()
+	c.buildCampaignTables()
 
 	// check if we are past the deadline
 	go c.doEvery(
 		time.Duration(5)*time.Minute,
+		"checkDeadline",
 		c.checkDeadline,
 	)
 	// check periodically for changes on the devices.
 	go c.doEvery(
 		time.Duration(c.flags.deviceDiffInterval)*time.Minute,
-		c.deviceDiff,
+		"deviceDiff",
+		metrics.Timed(metrics.Diff, c.deviceDiff),
 	)
-	// here we check who needs the first reminder as well as the second message to the manager.
+	// here we check who needs the first reminder for each campaign as well as the second message to the manager.
 	go c.doEvery(
 		time.Duration(c.flags.checkInterval)*time.Minute,
-		c.method.Check,
+		"check",
+		metrics.Timed(metrics.Check, c.checkCampaigns),
 	)
 	// check if anyone who elected for a reminder needs a reminder
 	go c.doEvery(
 		time.Duration(c.flags.pollInterval)*time.Minute,
-		c.method.Poll,
+		"poll",
+		metrics.Timed(metrics.Poll, c.pollCampaigns),
 	)
 
 	// Wait for stop signal
@@ -69,6 +88,7 @@ func (c *Cuebert) run() {
 func (c *Cuebert) reloadFlags() {
 	c.log.Info().Msg("reloading flags")
 	c.logFlags()
+	c.reloadCatalog()
 }
 
 func (c *Cuebert) start() {
@@ -79,7 +99,6 @@ func (c *Cuebert) start() {
 func (c *Cuebert) stop() {
 	c.log.Trace().Msg("Stopping bot...")
 	c.stopSignal <- struct{}{}
-	c.isRunning = false
 }
 
 func (c *Cuebert) update() {
@@ -87,19 +106,22 @@ func (c *Cuebert) update() {
 	c.reloadSignal <- struct{}{}
 }
 
-// doEvery runs a function every on a duration until stop signal is received
-func (c *Cuebert) doEvery(d time.Duration, f func(time.Time)) {
+// doEvery runs a function every on a duration until stop signal is received.
+// each run is the root span of the calls it makes.
+func (c *Cuebert) doEvery(d time.Duration, name string, f func(context.Context, time.Time)) {
 	ticker := time.NewTicker(d)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case tm := <-ticker.C:
-			if !c.isRunning {
+			if !c.running.Load() {
 				return
 			}
 
-			f(tm)
+			ctx, span := tracing.Start(context.Background(), name)
+			f(ctx, tm)
+			span.End()
 
 		case <-c.stopSignal:
 			return
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // StatusMessage is used by the program to send status messages
@@ -20,6 +22,7 @@ type StatusMessage struct {
 	Diff        *RoutineStatus `json:"diff"`
 	Respond     *BotStatus     `json:"respond"`
 	DailyReport *BotStatus     `json:"daily_repost"`
+	Catalog     *CatalogStatus `json:"catalog"`
 }
 
 // BotStatus is used to send the status of various parts of the bot
@@ -35,6 +38,16 @@ type DBStatus struct {
 	Connected bool `json:"connected"`
 }
 
+// CatalogStatus is used to send the OS release catalog in use and the
+// versions it resolved the required version to for each major version
+type CatalogStatus struct {
+	Source   string            `json:"source"`
+	Loaded   string            `json:"loaded"`
+	Required string            `json:"required"`
+	Versions map[string]string `json:"versions"`
+	Error    string            `json:"error,omitempty"`
+}
+
 // RoutineStatus is used to send the status of the main program routines
 type RoutineStatus struct {
 	Name          string `json:"name"`
@@ -56,6 +69,8 @@ type RoutineUpdate struct {
 type StatusHandler struct {
 	status     StatusMessage
 	statusLock sync.RWMutex
+	checks     map[string]Check
+	checkLock  sync.RWMutex
 }
 
 // SetStatus is used to set the status of the program retrieved by the health check endpoint
@@ -73,34 +88,41 @@ func (sh *StatusHandler) GetStatus() StatusMessage {
 	return sh.status
 }
 
-// StartHealthHandler is used to start the health check endpoint
-func (sh *StatusHandler) StartHealthHandler() {
+// StartHealthHandler is used to start the health check, liveness and
+// readiness endpoints and the prometheus metrics on /metrics
+func (sh *StatusHandler) StartHealthHandler(addr string) {
 	server := &http.Server{
-		Addr:              ":8888",
+		Addr:              addr,
 		ReadHeaderTimeout: 3 * time.Second,
 	}
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		// Read the status from the handler
-		status := sh.GetStatus()
-
-		// Convert the status message to JSON
-		jsonData, err := json.Marshal(status)
-		if err != nil {
-			log.Println("Error:", err)
-			return
-		}
+	http.HandleFunc("/health", sh.health)
+	http.HandleFunc("/livez", sh.livez)
+	http.HandleFunc("/readyz", sh.readyz)
+	http.Handle("/metrics", promhttp.Handler())
+	log.Fatal(server.ListenAndServe())
+}
 
-		// Set the appropriate content type
-		w.Header().Set("Content-Type", "application/json")
+// health writes the last status set by the program.
+func (sh *StatusHandler) health(w http.ResponseWriter, r *http.Request) {
+	// Read the status from the handler
+	status := sh.GetStatus()
 
-		// Write the JSON data to the response writer
-		_, err = io.WriteString(w, string(jsonData))
-		if err != nil {
-			log.Println("Error:", err)
-			return
-		}
-	})
-	log.Fatal(server.ListenAndServe())
+	// Convert the status message to JSON
+	jsonData, err := json.Marshal(status)
+	if err != nil {
+		log.Println("Error:", err)
+		return
+	}
+
+	// Set the appropriate content type
+	w.Header().Set("Content-Type", "application/json")
+
+	// Write the JSON data to the response writer
+	_, err = io.WriteString(w, string(jsonData))
+	if err != nil {
+		log.Println("Error:", err)
+		return
+	}
 }
 
 func (sh *StatusHandler) readStatusMessages() StatusMessage {
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// checkTimeout is how long each readiness check has to answer.
+const checkTimeout = 5 * time.Second
+
+// Check reports if a dependency is ready. The error says why it is not.
+type Check func(ctx context.Context) error
+
+// Readiness is the response of the readiness endpoint.
+type Readiness struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+// AddCheck adds a dependency checked by the readiness endpoint. A check added
+// under the same name replaces the previous one.
+func (sh *StatusHandler) AddCheck(name string, c Check) {
+	sh.checkLock.Lock()
+	defer sh.checkLock.Unlock()
+
+	if sh.checks == nil {
+		sh.checks = make(map[string]Check)
+	}
+	sh.checks[name] = c
+}
+
+// Ready runs every check at once and reports which are ready.
+func (sh *StatusHandler) Ready(ctx context.Context) Readiness {
+	sh.checkLock.RLock()
+	checks := make(map[string]Check, len(sh.checks))
+	for name, c := range sh.checks {
+		checks[name] = c
+	}
+	sh.checkLock.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		res  = Readiness{Ready: true, Checks: make(map[string]string, len(checks))}
+	)
+
+	for name, c := range checks {
+		wg.Add(1)
+		go func(name string, c Check) {
+			defer wg.Done()
+
+			msg := "ok"
+			err := c(ctx)
+			if err != nil {
+				msg = err.Error()
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			res.Checks[name] = msg
+			if err != nil {
+				res.Ready = false
+			}
+		}(name, c)
+	}
+	wg.Wait()
+
+	return res
+}
+
+// livez answers as long as the program is serving requests.
+func (sh *StatusHandler) livez(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	_, err := w.Write([]byte("ok"))
+	if err != nil {
+		log.Println("Error:", err)
+	}
+}
+
+// readyz answers 200 when every check is ready and 503 when any is not.
+func (sh *StatusHandler) readyz(w http.ResponseWriter, r *http.Request) {
+	res := sh.Ready(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	if !res.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	err := json.NewEncoder(w).Encode(res)
+	if err != nil {
+		log.Println("Error:", err)
+	}
+}
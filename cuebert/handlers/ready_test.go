@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLivez(t *testing.T) {
+	sh := &StatusHandler{}
+	sh.AddCheck("db", func(context.Context) error { return errors.New("down") })
+
+	rr := httptest.NewRecorder()
+	sh.livez(rr, httptest.NewRequest("GET", "/livez", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("livez() got status code: %d, want: %d", rr.Code, http.StatusOK)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks map[string]Check
+		code   int
+		want   map[string]string
+	}{
+		{
+			name:   "no checks",
+			checks: nil,
+			code:   http.StatusOK,
+			want:   map[string]string{},
+		},
+		{
+			name: "ready",
+			checks: map[string]Check{
+				"db":    func(context.Context) error { return nil },
+				"slack": func(context.Context) error { return nil },
+			},
+			code: http.StatusOK,
+			want: map[string]string{"db": "ok", "slack": "ok"},
+		},
+		{
+			name: "stale",
+			checks: map[string]Check{
+				"db":  func(context.Context) error { return nil },
+				"mdm": func(context.Context) error { return errors.New("last successful pull was 2h0m0s ago") },
+			},
+			code: http.StatusServiceUnavailable,
+			want: map[string]string{"db": "ok", "mdm": "last successful pull was 2h0m0s ago"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh := &StatusHandler{}
+			for name, c := range tt.checks {
+				sh.AddCheck(name, c)
+			}
+
+			rr := httptest.NewRecorder()
+			sh.readyz(rr, httptest.NewRequest("GET", "/readyz", nil))
+
+			if rr.Code != tt.code {
+				t.Errorf("readyz() got status code: %d, want: %d", rr.Code, tt.code)
+			}
+
+			var got Readiness
+			err := json.Unmarshal(rr.Body.Bytes(), &got)
+			if err != nil {
+				t.Fatalf("readyz() failed to parse response body: %v", err)
+			}
+
+			if got.Ready != (tt.code == http.StatusOK) {
+				t.Errorf("readyz() got ready: %v", got.Ready)
+			}
+			if len(got.Checks) != len(tt.want) {
+				t.Errorf("readyz() got checks: %v, want: %v", got.Checks, tt.want)
+			}
+			for name, msg := range tt.want {
+				if got.Checks[name] != msg {
+					t.Errorf("readyz() got %s: %q, want: %q", name, got.Checks[name], msg)
+				}
+			}
+		})
+	}
+}
@@ -1,13 +1,32 @@
 package main
 
-func (c *Cuebert) Start() {
+// Start starts the routines. The check and the transition are one step so
+// only one of the callers racing to start them does.
+func (c *Cuebert) Start() bool {
+	if !c.running.CompareAndSwap(false, true) {
+		return false
+	}
+
 	c.start()
+
+	return true
 }
 
-func (c *Cuebert) Stop() {
+// Stop stops the routines, and like Start only once.
+func (c *Cuebert) Stop() bool {
+	if !c.running.CompareAndSwap(true, false) {
+		return false
+	}
+
 	c.stop()
+
+	return true
 }
 
 func (c *Cuebert) Update() {
 	c.update()
 }
+
+func (c *Cuebert) Running() bool {
+	return c.running.Load()
+}
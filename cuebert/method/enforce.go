@@ -0,0 +1,234 @@
+package method
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/policy"
+	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/cuebert/tracing"
+	dbot "github.com/johnmikee/cuebert/db/bot"
+	"github.com/johnmikee/cuebert/db/campaigns"
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Enforcement holds what is needed to act on the devices left once the
+// deadline passes.
+type Enforcement struct {
+	Log          logger.Logger
+	Tables       *tables.Config
+	MDM          mdm.Provider
+	Action       mdm.Action
+	Opts         mdm.EnforceOpts
+	Policy       *policy.Policy
+	Cutoff       string
+	Testing      bool
+	TestingUsers []string
+}
+
+// Enforce sends the deadline action to the MDM for every serial still in
+// bot_results that does not have an active exclusion and whose policy rule
+// is due. While testing only the testing users' devices are acted on, the
+// rest are logged. The actions are sent as children of the span in ctx.
+//
+// Each action sent is recorded in the playbook_steps table for the campaign
+// and deadline so a restart does not send it again.
+func Enforce(ctx context.Context, e *Enforcement) {
+	if e.Action == "" {
+		e.Log.Info().Msg("no deadline action set. skipping enforcement")
+		return
+	}
+
+	enforcer, ok := tracing.Enforcer(ctx, e.MDM)
+	if !ok {
+		e.Log.Warn().
+			Str("action", string(e.Action)).
+			Msg("the mdm does not support enforcement actions")
+		return
+	}
+
+	br, err := e.Tables.WithContext(ctx).GetBotTableInfo()
+	if err != nil {
+		e.Log.Err(err).Msg("could not get devices to enforce")
+		return
+	}
+
+	enforced, err := enforcedSteps(e.Tables, br)
+	if err != nil {
+		e.Log.Err(err).Msg("could not get the devices already enforced")
+		return
+	}
+
+	now := time.Now()
+	for i := range br {
+		serial := br[i].SerialNumber
+
+		excluded, err := e.Tables.ExclusionActive(serial, now)
+		if err != nil {
+			e.Log.Err(err).
+				Str("serial", serial).
+				Msg("could not check for an exclusion. skipping enforcement")
+			continue
+		}
+		if excluded {
+			e.Log.Debug().
+				Str("serial", serial).
+				Msg("device has an active exclusion. skipping enforcement")
+			continue
+		}
+
+		dev, err := device(e.Tables, serial)
+		if err != nil {
+			e.Log.Err(err).
+				Str("serial", serial).
+				Msg("could not get device to enforce")
+			continue
+		}
+
+		opts := e.Opts
+		var due time.Time
+		if e.Policy != nil {
+			rule := e.Policy.Match(dev.Model, dev.OSVersion)
+			due, err = rule.Due(e.Cutoff)
+			if err != nil {
+				e.Log.Err(err).
+					Str("serial", serial).
+					Str("policy", rule.String()).
+					Msg("could not parse the policy deadline. skipping enforcement")
+				continue
+			}
+			if now.Before(due) {
+				e.Log.Debug().
+					Str("serial", serial).
+					Str("policy", rule.String()).
+					Msg("policy deadline has not passed. skipping enforcement")
+				continue
+			}
+			opts.Version = rule.Minimum
+		}
+
+		step := enforcedStep(e.Tables.CampaignID(), due)
+		if enforced[serial][step] {
+			e.Log.Debug().
+				Str("serial", serial).
+				Msg("deadline already enforced. skipping enforcement")
+			continue
+		}
+
+		if e.Testing && !helpers.Contains(e.TestingUsers, br[i].SlackID) {
+			e.Log.Info().
+				Str("serial", serial).
+				Str("user", br[i].UserEmail).
+				Str("action", string(e.Action)).
+				Bool("testing", e.Testing).
+				Msg("dry run. would have enforced the deadline")
+			continue
+		}
+
+		err = enforcer.Enforce(e.Action, dev, &opts)
+		if errors.Is(err, mdm.ErrActionNotSupported) {
+			e.Log.Warn().
+				Str("action", string(e.Action)).
+				Msg("the mdm does not support this action")
+			return
+		}
+		if err != nil {
+			e.Log.Err(err).
+				Str("serial", serial).
+				Str("action", string(e.Action)).
+				Msg("could not enforce the deadline")
+			continue
+		}
+
+		if err := e.Tables.CompleteStep(serial, step, now); err != nil {
+			e.Log.Err(err).
+				Str("serial", serial).
+				Msg("could not record the enforcement")
+		}
+
+		e.Log.Info().
+			Str("serial", serial).
+			Str("user", br[i].UserEmail).
+			Str("action", string(e.Action)).
+			Msg("enforced the deadline")
+	}
+}
+
+// enforcedStep names the record of the deadline action for the campaign and
+// deadline. A device is enforced again when its deadline moves.
+func enforcedStep(campaign string, due time.Time) string {
+	if campaign == "" {
+		campaign = campaigns.Default
+	}
+
+	return "deadline:" + campaign + ":" + due.UTC().Format(time.RFC3339)
+}
+
+// enforcedSteps returns the steps recorded for the serials in bot_results.
+func enforcedSteps(t *tables.Config, br dbot.BR) (map[string]map[string]bool, error) {
+	done := map[string]map[string]bool{}
+	if len(br) == 0 {
+		return done, nil
+	}
+
+	serials := make([]string, 0, len(br))
+	for i := range br {
+		serials = append(serials, br[i].SerialNumber)
+	}
+
+	steps, err := t.CompletedSteps(serials...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range steps {
+		if done[steps[i].SerialNumber] == nil {
+			done[steps[i].SerialNumber] = map[string]bool{}
+		}
+		done[steps[i].SerialNumber][steps[i].Step] = true
+	}
+
+	return done, nil
+}
+
+// EnforceDevice looks up the device for the serial and sends the action to the
+// MDM. When a policy is passed the version is taken from the rule the device
+// matches.
+func EnforceDevice(enforcer mdm.Enforcer, t *tables.Config, p *policy.Policy, serial string, action mdm.Action, opts *mdm.EnforceOpts) error {
+	dev, err := device(t, serial)
+	if err != nil {
+		return err
+	}
+
+	if p != nil {
+		o := *opts
+		o.Version = p.Match(dev.Model, dev.OSVersion).Minimum
+		opts = &o
+	}
+
+	return enforcer.Enforce(action, dev, opts)
+}
+
+// device returns the device for the serial from the devices table.
+func device(t *tables.Config, serial string) (*mdm.Device, error) {
+	dev, err := t.DeviceBySerial(serial)
+	if err != nil {
+		return nil, err
+	}
+
+	if dev.Empty() {
+		return nil, fmt.Errorf("no device found with serial %s", serial)
+	}
+
+	return &mdm.Device{
+		DeviceID:     dev[0].DeviceID,
+		SerialNumber: serial,
+		Model:        dev[0].Model,
+		OSVersion:    dev[0].OSVersion,
+		Platform:     dev[0].Platform,
+	}, nil
+}
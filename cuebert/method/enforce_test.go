@@ -0,0 +1,109 @@
+package method
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/johnmikee/cuebert/cuebert/policy"
+	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/db/devices"
+	"github.com/johnmikee/cuebert/db/sqlite"
+	"github.com/johnmikee/cuebert/db/users"
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// stubEnforcer records the serials it is asked to enforce.
+type stubEnforcer struct {
+	mdm.Provider
+	enforced []string
+}
+
+func (s *stubEnforcer) Enforce(_ mdm.Action, d *mdm.Device, _ *mdm.EnforceOpts) error {
+	s.enforced = append(s.enforced, d.SerialNumber)
+	return nil
+}
+
+// newEnforcement returns an enforcement for a user on an old os whose
+// deadline has passed, with its tables stored in a new sqlite database.
+func newEnforcement(t *testing.T) (*Enforcement, *stubEnforcer) {
+	t.Helper()
+
+	store, err := sqlite.Open(filepath.Join(t.TempDir(), "cue.db"))
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	t.Cleanup(store.Close)
+
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+
+	_, err = users.User(store, &log).AddAllUsers(users.UI{
+		{MDMID: "1", UserLongName: "Old Mac", UserEmail: "old@example.com", UserSlackID: "U1"},
+	})
+	if err != nil {
+		t.Fatalf("adding users: %v", err)
+	}
+
+	_, err = devices.Device(store, &log).AddAllDevices(devices.DI{
+		{DeviceID: "d1", DeviceName: "old", Model: "Mac14,2", SerialNumber: "S1", Platform: "Mac", OSVersion: "13.3", User: "old@example.com", UserMDMID: "1"},
+	})
+	if err != nil {
+		t.Fatalf("adding devices: %v", err)
+	}
+
+	p := policy.New("13.4.1", "06-30-2023")
+	tbl := tables.New(store, &log).ForCampaign("default")
+	tbl.BuildBotResTable(p)
+
+	en := &stubEnforcer{}
+
+	return &Enforcement{
+		Log:    log,
+		Tables: tbl,
+		MDM:    en,
+		Action: "force_install",
+		Policy: p,
+		Cutoff: "17:00",
+	}, en
+}
+
+func TestEnforceOnce(t *testing.T) {
+	e, m := newEnforcement(t)
+
+	Enforce(context.Background(), e)
+	if len(m.enforced) != 1 || m.enforced[0] != "S1" {
+		t.Fatalf("got enforced %v, want [S1]", m.enforced)
+	}
+
+	// a restart runs the deadline action again.
+	Enforce(context.Background(), e)
+	if len(m.enforced) != 1 {
+		t.Errorf("got enforced %v, want the device enforced once", m.enforced)
+	}
+
+	// a new deadline is enforced again.
+	e.Policy.Default.Deadline = "07-30-2023"
+	Enforce(context.Background(), e)
+	if len(m.enforced) != 2 {
+		t.Errorf("got enforced %v, want the device enforced for the new deadline", m.enforced)
+	}
+}
+
+func TestEnforceMalformedDeadline(t *testing.T) {
+	e, m := newEnforcement(t)
+	e.Policy.Default.Deadline = "soon"
+
+	Enforce(context.Background(), e)
+	if len(m.enforced) != 0 {
+		t.Errorf("got enforced %v, want no device enforced on a bad deadline", m.enforced)
+	}
+
+	e.Policy.Default.Deadline = "06-30-2023"
+	e.Cutoff = "5pm"
+
+	Enforce(context.Background(), e)
+	if len(m.enforced) != 0 {
+		t.Errorf("got enforced %v, want no device enforced on a bad cutoff", m.enforced)
+	}
+}
@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/slack-go/slack"
@@ -12,8 +13,8 @@ type ManagerAlert struct {
 }
 
 // sendAlert sends an alert to the specified channel after building the attachment
-func (m *Manager) sendAlert(alertChan string, attachment *slack.Attachment) {
-	_, _, err := m.sc.PostMessage(alertChan, slack.MsgOptionAttachments(*attachment))
+func (m *Manager) sendAlert(ctx context.Context, alertChan string, attachment *slack.Attachment) {
+	_, _, err := m.sc.PostMessageContext(ctx, alertChan, slack.MsgOptionAttachments(*attachment))
 	if err != nil {
 		m.log.Err(err).Msg("sending alert message")
 	}
@@ -41,9 +42,9 @@ func buildAlert(source string, mm []MissingManager) slack.Attachment {
 // missing managers in both the DB and iDP. this is no longer the case, but
 // the logic is still here in case it is needed in the future to range
 // over potential sources of truth for managers values.
-func (m *Manager) alertIfNoManager(alertChan string, ma []ManagerAlert) {
+func (m *Manager) alertIfNoManager(ctx context.Context, alertChan string, ma []ManagerAlert) {
 	for _, a := range ma {
 		alert := buildAlert(a.msg, a.info)
-		m.sendAlert(alertChan, &alert)
+		m.sendAlert(ctx, alertChan, &alert)
 	}
 }
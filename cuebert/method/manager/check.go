@@ -1,10 +1,12 @@
 package manager
 
 import (
+	"context"
 	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/bot"
 	"github.com/johnmikee/cuebert/cuebert/handlers"
+	"github.com/johnmikee/cuebert/cuebert/metrics"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 )
 
@@ -12,9 +14,13 @@ import (
 // table to see if and when users need to be reminded to update their devices.
 // this handles the first message, acknowledgements, and second message with
 // the user and their manager.
-func (m *Manager) Check(time.Time) {
-	devices, err := m.tables.GetBotTableInfo()
+func (m *Manager) Check(ctx context.Context, _ time.Time) {
+	tables := m.tables.WithContext(ctx)
+
+	devices, err := tables.GetBotTableInfo()
 	if err != nil {
+		m.log.Err(err).Msg("could not get info from the bot results table")
+		metrics.RoutineFailed(metrics.Check)
 		return
 	}
 
@@ -28,7 +34,7 @@ func (m *Manager) Check(time.Time) {
 
 				continue
 			}
-			m.bot.SendReminder(1, &devices[i])
+			m.bot.SendReminder(ctx, 1, &devices[i])
 			continue
 		}
 
@@ -51,7 +57,7 @@ func (m *Manager) Check(time.Time) {
 					continue
 				}
 			}
-			m.bot.SendReminder(2, &devices[i])
+			m.bot.SendReminder(ctx, 2, &devices[i])
 		}
 
 		// the use has received the first message so
@@ -63,7 +69,7 @@ func (m *Manager) Check(time.Time) {
 			continue
 		}
 
-		fa, err := m.tables.GetACKTime(devices[i].SerialNumber)
+		fa, err := tables.GetACKTime(devices[i].SerialNumber)
 		if err != nil {
 			m.log.Err(err).Msg("could not get ack time")
 			continue
@@ -98,7 +104,7 @@ func (m *Manager) Check(time.Time) {
 				continue
 			}
 			// first check if the manager has been notified
-			s, err := m.tables.GetManagerNotified(devices[i].SerialNumber)
+			s, err := tables.GetManagerNotified(devices[i].SerialNumber)
 			if err != nil {
 				m.log.Err(err).Msg("could not get manager notified")
 				continue
@@ -115,7 +121,7 @@ func (m *Manager) Check(time.Time) {
 				return
 			}
 
-			dev, err := m.tables.DeviceBySerial(devices[i].SerialNumber)
+			dev, err := tables.DeviceBySerial(devices[i].SerialNumber)
 			if err != nil {
 				// if we cant get the device info then we cant send the message
 				m.log.Info().
@@ -131,6 +137,7 @@ func (m *Manager) Check(time.Time) {
 			fm := devices[i].FirstMessageSentAt.In(helpers.GenLocation(devices[i].TZOffset))
 
 			m.managerMessage(
+				ctx,
 				&bot.ReminderPayload{
 					UserSlackID:    devices[i].SlackID,
 					UserName:       devices[i].FullName,
@@ -1,6 +1,26 @@
 package manager
 
+import (
+	"context"
+
+	"github.com/johnmikee/cuebert/cuebert/method"
+	"github.com/johnmikee/cuebert/mdm"
+)
+
 // Deadline implements method.Actions.
-func (m *Manager) Deadline() {
-	m.log.Trace().Msg("implement actions for deadline that fit your needs")
+func (m *Manager) Deadline(ctx context.Context) {
+	method.Enforce(ctx, &method.Enforcement{
+		Log:    m.log,
+		Tables: m.tables,
+		MDM:    m.mdm,
+		Action: m.cfg.deadlineAction,
+		Opts: mdm.EnforceOpts{
+			Version: m.cfg.requiredVers,
+			Group:   m.cfg.deadlineGroup,
+		},
+		Policy:       m.cfg.policy,
+		Cutoff:       m.cfg.cutoffTime,
+		Testing:      m.cfg.testing,
+		TestingUsers: m.cfg.testingUsers,
+	})
 }
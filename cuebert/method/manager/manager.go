@@ -1,12 +1,14 @@
 package manager
 
 import (
+	"context"
 	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/bot"
 	"github.com/johnmikee/cuebert/cuebert/handlers"
 	"github.com/johnmikee/cuebert/cuebert/method"
 	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/cuebert/tracing"
 
 	"github.com/johnmikee/cuebert/idp"
 	"github.com/johnmikee/cuebert/mdm"
@@ -38,6 +40,9 @@ func (m *Manager) Setup(method method.Config) {
 	m.cfg = WithOptions(
 		WithCutoffTime(method.CutoffTime),
 		WithDeadline(method.Deadline),
+		WithDeadlineAction(method.DeadlineAction),
+		WithDeadlineGroup(method.DeadlineGroup),
+		WithPolicy(method.Policy),
 		WithRequiredVers(method.RequiredVers),
 		WithSlackAlertChannel(method.SlackAlertChannel),
 		WithTesting(method.Testing),
@@ -47,12 +52,12 @@ func (m *Manager) Setup(method method.Config) {
 }
 
 // PostInit implements method.Actions.
-func (m *Manager) PostCheck(sa []string) {
-	m.associateUserManager(sa)
+func (m *Manager) PostCheck(ctx context.Context, sa []string) {
+	m.associateUserManager(ctx, sa)
 }
 
 // Poll implements method.Actions.
-func (m *Manager) Poll(t time.Time) {
+func (m *Manager) Poll(context.Context, time.Time) {
 	m.log.Trace().Msg("not implemented")
 }
 
@@ -61,8 +66,8 @@ func (*Manager) FirstMessage() string {
 	return firstMessage(helpers.GetReminderDay())
 }
 
-func (m *Manager) TableAssociations(sa []string) {
-	m.associateUserManager(sa)
+func (m *Manager) TableAssociations(ctx context.Context, sa []string) {
+	m.associateUserManager(ctx, sa)
 }
 
 type MissingManager struct {
@@ -93,8 +98,8 @@ func New(c *Config) *Manager {
 		statusHandler: c.Handler,
 	}
 }
-func (m *Manager) buildAssociation(idpUsers []idp.User, check []string) ([]MissingManager, error) {
-	m.tables.AddCheckMissing(idpUsers, check, m.sc)
+func (m *Manager) buildAssociation(ctx context.Context, idpUsers []idp.User, check []string) ([]MissingManager, error) {
+	m.tables.WithContext(ctx).AddCheckMissing(ctx, idpUsers, check, m.sc)
 
 	mm, err := m.getMissingManagers()
 	if err != nil {
@@ -119,10 +124,10 @@ func (m *Manager) buildAssociation(idpUsers []idp.User, check []string) ([]Missi
 // machine ala windows.
 //
 // we take a second pass on these and attach it directly to the user.
-func (m *Manager) associateUserManager(check []string) {
+func (m *Manager) associateUserManager(ctx context.Context, check []string) {
 	m.log.Info().Msg("checking manager association..")
 
-	ur, err := m.idp.GetAllUsers()
+	ur, err := tracing.IDP(ctx, m.idp).GetAllUsers()
 	if err != nil {
 		m.log.Trace().
 			AnErr("error", err).
@@ -136,7 +141,7 @@ func (m *Manager) associateUserManager(check []string) {
 			Msg("checking user")
 	}
 
-	missing, err := m.buildAssociation(ur, check)
+	missing, err := m.buildAssociation(ctx, ur, check)
 	if err != nil {
 		m.log.Err(err).
 			Msg("associating managers to users")
@@ -147,7 +152,7 @@ func (m *Manager) associateUserManager(check []string) {
 	// if we have missing managers we need to alert
 
 	// that is, if we opted to do so.
-	m.alertIfNoManager(m.cfg.slackAlertChannel,
+	m.alertIfNoManager(ctx, m.cfg.slackAlertChannel,
 		[]ManagerAlert{
 			{
 				info: missing,
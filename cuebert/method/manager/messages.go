@@ -1,10 +1,13 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/bot"
+	"github.com/johnmikee/cuebert/cuebert/metrics"
+	"github.com/johnmikee/cuebert/db/audit"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 	"github.com/slack-go/slack"
 )
@@ -44,11 +47,11 @@ For more information and detailed guidance, refer to this <https://www.youtube.c
 }
 
 // currently not implemented
-func (m *Manager) ReminderMessage(rp *bot.ReminderPayload) error {
+func (m *Manager) ReminderMessage(ctx context.Context, rp *bot.ReminderPayload) error {
 	return nil
 }
 
-func (m *Manager) managerMessage(rp *bot.ReminderPayload) {
+func (m *Manager) managerMessage(ctx context.Context, rp *bot.ReminderPayload) {
 	attachment := slack.Attachment{
 		Text: managerMessaging(
 			rp.UserName,
@@ -61,7 +64,8 @@ func (m *Manager) managerMessage(rp *bot.ReminderPayload) {
 
 	message := slack.MsgOptionAttachments(attachment)
 
-	dm, _, _, err := m.sc.OpenConversation(
+	dm, _, _, err := m.sc.OpenConversationContext(
+		ctx,
 		&slack.OpenConversationParameters{
 			ChannelID: "",
 			ReturnIM:  false,
@@ -76,9 +80,13 @@ func (m *Manager) managerMessage(rp *bot.ReminderPayload) {
 		return
 	}
 
-	channelID, timestamp, err := m.sc.PostMessage(dm.ID, message)
+	channelID, timestamp, err := m.sc.PostMessageContext(ctx, dm.ID, message)
 	if err != nil {
 		m.log.Err(err).Msg("posting message in")
+	} else {
+		metrics.MessageSent(metrics.Manager)
+		m.bot.Audit(audit.System, audit.ManagerMessageSent, rp.UserSlackID, rp.Serial, nil,
+			map[string]any{"manager": rp.ManagerSlackID})
 	}
 
 	m.log.Debug().
@@ -88,7 +96,7 @@ func (m *Manager) managerMessage(rp *bot.ReminderPayload) {
 		Str("channel", channelID).
 		Msg("manager message sent")
 
-	err = m.tables.ManagerNotifed(true, rp.Serial)
+	err = m.tables.WithContext(ctx).ManagerNotifed(true, rp.Serial)
 	if err != nil {
 		m.log.Err(err).Send()
 	}
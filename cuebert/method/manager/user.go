@@ -145,7 +145,7 @@ func (m *Manager) UserUpdate(ctx *slacker.InteractionContext) {
 	}
 
 	m.log.Info().Interface("modal_request", modalRequest).Msg("opening modal")
-	vr, err := m.sc.OpenView(ctx.Callback().TriggerID, modalRequest)
+	vr, err := m.sc.OpenViewContext(m.bot.Context(ctx), ctx.Callback().TriggerID, modalRequest)
 	if err != nil {
 		m.log.Error().Err(err).Msg("error opening modal")
 	}
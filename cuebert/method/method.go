@@ -1,10 +1,12 @@
 package method
 
 import (
+	"context"
 	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/bot"
 	"github.com/johnmikee/cuebert/cuebert/handlers"
+	"github.com/johnmikee/cuebert/cuebert/policy"
 	"github.com/johnmikee/cuebert/cuebert/tables"
 	dbot "github.com/johnmikee/cuebert/db/bot"
 	"github.com/johnmikee/cuebert/idp"
@@ -29,22 +31,22 @@ type Actions interface {
 }
 
 type Routines interface {
-	Check(time.Time)
-	Poll(time.Time)
+	Check(context.Context, time.Time)
+	Poll(context.Context, time.Time)
 	DeviceDiff([]string)
 }
 
 type Tasks interface {
-	PostCheck([]string)
-	TableAssociations([]string)
+	PostCheck(context.Context, []string)
+	TableAssociations(context.Context, []string)
 	UserUpdate(ctx *slacker.InteractionContext)
 	UserUpdateModalSubmit(base *dbot.Info, values map[string]map[string]slack.BlockAction)
-	Deadline()
+	Deadline(context.Context)
 }
 
 type Messaging interface {
 	FirstMessage() string
-	ReminderMessage(rp *bot.ReminderPayload) error
+	ReminderMessage(ctx context.Context, rp *bot.ReminderPayload) error
 }
 
 type Setup interface {
@@ -62,7 +64,10 @@ type Config struct {
 	SlackAlertChannel string
 	CutoffTime        string
 	Deadline          string
+	DeadlineAction    mdm.Action
+	DeadlineGroup     string
 	RequiredVers      string
+	Policy            *policy.Policy
 	Testing           bool
 	TestingUsers      []string
 	PollInterval      int
@@ -1,24 +1,28 @@
 package timebound
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"time"
 
 	bi "github.com/johnmikee/cuebert/cuebert/bot"
 	"github.com/johnmikee/cuebert/cuebert/handlers"
+	"github.com/johnmikee/cuebert/cuebert/metrics"
 	"github.com/johnmikee/cuebert/db/bot"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 )
 
 // Check implements method.Actions.
-func (t *TimeBound) Check(time.Time) {
-	t.check()
+func (t *TimeBound) Check(ctx context.Context, _ time.Time) {
+	t.check(ctx)
 }
 
-func (t *TimeBound) check() {
-	devices, err := t.tables.GetBotTableInfo()
+func (t *TimeBound) check(ctx context.Context) {
+	devices, err := t.tables.WithContext(ctx).GetBotTableInfo()
 	if err != nil {
+		t.log.Err(err).Msg("could not get info from the bot results table")
+		metrics.RoutineFailed(metrics.Check)
 		return
 	}
 
@@ -32,7 +36,7 @@ func (t *TimeBound) check() {
 
 				continue
 			}
-			t.bot.SendReminder(1, &devices[i])
+			t.bot.SendReminder(ctx, 1, &devices[i])
 			continue
 		}
 
@@ -56,10 +60,10 @@ func (t *TimeBound) check() {
 					continue
 				}
 			}
-			t.bot.SendReminder(2, &devices[i])
+			t.bot.SendReminder(ctx, 2, &devices[i])
 		}
 
-		_, err := t.checkReminders(devices[i])
+		_, err := t.checkReminders(ctx, devices[i])
 		if err != nil {
 			t.log.Err(err).Msg("could not check reminders")
 			continue
@@ -81,10 +85,12 @@ func (t *TimeBound) check() {
 	)
 }
 
-func (t *TimeBound) checkReminders(device bot.Info) (bool, error) {
+func (t *TimeBound) checkReminders(ctx context.Context, device bot.Info) (bool, error) {
+	tables := t.tables.WithContext(ctx)
+
 	// the user has received the first message so
 	// check how long its been since the first ack
-	fa, err := t.tables.GetACKTime(device.SerialNumber)
+	fa, err := tables.GetACKTime(device.SerialNumber)
 	if err != nil {
 		t.log.Err(err).Msg("could not get ack time")
 		return false, nil
@@ -112,12 +118,12 @@ func (t *TimeBound) checkReminders(device bot.Info) (bool, error) {
 		}
 	} else {
 		// if the time difference is greater than the default reminder interval
-		i, err := t.tables.GetReminderInterval(device.SerialNumber)
+		i, err := tables.GetReminderInterval(device.SerialNumber)
 		if err != nil {
 			return false, fmt.Errorf("could not get reminder interval: %w", err)
 		}
 
-		dev, err := t.tables.DeviceBySerial(device.SerialNumber)
+		dev, err := tables.DeviceBySerial(device.SerialNumber)
 		if err != nil {
 			// if we cant get the device info then we cant send the message
 			t.log.Info().
@@ -130,7 +136,7 @@ func (t *TimeBound) checkReminders(device bot.Info) (bool, error) {
 		}
 
 		// check if the user has a reminder waiting
-		rw, err := t.tables.GetReminderWaiting(device.SerialNumber)
+		rw, err := tables.GetReminderWaiting(device.SerialNumber)
 		if err != nil {
 			return false, fmt.Errorf("could not get reminder waiting: %w", err)
 		}
@@ -143,15 +149,18 @@ func (t *TimeBound) checkReminders(device bot.Info) (bool, error) {
 
 		// we are close enough - spin off a routine to send the reminder
 		if distance < 15 {
+			rule := t.cfg.policy.Match(dev[0].Model, dev[0].OSVersion)
 			go t.bot.ScheduleReminder(
+				ctx,
 				time.Duration(distance*float64(time.Minute)),
 				&bi.ReminderInfo{
-					Deadline: t.cfg.deadline,
+					Deadline: rule.Deadline,
 					User:     device.SlackID,
 					Serial:   device.SerialNumber,
-					Version:  t.cfg.requiredVers,
+					Version:  rule.Minimum,
 					OS:       dev[0].OSVersion,
-					Text:     t.reminderMessage(t.cfg.deadline),
+					Text:     t.reminderMessage(rule.Deadline),
+					Policy:   rule.String(),
 				},
 			)
 			return true, nil
@@ -159,6 +168,7 @@ func (t *TimeBound) checkReminders(device bot.Info) (bool, error) {
 
 		if diff.Minutes() >= float64(i) {
 			t.ReminderMessage(
+				ctx,
 				&bi.ReminderPayload{
 					UserSlackID: device.SlackID,
 					UserName:    device.FullName,
@@ -3,6 +3,7 @@ package timebound
 import (
 	"github.com/johnmikee/cuebert/cuebert/bot"
 	"github.com/johnmikee/cuebert/cuebert/handlers"
+	"github.com/johnmikee/cuebert/cuebert/policy"
 	"github.com/johnmikee/cuebert/cuebert/tables"
 	"github.com/johnmikee/cuebert/idp"
 	"github.com/johnmikee/cuebert/mdm"
@@ -20,14 +21,17 @@ type Config struct {
 }
 
 type Cfg struct {
-	slackAlertChannel       string   // the channel to send alerts to
-	cutoffTime              string   // cutoffTime will be the time access is revoked
-	deadline                string   // the day the update is required
-	requiredVers            string   // ex: 13.1
-	testing                 bool     // run in testing mode
-	testingUsers            []string // array of users to test with
-	pollInterval            int      // how often to poll the idp for users
-	defaultReminderInterval int      // how often to remind users to update unless they opt for a different interval
+	slackAlertChannel       string         // the channel to send alerts to
+	cutoffTime              string         // cutoffTime will be the time access is revoked
+	deadline                string         // the day the update is required
+	deadlineAction          mdm.Action     // what to do through the mdm at the deadline
+	deadlineGroup           string         // blueprint, group or team used to remediate
+	requiredVers            string         // ex: 13.1
+	policy                  *policy.Policy // minimum versions and deadlines per model or major version
+	testing                 bool           // run in testing mode
+	testingUsers            []string       // array of users to test with
+	pollInterval            int            // how often to poll the idp for users
+	defaultReminderInterval int            // how often to remind users to update unless they opt for a different interval
 }
 
 type Option func(*Cfg)
@@ -58,6 +62,24 @@ func WithDeadline(time string) Option {
 	}
 }
 
+func WithDeadlineAction(action mdm.Action) Option {
+	return func(cfg *Cfg) {
+		cfg.deadlineAction = action
+	}
+}
+
+func WithDeadlineGroup(group string) Option {
+	return func(cfg *Cfg) {
+		cfg.deadlineGroup = group
+	}
+}
+
+func WithPolicy(p *policy.Policy) Option {
+	return func(cfg *Cfg) {
+		cfg.policy = p
+	}
+}
+
 func WithRequiredVers(vers string) Option {
 	return func(cfg *Cfg) {
 		cfg.requiredVers = vers
@@ -1,6 +1,26 @@
 package timebound
 
+import (
+	"context"
+
+	"github.com/johnmikee/cuebert/cuebert/method"
+	"github.com/johnmikee/cuebert/mdm"
+)
+
 // Deadline implements method.Actions.
-func (t *TimeBound) Deadline() {
-	t.log.Trace().Msg("implement actions for deadline that fit your needs")
+func (t *TimeBound) Deadline(ctx context.Context) {
+	method.Enforce(ctx, &method.Enforcement{
+		Log:    t.log,
+		Tables: t.tables,
+		MDM:    t.mdm,
+		Action: t.cfg.deadlineAction,
+		Opts: mdm.EnforceOpts{
+			Version: t.cfg.requiredVers,
+			Group:   t.cfg.deadlineGroup,
+		},
+		Policy:       t.cfg.policy,
+		Cutoff:       t.cfg.cutoffTime,
+		Testing:      t.cfg.testing,
+		TestingUsers: t.cfg.testingUsers,
+	})
 }
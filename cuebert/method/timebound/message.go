@@ -1,18 +1,21 @@
 package timebound
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/bot"
+	"github.com/johnmikee/cuebert/cuebert/metrics"
+	"github.com/johnmikee/cuebert/db/audit"
 	br "github.com/johnmikee/cuebert/db/bot"
 	"github.com/slack-go/slack"
 )
 
 // PostInit implements method.Actions.
-func (t *TimeBound) PostCheck(sa []string) {
+func (t *TimeBound) PostCheck(_ context.Context, sa []string) {
 	t.log.Trace().Strs("", sa).Msg("not implemented")
 }
 
@@ -44,7 +47,7 @@ func (t *TimeBound) firstMessage(day string) string {
 	`, day)
 }
 
-func (t *TimeBound) ReminderMessage(rp *bot.ReminderPayload) error {
+func (t *TimeBound) ReminderMessage(ctx context.Context, rp *bot.ReminderPayload) error {
 	attachment := slack.Attachment{
 		Text:       t.reminderMessage(t.cfg.deadline),
 		CallbackID: ReminderMessage,
@@ -53,7 +56,7 @@ func (t *TimeBound) ReminderMessage(rp *bot.ReminderPayload) error {
 
 	message := slack.MsgOptionAttachments(attachment)
 
-	channelID, timestamp, err := t.sc.PostMessage(rp.UserSlackID, message)
+	channelID, timestamp, err := t.sc.PostMessageContext(ctx, rp.UserSlackID, message)
 	if err != nil {
 		t.log.Debug().AnErr("failed to send reminder message", err).Send()
 		return err
@@ -66,6 +69,10 @@ func (t *TimeBound) ReminderMessage(rp *bot.ReminderPayload) error {
 		Str("channel", channelID).
 		Msg("manager message sent")
 
+	metrics.MessageSent(metrics.Reminder)
+	t.bot.Audit(audit.System, audit.ReminderDelivered, rp.UserSlackID, rp.Serial, nil,
+		map[string]any{"os": rp.OS})
+
 	return nil
 }
 
@@ -94,11 +101,11 @@ func (t *TimeBound) reminderMessage(day string) string {
 	`, day)
 }
 
-func (t *TimeBound) waitSend(b *br.Info) {
+func (t *TimeBound) waitSend(ctx context.Context, b *br.Info) {
 	n, err := rand.Int(rand.Reader, big.NewInt(120))
 	if err != nil {
 		t.log.Err(err).Msg("could not generate random delay")
 	}
 	time.Sleep(time.Duration(n.Int64()) * time.Second)
-	t.bot.SendReminder(3, b)
+	t.bot.SendReminder(ctx, 3, b)
 }
@@ -1,12 +1,16 @@
 package timebound
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/bot"
+	"github.com/johnmikee/cuebert/cuebert/metrics"
+	"github.com/johnmikee/cuebert/cuebert/tracing"
 	br "github.com/johnmikee/cuebert/db/bot"
 	"github.com/shomali11/slacker/v2"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -17,13 +21,13 @@ var (
 // the interval. the poll checks that the groups are still valid based on user interval preference
 // and moves them as needed.
 
-func (t *TimeBound) Poll(time.Time) {
+func (t *TimeBound) Poll(ctx context.Context, _ time.Time) {
 	// make sure the jobs are only started once
 	once.Do(func() {
 		t.registerCronJobs()
 	})
 
-	t.pollGroups()
+	t.pollGroups(ctx)
 }
 
 func (t *TimeBound) registerCronJobs() {
@@ -96,6 +100,10 @@ func (t *TimeBound) fourHour() {
 
 // poke them with a reminder
 func (t *TimeBound) poke(ctx *slacker.JobContext, ri int) {
+	c, span := tracing.Start(ctx.Context(), "job "+ctx.Definition().Name,
+		attribute.Int("reminder_interval", ri))
+	defer span.End()
+
 	var devices br.BR
 	switch ri {
 	case 30:
@@ -111,7 +119,7 @@ func (t *TimeBound) poke(ctx *slacker.JobContext, ri int) {
 	}
 
 	for i := range devices {
-		rw, err := t.tables.GetReminderWaiting(devices[i].SerialNumber)
+		rw, err := t.tables.WithContext(c).GetReminderWaiting(devices[i].SerialNumber)
 		if err != nil {
 			t.log.Error().
 				Str("user", devices[i].FullName).
@@ -125,19 +133,23 @@ func (t *TimeBound) poke(ctx *slacker.JobContext, ri int) {
 			continue
 		}
 
-		go t.waitSend(&devices[i])
+		go t.waitSend(c, &devices[i])
 	}
 
 }
 
-func (t *TimeBound) pollGroups() {
-	devices, err := t.tables.GetBotTableInfo()
+func (t *TimeBound) pollGroups(ctx context.Context) {
+	tables := t.tables.WithContext(ctx)
+
+	devices, err := tables.GetBotTableInfo()
 	if err != nil {
+		t.log.Err(err).Msg("could not get info from the bot results table")
+		metrics.RoutineFailed(metrics.Poll)
 		return
 	}
 
 	for i := range devices {
-		ri, err := t.tables.GetReminderInterval(devices[i].SerialNumber)
+		ri, err := tables.GetReminderInterval(devices[i].SerialNumber)
 		if err != nil {
 			t.log.Error().
 				Str("user", devices[i].FullName).
@@ -1,11 +1,13 @@
 package timebound
 
 import (
+	"context"
 	"github.com/johnmikee/cuebert/cuebert/bot"
 	"github.com/johnmikee/cuebert/cuebert/handlers"
 	"github.com/johnmikee/cuebert/cuebert/method"
 	"github.com/johnmikee/cuebert/cuebert/tables"
 	br "github.com/johnmikee/cuebert/db/bot"
+	"github.com/johnmikee/cuebert/mdm"
 	"github.com/johnmikee/cuebert/pkg/logger"
 	"github.com/slack-go/slack"
 )
@@ -14,6 +16,7 @@ type TimeBound struct {
 	log           logger.Logger
 	tables        *tables.Config
 	bot           *bot.Bot
+	mdm           mdm.Provider
 	intervals     Intervals
 	cfg           *Cfg
 	sc            *slack.Client
@@ -33,7 +36,7 @@ const (
 )
 
 // TableAssociations implements method.Actions.
-func (t *TimeBound) TableAssociations([]string) {
+func (t *TimeBound) TableAssociations(context.Context, []string) {
 	t.log.Trace().Msg("nothing to do")
 }
 
@@ -42,11 +45,15 @@ func (t *TimeBound) Setup(method method.Config) {
 	t.log = method.Log
 	t.tables = method.Tables
 	t.bot = method.Bot
+	t.mdm = method.MDM
 	t.sc = method.SlackClient
 	t.statusHandler = method.StatusHandler
 	t.cfg = WithOptions(
 		WithCutoffTime(method.CutoffTime),
 		WithDeadline(method.Deadline),
+		WithDeadlineAction(method.DeadlineAction),
+		WithDeadlineGroup(method.DeadlineGroup),
+		WithPolicy(method.Policy),
 		WithRequiredVers(method.RequiredVers),
 		WithSlackAlertChannel(method.SlackAlertChannel),
 		WithTesting(method.Testing),
@@ -0,0 +1,154 @@
+// Package metrics holds the prometheus collectors served on /metrics.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "cuebert"
+
+// Message is the type of message sent to a user.
+type Message string
+
+const (
+	First    Message = "first"
+	Second   Message = "second"
+	Manager  Message = "manager"
+	Reminder Message = "reminder"
+)
+
+// Exclusion is what happened to an exclusion.
+type Exclusion string
+
+const (
+	ExclusionRequested Exclusion = "requested"
+	ExclusionAdded     Exclusion = "added"
+	ExclusionApproved  Exclusion = "approved"
+	ExclusionDenied    Exclusion = "denied"
+	ExclusionRevoked   Exclusion = "revoked"
+)
+
+// Routine is a routine run on an interval.
+type Routine string
+
+const (
+	Check Routine = "check"
+	Poll  Routine = "poll"
+	Diff  Routine = "diff"
+)
+
+// Service is an api cuebert calls.
+type Service string
+
+const (
+	MDM   Service = "mdm"
+	IDP   Service = "idp"
+	Slack Service = "slack"
+)
+
+var (
+	messagesSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_sent_total",
+		Help:      "Messages delivered to users by type.",
+	}, []string{"type"})
+
+	acknowledgements = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "acknowledgements_total",
+		Help:      "First messages acknowledged by users.",
+	})
+
+	exclusions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "exclusions_total",
+		Help:      "Exclusions by what happened to them.",
+	}, []string{"decision"})
+
+	outOfCompliance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "devices_out_of_compliance",
+		Help:      "Devices missing an active campaign by os version as of the last device diff.",
+	}, []string{"os_version"})
+
+	routineDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "routine_duration_seconds",
+		Help:      "How long each run of a routine took.",
+		Buckets:   []float64{.1, .5, 1, 5, 15, 30, 60, 120, 300, 600},
+	}, []string{"routine"})
+
+	routineFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "routine_failures_total",
+		Help:      "Runs of a routine that stopped on an error.",
+	}, []string{"routine"})
+
+	apiDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_request_duration_seconds",
+		Help:      "Latency of calls to the mdm, idp and slack apis.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "operation"})
+
+	apiErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_errors_total",
+		Help:      "Failed calls to the mdm, idp and slack apis.",
+	}, []string{"service", "operation"})
+)
+
+// MessageSent counts a message delivered to a user.
+func MessageSent(m Message) {
+	messagesSent.WithLabelValues(string(m)).Inc()
+}
+
+// Acknowledged counts a first message acknowledged by a user.
+func Acknowledged() {
+	acknowledgements.Inc()
+}
+
+// ExclusionDecision counts what happened to an exclusion.
+func ExclusionDecision(e Exclusion) {
+	exclusions.WithLabelValues(string(e)).Inc()
+}
+
+// SetOutOfCompliance replaces the count of devices out of compliance for
+// each os version. versions no longer seen are dropped.
+func SetOutOfCompliance(byVersion map[string]int) {
+	outOfCompliance.Reset()
+
+	for v, n := range byVersion {
+		outOfCompliance.WithLabelValues(v).Set(float64(n))
+	}
+}
+
+// Timed wraps a routine so the duration of each run is recorded.
+func Timed(r Routine, f func(context.Context, time.Time)) func(context.Context, time.Time) {
+	return func(ctx context.Context, t time.Time) {
+		start := time.Now()
+		defer func() {
+			routineDuration.WithLabelValues(string(r)).Observe(time.Since(start).Seconds())
+		}()
+
+		f(ctx, t)
+	}
+}
+
+// RoutineFailed counts a run of the routine that stopped on an error.
+func RoutineFailed(r Routine) {
+	routineFailures.WithLabelValues(string(r)).Inc()
+}
+
+// ObserveAPI records the latency of a call and counts it if it failed.
+func ObserveAPI(s Service, operation string, start time.Time, failed bool) {
+	apiDuration.WithLabelValues(string(s), operation).Observe(time.Since(start).Seconds())
+
+	if failed {
+		apiErrors.WithLabelValues(string(s), operation).Inc()
+	}
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type stubMDM struct {
+	mdm.Provider
+	err error
+}
+
+func (s *stubMDM) ListDevices() ([]mdm.Device, error) {
+	return nil, s.err
+}
+
+func TestInstrumentMDM(t *testing.T) {
+	p := InstrumentMDM(&stubMDM{err: errors.New("unavailable")})
+
+	before := testutil.ToFloat64(apiErrors.WithLabelValues(string(MDM), "list_devices"))
+
+	if _, err := p.ListDevices(); err == nil {
+		t.Fatal("expected the provider error")
+	}
+
+	if got := testutil.ToFloat64(apiErrors.WithLabelValues(string(MDM), "list_devices")); got != before+1 {
+		t.Errorf("mdm errors = %v, want %v", got, before+1)
+	}
+
+	if _, ok := p.(mdm.Wrapper); !ok {
+		t.Error("instrumented provider does not unwrap")
+	}
+}
+
+func TestSlackMethod(t *testing.T) {
+	tests := []struct {
+		url    string
+		want   string
+		wantOK bool
+	}{
+		{"https://slack.com/api/chat.postMessage", "chat.postMessage", true},
+		{"https://wss-primary.slack.com/api/apps.connections.open", "apps.connections.open", true},
+		{"https://slack.com/", "", false},
+		{"https://notslack.com/api/chat.postMessage", "", false},
+		{"https://example.kandji.io/api/v1/devices", "", false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.url, nil)
+
+		got, ok := slackMethod(req)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("slackMethod(%s) = %q, %v, want %q, %v", tt.url, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestSlackTransport(t *testing.T) {
+	rt := SlackTransport(roundTripper(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Body: http.NoBody}, nil
+	}))
+
+	before := testutil.ToFloat64(apiErrors.WithLabelValues(string(Slack), "users.info"))
+
+	req := httptest.NewRequest(http.MethodPost, "https://slack.com/api/users.info", strings.NewReader(""))
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+
+	// requests elsewhere are not recorded.
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/api/users.info", nil)
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := testutil.ToFloat64(apiErrors.WithLabelValues(string(Slack), "users.info")); got != before+1 {
+		t.Errorf("slack errors = %v, want %v", got, before+1)
+	}
+}
+
+func TestSlackTransportNotOK(t *testing.T) {
+	tests := []struct {
+		body   string
+		failed bool
+	}{
+		{`{"ok":false,"error":"channel_not_found"}`, true},
+		{`{"ok":true}`, false},
+		{`not json`, false},
+	}
+
+	for _, tt := range tests {
+		rt := SlackTransport(roundTripper(func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json; charset=utf-8"}},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}, nil
+		}))
+
+		before := testutil.ToFloat64(apiErrors.WithLabelValues(string(Slack), "chat.postMessage"))
+
+		req := httptest.NewRequest(http.MethodPost, "https://slack.com/api/chat.postMessage", strings.NewReader(""))
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the body is still there for the slack client.
+		b, err := io.ReadAll(resp.Body)
+		if err != nil || string(b) != tt.body {
+			t.Errorf("got body %q, %v, want %q", b, err, tt.body)
+		}
+
+		want := before
+		if tt.failed {
+			want++
+		}
+		if got := testutil.ToFloat64(apiErrors.WithLabelValues(string(Slack), "chat.postMessage")); got != want {
+			t.Errorf("%s: slack errors = %v, want %v", tt.body, got, want)
+		}
+	}
+}
+
+func TestSetOutOfCompliance(t *testing.T) {
+	SetOutOfCompliance(map[string]int{"13.3": 2, "12.6": 1})
+	SetOutOfCompliance(map[string]int{"13.3": 1})
+
+	if got := testutil.CollectAndCount(outOfCompliance); got != 1 {
+		t.Errorf("got %d os versions, want 1", got)
+	}
+	if got := testutil.ToFloat64(outOfCompliance.WithLabelValues("13.3")); got != 1 {
+		t.Errorf("13.3 = %v, want 1", got)
+	}
+}
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/johnmikee/cuebert/idp"
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+// mdmProvider records the latency and errors of each call to the MDM.
+type mdmProvider struct {
+	mdm.Provider
+}
+
+// InstrumentMDM returns the provider with its calls recorded.
+func InstrumentMDM(p mdm.Provider) mdm.Provider {
+	return &mdmProvider{Provider: p}
+}
+
+// Unwrap implements mdm.Wrapper.
+func (m *mdmProvider) Unwrap() mdm.Provider {
+	return m.Provider
+}
+
+// WithContext implements mdm.Binder.
+func (m *mdmProvider) WithContext(ctx context.Context) mdm.Provider {
+	return &mdmProvider{Provider: mdm.WithContext(m.Provider, ctx)}
+}
+
+// ListDevices implements mdm.Provider.
+func (m *mdmProvider) ListDevices() ([]mdm.Device, error) {
+	start := time.Now()
+	d, err := m.Provider.ListDevices()
+	ObserveAPI(MDM, "list_devices", start, err != nil)
+
+	return d, err
+}
+
+// GetDevice implements mdm.Provider.
+func (m *mdmProvider) GetDevice(deviceID string) (*mdm.Device, error) {
+	start := time.Now()
+	d, err := m.Provider.GetDevice(deviceID)
+	ObserveAPI(MDM, "get_device", start, err != nil)
+
+	return d, err
+}
+
+// QueryDevices implements mdm.Provider.
+func (m *mdmProvider) QueryDevices(opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
+	start := time.Now()
+	d, err := m.Provider.QueryDevices(opts)
+	ObserveAPI(MDM, "query_devices", start, err != nil)
+
+	return d, err
+}
+
+// GetUsers implements mdm.Provider.
+func (m *mdmProvider) GetUsers(opts *mdm.QueryOpts) ([]mdm.User, error) {
+	start := time.Now()
+	u, err := m.Provider.GetUsers(opts)
+	ObserveAPI(MDM, "get_users", start, err != nil)
+
+	return u, err
+}
+
+// idpProvider records the latency and errors of each call to the IdP.
+type idpProvider struct {
+	idp.Provider
+}
+
+// InstrumentIDP returns the provider with its calls recorded.
+func InstrumentIDP(p idp.Provider) idp.Provider {
+	return &idpProvider{Provider: p}
+}
+
+// WithContext implements idp.Binder.
+func (i *idpProvider) WithContext(ctx context.Context) idp.Provider {
+	return &idpProvider{Provider: idp.WithContext(i.Provider, ctx)}
+}
+
+// GetAdminGroup implements idp.Provider.
+func (i *idpProvider) GetAdminGroup(group string) ([]string, error) {
+	start := time.Now()
+	g, err := i.Provider.GetAdminGroup(group)
+	ObserveAPI(IDP, "get_admin_group", start, err != nil)
+
+	return g, err
+}
+
+// GetAllUsers implements idp.Provider.
+func (i *idpProvider) GetAllUsers() ([]idp.User, error) {
+	start := time.Now()
+	u, err := i.Provider.GetAllUsers()
+	ObserveAPI(IDP, "get_all_users", start, err != nil)
+
+	return u, err
+}
+
+// SlackTransport records the latency and errors of requests to the Slack web
+// api, labelled by api method, and passes other requests through. Slack
+// reports most errors in the body of a 200 with "ok": false, so the json
+// bodies are read to count those along with failed requests and error
+// statuses such as rate limiting.
+func SlackTransport(base http.RoundTripper) http.RoundTripper {
+	return &slackTransport{base: base}
+}
+
+type slackTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (s *slackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method, ok := slackMethod(req)
+	if !ok {
+		return s.base.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := s.base.RoundTrip(req)
+	if err != nil {
+		ObserveAPI(Slack, method, start, true)
+		return nil, err
+	}
+
+	failed := resp.StatusCode >= http.StatusBadRequest
+	if !failed && strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		failed, err = notOK(resp)
+	}
+
+	ObserveAPI(Slack, method, start, failed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// notOK reports if the body of the response has "ok": false. The body is
+// replaced so it can be read again.
+func notOK(resp *http.Response) (bool, error) {
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return true, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	var body struct {
+		OK *bool `json:"ok"`
+	}
+	if err := json.Unmarshal(b, &body); err != nil || body.OK == nil {
+		return false, nil
+	}
+
+	return !*body.OK, nil
+}
+
+// slackMethod returns the web api method of requests to slack.
+func slackMethod(req *http.Request) (string, bool) {
+	host := req.URL.Hostname()
+	if host != "slack.com" && !strings.HasSuffix(host, ".slack.com") {
+		return "", false
+	}
+
+	method, ok := strings.CutPrefix(req.URL.Path, "/api/")
+	if !ok || method == "" {
+		return "", false
+	}
+
+	return method, true
+}
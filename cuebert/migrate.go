@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/db/migrate"
+)
+
+const migrateUsage = "usage: cuebert migrate status|up|down [steps] [flags]"
+
+// migrateCommand runs `cuebert migrate status|up|down [steps]` and returns the
+// exit code. Flags after the action are parsed as usual so the database can
+// be picked with -env-type and the service name.
+func migrateCommand(args []string) int {
+	action := "status"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	steps := 1
+	if action == "down" && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 1
+		}
+		steps, args = n, args[1:]
+	}
+
+	switch action {
+	case "status", "up", "down":
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 1
+	}
+
+	cb, _ := loadEnv(args)
+	cb.db = cb.connect()
+
+	var err error
+	if action == "status" {
+		err = cb.migrationStatus(os.Stdout)
+	} else {
+		err = cb.migrate(action, steps)
+	}
+	if err != nil {
+		cb.log.Err(err).Str("action", action).Msg("could not migrate the database")
+		return 1
+	}
+
+	return 0
+}
+
+// migrate applies every pending migration for up or reverts the latest
+// steps migrations for down.
+func (cb *Cuebert) migrate(action string, steps int) error {
+	m, err := cb.migrator()
+	if err != nil || m == nil {
+		return err
+	}
+
+	var done []migrate.Migration
+	switch action {
+	case "up":
+		done, err = m.Up(context.Background())
+	case "down":
+		done, err = m.Down(context.Background(), steps)
+	default:
+		return fmt.Errorf("unknown migrate action %q", action)
+	}
+
+	for i := range done {
+		cb.log.Info().
+			Int("version", done[i].Version).
+			Str("name", done[i].Name).
+			Str("action", action).
+			Msg("migrated")
+	}
+
+	if err == nil && len(done) == 0 {
+		cb.log.Info().Str("action", action).Msg("nothing to migrate")
+	}
+
+	return err
+}
+
+// migrationStatus writes each migration and when it was applied.
+func (cb *Cuebert) migrationStatus(out io.Writer) error {
+	m, err := cb.migrator()
+	if err != nil || m == nil {
+		return err
+	}
+
+	status, err := m.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for i := range status {
+		applied := "pending"
+		if status[i].Applied {
+			applied = status[i].AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status[i].Version, status[i].Name, applied)
+	}
+
+	return w.Flush()
+}
+
+// migrator returns the migrations for postgres. sqlite applies its schema
+// when it is opened so there is nothing to migrate and nil is returned.
+func (cb *Cuebert) migrator() (*migrate.Config, error) {
+	pg, ok := db.AsPostgres(cb.db)
+	if !ok {
+		cb.log.Info().Str("driver", cb.db.Driver()).Msg("schema is applied when the db is opened, nothing to migrate")
+		return nil, nil
+	}
+
+	return migrate.Migrate(pg.Pool, &cb.log)
+}
@@ -0,0 +1,193 @@
+package playbook
+
+import (
+	"context"
+	"time"
+
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Target is a device the playbook is run against along with who to contact.
+type Target struct {
+	SerialNumber   string
+	SlackID        string
+	ManagerSlackID string
+	UserEmail      string
+	FullName       string
+	// Deadline is when the policy rule for the device is due. The deadline
+	// passed to Run is used when it is zero.
+	Deadline time.Time
+}
+
+// Source returns the devices still to be updated that do not have an active
+// exclusion.
+type Source interface {
+	Targets(now time.Time) ([]Target, error)
+}
+
+// Store persists which steps have run for each serial.
+type Store interface {
+	Completed(serials []string) (map[string]map[string]bool, error)
+	Complete(serial, step string, at time.Time) error
+}
+
+// Runner carries out a step for a target. The calls it makes are children of
+// the span in ctx.
+type Runner interface {
+	Run(ctx context.Context, step *Step, target *Target, deadline time.Time) error
+}
+
+// Engine runs the playbook steps that are due. It is meant to be called on
+// an interval and keeps no state of its own so restarts pick up where the
+// store left off.
+type Engine struct {
+	Log          logger.Logger
+	Playbook     *Playbook
+	Source       Source
+	Store        Store
+	Runner       Runner
+	Testing      bool
+	TestingUsers []string
+}
+
+// Run runs every step due by now that has not been completed for each target.
+// Steps for a target run in order and a failed step holds back the ones after
+// it until the next run. A message step is skipped when a later message to
+// the same audience is due as well, so a late first run does not send every
+// reminder at once. While testing only the testing users are acted on,
+// the rest are logged and not recorded. first is the earliest deadline any
+// target can have and deadline is used for targets without their own.
+func (e *Engine) Run(ctx context.Context, now, first, deadline time.Time) {
+	if len(e.due(now, first)) == 0 {
+		e.Log.Trace().Msg("no playbook steps due")
+		return
+	}
+
+	targets, err := e.Source.Targets(now)
+	if err != nil {
+		e.Log.Err(err).Msg("could not get playbook targets")
+		return
+	}
+
+	serials := make([]string, 0, len(targets))
+	for i := range targets {
+		serials = append(serials, targets[i].SerialNumber)
+	}
+
+	completed, err := e.Store.Completed(serials)
+	if err != nil {
+		e.Log.Err(err).Msg("could not get completed playbook steps")
+		return
+	}
+
+	for i := range targets {
+		t := &targets[i]
+
+		d := deadline
+		if !t.Deadline.IsZero() {
+			d = t.Deadline
+		}
+
+		steps := e.due(now, d)
+		for j, step := range steps {
+			if completed[t.SerialNumber][step.Name] {
+				continue
+			}
+
+			if stale(step, steps[j+1:]) {
+				e.skip(t, step, now)
+				continue
+			}
+
+			if e.Testing && !helpers.Contains(e.TestingUsers, t.SlackID) {
+				e.Log.Info().
+					Str("serial", t.SerialNumber).
+					Str("user", t.UserEmail).
+					Str("step", step.Name).
+					Bool("testing", e.Testing).
+					Msg("dry run. would have run playbook step")
+				continue
+			}
+
+			if err := e.Runner.Run(ctx, step, t, d); err != nil {
+				e.Log.Err(err).
+					Str("serial", t.SerialNumber).
+					Str("step", step.Name).
+					Msg("playbook step failed")
+				break
+			}
+
+			if err := e.Store.Complete(t.SerialNumber, step.Name, now); err != nil {
+				e.Log.Err(err).
+					Str("serial", t.SerialNumber).
+					Str("step", step.Name).
+					Msg("could not record playbook step")
+				break
+			}
+
+			e.Log.Info().
+				Str("serial", t.SerialNumber).
+				Str("user", t.UserEmail).
+				Str("step", step.Name).
+				Msg("ran playbook step")
+		}
+	}
+}
+
+// skip records a stale step as done without running it.
+func (e *Engine) skip(t *Target, step *Step, now time.Time) {
+	if e.Testing && !helpers.Contains(e.TestingUsers, t.SlackID) {
+		return
+	}
+
+	if err := e.Store.Complete(t.SerialNumber, step.Name, now); err != nil {
+		e.Log.Err(err).
+			Str("serial", t.SerialNumber).
+			Str("step", step.Name).
+			Msg("could not record skipped playbook step")
+		return
+	}
+
+	e.Log.Info().
+		Str("serial", t.SerialNumber).
+		Str("user", t.UserEmail).
+		Str("step", step.Name).
+		Msg("skipped stale playbook step")
+}
+
+// stale reports if step is a message superseded by a later message to the
+// same audience.
+func stale(step *Step, later []*Step) bool {
+	if step.Action != Message {
+		return false
+	}
+
+	for _, l := range later {
+		if l.Action == Message && l.Audience == step.Audience {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Done reports whether every step is due for the latest deadline, meaning the
+// playbook has nothing left to schedule after the next run.
+func (e *Engine) Done(now, latest time.Time) bool {
+	last := e.Playbook.Steps[len(e.Playbook.Steps)-1]
+
+	return !now.Before(last.Due(latest))
+}
+
+// due returns the steps due by now for the deadline.
+func (e *Engine) due(now, deadline time.Time) []*Step {
+	due := []*Step{}
+	for i := range e.Playbook.Steps {
+		if !now.Before(e.Playbook.Steps[i].Due(deadline)) {
+			due = append(due, &e.Playbook.Steps[i])
+		}
+	}
+
+	return due
+}
@@ -0,0 +1,203 @@
+package playbook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+// Audience is who a message step is sent to.
+type Audience string
+
+const (
+	User    Audience = "user"
+	Manager Audience = "manager"
+	Channel Audience = "channel"
+)
+
+// Action is what a step does.
+type Action string
+
+const (
+	// Message sends the step message to the audience over Slack.
+	Message Action = "message"
+	// Enforce sends the step mdm_action to the MDM for the device.
+	Enforce Action = "enforce"
+	// Ticket posts the step to the playbook ticket_url.
+	Ticket Action = "ticket"
+)
+
+// Playbook is an ordered escalation ladder run against every device that has
+// not updated by the deadline.
+type Playbook struct {
+	// TicketURL is the webhook ticket steps are posted to.
+	TicketURL string `json:"ticket_url" yaml:"ticket_url"`
+	Steps     []Step `json:"steps" yaml:"steps"`
+}
+
+// Step is a single rung of the ladder. Offset is relative to the deadline,
+// ex: -7d runs a week before it, 0 at the deadline and 2d two days after.
+// Hours and minutes are accepted as well (-36h, 90m).
+//
+// Message is a text/template rendered with the Target along with .Deadline
+// and .Step.
+type Step struct {
+	Name      string     `json:"name" yaml:"name"`
+	Offset    string     `json:"offset" yaml:"offset"`
+	Audience  Audience   `json:"audience,omitempty" yaml:"audience,omitempty"`
+	Action    Action     `json:"action" yaml:"action"`
+	Message   string     `json:"message,omitempty" yaml:"message,omitempty"`
+	MDMAction mdm.Action `json:"mdm_action,omitempty" yaml:"mdm_action,omitempty"`
+	Group     string     `json:"group,omitempty" yaml:"group,omitempty"`
+
+	offset time.Duration
+	tmpl   *template.Template
+}
+
+// Load reads the playbook from a .json, .yaml or .yml file and validates it.
+func Load(path string) (*Playbook, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Playbook{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(b, p)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, p)
+	default:
+		return nil, fmt.Errorf("unsupported playbook format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding playbook")
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Validate checks every step and sorts them by offset.
+func (p *Playbook) Validate() error {
+	if len(p.Steps) == 0 {
+		return errors.New("playbook has no steps")
+	}
+
+	seen := map[string]bool{}
+	for i := range p.Steps {
+		s := &p.Steps[i]
+
+		if s.Name == "" {
+			return fmt.Errorf("step %d has no name", i+1)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("step name %q is used more than once", s.Name)
+		}
+		seen[s.Name] = true
+
+		off, err := ParseOffset(s.Offset)
+		if err != nil {
+			return fmt.Errorf("step %q: %w", s.Name, err)
+		}
+		s.offset = off
+
+		switch s.Action {
+		case Message:
+			switch s.Audience {
+			case User, Manager, Channel:
+			default:
+				return fmt.Errorf("step %q: unknown audience %q. options are [user, manager, channel]", s.Name, s.Audience)
+			}
+			if s.Message == "" {
+				return fmt.Errorf("step %q: message steps need a message", s.Name)
+			}
+		case Enforce:
+			if !mdm.ValidAction(s.MDMAction) {
+				return fmt.Errorf("step %q: unknown mdm_action %q", s.Name, s.MDMAction)
+			}
+		case Ticket:
+			if p.TicketURL == "" {
+				return fmt.Errorf("step %q: ticket steps need the playbook ticket_url", s.Name)
+			}
+		default:
+			return fmt.Errorf("step %q: unknown action %q. options are [message, enforce, ticket]", s.Name, s.Action)
+		}
+
+		s.tmpl, err = template.New(s.Name).Parse(s.Message)
+		if err != nil {
+			return fmt.Errorf("step %q: parsing message: %w", s.Name, err)
+		}
+	}
+
+	sort.SliceStable(p.Steps, func(i, j int) bool {
+		return p.Steps[i].offset < p.Steps[j].offset
+	})
+
+	return nil
+}
+
+// Due returns when the step runs for the deadline.
+func (s *Step) Due(deadline time.Time) time.Time {
+	return deadline.Add(s.offset)
+}
+
+// Render returns the step message for the target.
+func (s *Step) Render(t *Target, deadline time.Time) (string, error) {
+	if s.tmpl == nil {
+		return s.Message, nil
+	}
+
+	var buf bytes.Buffer
+	err := s.tmpl.Execute(&buf, struct {
+		Target
+		Deadline string
+		Step     string
+	}{
+		Target:   *t,
+		Deadline: deadline.Format("Monday, January 2 15:04"),
+		Step:     s.Name,
+	})
+
+	return buf.String(), err
+}
+
+// ParseOffset parses an offset from the deadline. A d suffix is read as days,
+// anything else is passed to time.ParseDuration. A leading T is ignored so
+// T-7d and -7d are the same.
+func ParseOffset(s string) (time.Duration, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "T")
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset %q", s)
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset %q", s)
+	}
+
+	return d, nil
+}
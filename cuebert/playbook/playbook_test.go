@@ -0,0 +1,282 @@
+package playbook
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+const testPlaybook = `
+ticket_url: https://tickets.example.com/hook
+steps:
+  - name: overdue
+    offset: 2d
+    action: ticket
+  - name: friendly
+    offset: T-7d
+    audience: user
+    action: message
+    message: "Hi {{.FullName}}, {{.SerialNumber}} needs an update by {{.Deadline}}"
+  - name: manager
+    offset: -3d
+    audience: manager
+    action: message
+    message: "{{.FullName}} has not updated"
+  - name: enforce
+    offset: "0"
+    action: enforce
+    mdm_action: force_install
+`
+
+func loadTest(t *testing.T) *Playbook {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "playbook.yaml")
+	if err := os.WriteFile(path, []byte(testPlaybook), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	return p
+}
+
+func TestLoadSortsSteps(t *testing.T) {
+	p := loadTest(t)
+
+	want := []string{"friendly", "manager", "enforce", "overdue"}
+	for i := range want {
+		if p.Steps[i].Name != want[i] {
+			t.Fatalf("step %d is %s, want %s", i, p.Steps[i].Name, want[i])
+		}
+	}
+
+	deadline := time.Date(2023, 10, 9, 18, 0, 0, 0, time.UTC)
+	msg, err := p.Steps[0].Render(&Target{FullName: "Jane", SerialNumber: "C02ABC"}, deadline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg != "Hi Jane, C02ABC needs an update by Monday, October 9 18:00" {
+		t.Errorf("unexpected message: %s", msg)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Playbook
+	}{
+		{"no steps", Playbook{}},
+		{"no name", Playbook{Steps: []Step{{Action: Ticket}}}},
+		{"duplicate", Playbook{TicketURL: "x", Steps: []Step{{Name: "a", Action: Ticket}, {Name: "a", Action: Ticket}}}},
+		{"bad offset", Playbook{TicketURL: "x", Steps: []Step{{Name: "a", Offset: "soon", Action: Ticket}}}},
+		{"bad audience", Playbook{Steps: []Step{{Name: "a", Action: Message, Audience: "everyone", Message: "hi"}}}},
+		{"no message", Playbook{Steps: []Step{{Name: "a", Action: Message, Audience: User}}}},
+		{"bad mdm action", Playbook{Steps: []Step{{Name: "a", Action: Enforce, MDMAction: "wipe"}}}},
+		{"no ticket url", Playbook{Steps: []Step{{Name: "a", Action: Ticket}}}},
+		{"bad action", Playbook{Steps: []Step{{Name: "a", Action: "page"}}}},
+	}
+
+	for _, tt := range tests {
+		if err := tt.p.Validate(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"0", 0},
+		{"", 0},
+		{"-7d", -7 * 24 * time.Hour},
+		{"T-3d", -3 * 24 * time.Hour},
+		{"2d", 48 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{"-36h", -36 * time.Hour},
+		{"90m", 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseOffset(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseOffset(%q) = %v, %v. want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParseOffset("next week"); err == nil {
+		t.Error("expected an error for an invalid offset")
+	}
+}
+
+type stubSource []Target
+
+func (s stubSource) Targets(time.Time) ([]Target, error) { return s, nil }
+
+type stubStore map[string]map[string]bool
+
+func (s stubStore) Completed([]string) (map[string]map[string]bool, error) { return s, nil }
+
+func (s stubStore) Complete(serial, step string, _ time.Time) error {
+	if s[serial] == nil {
+		s[serial] = map[string]bool{}
+	}
+	s[serial][step] = true
+	return nil
+}
+
+type stubRunner struct {
+	ran  []string
+	fail string
+}
+
+func (r *stubRunner) Run(_ context.Context, step *Step, t *Target, _ time.Time) error {
+	if step.Name == r.fail {
+		return errors.New("failed")
+	}
+	r.ran = append(r.ran, t.SerialNumber+":"+step.Name)
+	return nil
+}
+
+func newEngine(t *testing.T, r *stubRunner, store stubStore) *Engine {
+	return &Engine{
+		Log:      logger.NewLogger(&logger.Config{Level: "error"}),
+		Playbook: loadTest(t),
+		Source: stubSource{
+			{SerialNumber: "A", SlackID: "U1"},
+			{SerialNumber: "B", SlackID: "U2"},
+		},
+		Store:  store,
+		Runner: r,
+	}
+}
+
+func TestEngineRunsDueStepsOnce(t *testing.T) {
+	deadline := time.Date(2023, 10, 9, 18, 0, 0, 0, time.UTC)
+	store := stubStore{"B": {"friendly": true}}
+	r := &stubRunner{}
+	e := newEngine(t, r, store)
+
+	// four days out only the friendly message is due.
+	e.Run(context.Background(), deadline.Add(-4*24*time.Hour), deadline, deadline)
+	if len(r.ran) != 1 || r.ran[0] != "A:friendly" {
+		t.Fatalf("unexpected steps: %v", r.ran)
+	}
+
+	// running again does not repeat the step.
+	e.Run(context.Background(), deadline.Add(-4*24*time.Hour), deadline, deadline)
+	if len(r.ran) != 1 {
+		t.Fatalf("expected no steps to repeat, got: %v", r.ran)
+	}
+
+	if e.Done(deadline, deadline) {
+		t.Error("expected the playbook to have steps left")
+	}
+
+	// a failed step holds back the ones after it.
+	r.fail = "manager"
+	e.Run(context.Background(), deadline, deadline, deadline)
+	if len(r.ran) != 1 {
+		t.Fatalf("expected the failed step to block the rest, got: %v", r.ran)
+	}
+
+	r.fail = ""
+	e.Run(context.Background(), deadline.Add(3*24*time.Hour), deadline, deadline)
+	want := []string{"A:friendly", "A:manager", "A:enforce", "A:overdue", "B:manager", "B:enforce", "B:overdue"}
+	if len(r.ran) != len(want) {
+		t.Fatalf("got steps %v, want %v", r.ran, want)
+	}
+	for i := range want {
+		if r.ran[i] != want[i] {
+			t.Errorf("step %d is %s, want %s", i, r.ran[i], want[i])
+		}
+	}
+
+	if !e.Done(deadline.Add(3*24*time.Hour), deadline) {
+		t.Error("expected the playbook to be done")
+	}
+}
+
+func TestEngineTesting(t *testing.T) {
+	deadline := time.Date(2023, 10, 9, 18, 0, 0, 0, time.UTC)
+	store := stubStore{}
+	r := &stubRunner{}
+	e := newEngine(t, r, store)
+	e.Testing = true
+	e.TestingUsers = []string{"U2"}
+
+	e.Run(context.Background(), deadline.Add(-7*24*time.Hour), deadline, deadline)
+	if len(r.ran) != 1 || r.ran[0] != "B:friendly" {
+		t.Fatalf("expected only the testing user to be acted on, got: %v", r.ran)
+	}
+	if store["A"]["friendly"] {
+		t.Error("dry run steps should not be recorded")
+	}
+}
+
+func TestEngineTargetDeadline(t *testing.T) {
+	deadline := time.Date(2023, 10, 9, 18, 0, 0, 0, time.UTC)
+	store := stubStore{}
+	r := &stubRunner{}
+	e := newEngine(t, r, store)
+	e.Source = stubSource{
+		{SerialNumber: "A", SlackID: "U1"},
+		{SerialNumber: "B", SlackID: "U2", Deadline: deadline.Add(-5 * 24 * time.Hour)},
+	}
+
+	// B is on an earlier policy deadline that has already passed.
+	e.Run(context.Background(), deadline.Add(-4*24*time.Hour), deadline.Add(-5*24*time.Hour), deadline)
+	want := []string{"A:friendly", "B:friendly", "B:manager", "B:enforce"}
+	if len(r.ran) != len(want) {
+		t.Fatalf("got steps %v, want %v", r.ran, want)
+	}
+	for i := range want {
+		if r.ran[i] != want[i] {
+			t.Errorf("step %d is %s, want %s", i, r.ran[i], want[i])
+		}
+	}
+}
+
+func TestEngineLateFirstRun(t *testing.T) {
+	deadline := time.Date(2023, 10, 9, 18, 0, 0, 0, time.UTC)
+	store := stubStore{}
+	r := &stubRunner{}
+	e := newEngine(t, r, store)
+	e.Source = stubSource{{SerialNumber: "A", SlackID: "U1"}}
+	e.Playbook.Steps = append([]Step{{
+		Name:     "heads-up",
+		Offset:   "-14d",
+		Audience: User,
+		Action:   Message,
+		Message:  "heads up",
+	}}, e.Playbook.Steps...)
+	if err := e.Playbook.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// first run after the deadline. only the latest message to each audience
+	// is sent, the actions all run.
+	e.Run(context.Background(), deadline.Add(time.Hour), deadline, deadline)
+	want := []string{"A:friendly", "A:manager", "A:enforce"}
+	if len(r.ran) != len(want) {
+		t.Fatalf("got steps %v, want %v", r.ran, want)
+	}
+	for i := range want {
+		if r.ran[i] != want[i] {
+			t.Errorf("step %d is %s, want %s", i, r.ran[i], want[i])
+		}
+	}
+	if !store["A"]["heads-up"] {
+		t.Error("expected the stale step to be recorded")
+	}
+}
@@ -0,0 +1,317 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/johnmikee/cuebert/cuebert/catalog"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+)
+
+// Rule is the minimum OS version, and when it is due, for the devices it
+// matches. A rule matches on the device model, the major version of the OS
+// the device is running or both. A rule with neither matches every device.
+type Rule struct {
+	// Name is shown in place of the generated description when set.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Models are the model names or identifiers the rule applies to. They are
+	// compared case-insensitively and may use * and ? wildcards.
+	Models []string `json:"models,omitempty" yaml:"models,omitempty"`
+	// Major is the OS major version the rule applies to, ex: 13 or 13.x.
+	Major string `json:"major,omitempty" yaml:"major,omitempty"`
+	// Minimum is the lowest OS version that is compliant, or latest-N to
+	// require the latest release of the newest N+1 major versions from the
+	// catalog.
+	Minimum string `json:"minimum" yaml:"minimum"`
+	// Deadline is the date the update is due. Defaults to -deadline-date.
+	Deadline string `json:"deadline,omitempty" yaml:"deadline,omitempty"`
+	// Requirement is the latest-N requirement Minimum was resolved from.
+	Requirement string `json:"-" yaml:"-"`
+}
+
+// Policy holds the rules devices are evaluated against in order. The first
+// rule that matches is used. Devices no rule matches fall back to Default,
+// which is built from -required-os and -deadline-date.
+type Policy struct {
+	Rules   []Rule `json:"rules" yaml:"rules"`
+	Default Rule   `json:"-" yaml:"-"`
+
+	catalog     *catalog.Catalog
+	catalogLock sync.RWMutex
+}
+
+// New returns a policy holding only the default rule.
+func New(minimum, deadline string) *Policy {
+	return &Policy{
+		Default: Rule{
+			Minimum:  minimum,
+			Deadline: deadline,
+		},
+	}
+}
+
+// Load reads the rules from a .json, .yaml or .yml file. Rules without a
+// deadline use the default deadline.
+func Load(file, minimum, deadline string) (*Policy, error) {
+	b, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+
+	p := New(minimum, deadline)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		err = json.Unmarshal(b, p)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, p)
+	default:
+		return nil, fmt.Errorf("unsupported policy format %q", filepath.Ext(file))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding policy")
+	}
+
+	for i := range p.Rules {
+		r := &p.Rules[i]
+
+		if _, err := helpers.CompareOSVer(r.Minimum, r.Minimum); err != nil && !catalog.IsRequirement(r.Minimum) {
+			return nil, fmt.Errorf("rule %d: invalid minimum version %q", i+1, r.Minimum)
+		}
+
+		r.Major = strings.TrimSuffix(strings.ToLower(r.Major), ".x")
+		for _, m := range r.Models {
+			if _, err := path.Match(strings.ToLower(m), ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid model pattern %q", i+1, m)
+			}
+		}
+
+		if r.Deadline == "" {
+			r.Deadline = deadline
+		}
+	}
+
+	return p, nil
+}
+
+// Match returns the rule for the device. latest-N minimums are resolved for
+// the device against the catalog.
+func (p *Policy) Match(model, osVersion string) *Rule {
+	rule := &p.Default
+	for i := range p.Rules {
+		if p.Rules[i].matches(model, osVersion) {
+			rule = &p.Rules[i]
+			break
+		}
+	}
+
+	return p.resolve(rule, osVersion)
+}
+
+// resolve returns a copy of the rule with the minimum the catalog requires
+// for the OS version. Rules without a latest-N minimum are returned as is.
+func (p *Policy) resolve(r *Rule, osVersion string) *Rule {
+	if !catalog.IsRequirement(r.Minimum) {
+		return r
+	}
+
+	c := p.Catalog()
+	if c == nil {
+		return r
+	}
+
+	minimum, err := c.Minimum(r.Minimum, osVersion)
+	if err != nil {
+		return r
+	}
+
+	resolved := *r
+	resolved.Minimum = minimum
+	resolved.Requirement = r.Minimum
+
+	return &resolved
+}
+
+// NeedsCatalog reports whether any rule uses a latest-N minimum.
+func (p *Policy) NeedsCatalog() bool {
+	if catalog.IsRequirement(p.Default.Minimum) {
+		return true
+	}
+
+	for i := range p.Rules {
+		if catalog.IsRequirement(p.Rules[i].Minimum) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Catalog returns the release catalog latest-N minimums are resolved against.
+func (p *Policy) Catalog() *catalog.Catalog {
+	p.catalogLock.RLock()
+	defer p.catalogLock.RUnlock()
+
+	return p.catalog
+}
+
+// SetCatalog replaces the release catalog.
+func (p *Policy) SetCatalog(c *catalog.Catalog) {
+	p.catalogLock.Lock()
+	defer p.catalogLock.Unlock()
+
+	p.catalog = c
+}
+
+// LoadCatalog loads the catalog from a path or URL and uses it in place of
+// the current one. The current catalog is kept if it cannot be loaded.
+func (p *Policy) LoadCatalog(source string) error {
+	c, err := catalog.Load(source)
+	if err != nil {
+		return err
+	}
+
+	p.SetCatalog(c)
+
+	return nil
+}
+
+// ReloadCatalog reloads the current catalog from its source.
+func (p *Policy) ReloadCatalog() error {
+	c := p.Catalog()
+	if c == nil {
+		return nil
+	}
+
+	return p.LoadCatalog(c.Source)
+}
+
+// Minimums returns the version the default rule requires for each major
+// version in the catalog. It is nil unless the default is latest-N.
+func (p *Policy) Minimums() map[string]string {
+	c := p.Catalog()
+	if c == nil || !catalog.IsRequirement(p.Default.Minimum) {
+		return nil
+	}
+
+	mins, err := c.Minimums(p.Default.Minimum)
+	if err != nil {
+		return nil
+	}
+
+	return mins
+}
+
+// Compliant reports whether the device meets the rule it matches.
+func (p *Policy) Compliant(model, osVersion string) bool {
+	return p.Match(model, osVersion).Compliant(osVersion)
+}
+
+// Compliant reports whether the OS version meets the rule minimum. Versions
+// that cannot be parsed are not compliant.
+func (r *Rule) Compliant(osVersion string) bool {
+	ok, err := helpers.CompareOSVer(osVersion, r.Minimum)
+	return err == nil && ok
+}
+
+// DeadlineLayout is the layout of the deadline date and cutoff time.
+const DeadlineLayout = "01-02-2006 15:04"
+
+// Due returns the rule deadline on the cutoff time.
+func (r *Rule) Due(cutoff string) (time.Time, error) {
+	return time.Parse(DeadlineLayout, fmt.Sprintf("%s %s", r.Deadline, cutoff))
+}
+
+// Deadlines returns when each rule, including the default, is due on the
+// cutoff time. The times are sorted and duplicates are removed.
+func (p *Policy) Deadlines(cutoff string) ([]time.Time, error) {
+	rules := append([]Rule{p.Default}, p.Rules...)
+
+	deadlines := make([]time.Time, 0, len(rules))
+	for i := range rules {
+		due, err := rules[i].Due(cutoff)
+		if err != nil {
+			return nil, err
+		}
+
+		seen := false
+		for _, d := range deadlines {
+			if d.Equal(due) {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			deadlines = append(deadlines, due)
+		}
+	}
+
+	sort.Slice(deadlines, func(i, j int) bool { return deadlines[i].Before(deadlines[j]) })
+
+	return deadlines, nil
+}
+
+// String describes the rule, ex: 13.x must be at least 13.6.4 by 04-01-2024.
+func (r *Rule) String() string {
+	subject := r.Name
+	switch {
+	case subject != "":
+	case r.Major != "" && len(r.Models) > 0:
+		subject = fmt.Sprintf("%s.x on %s", r.Major, strings.Join(r.Models, ", "))
+	case r.Major != "":
+		subject = r.Major + ".x"
+	case len(r.Models) > 0:
+		subject = strings.Join(r.Models, ", ")
+	default:
+		subject = "All devices"
+	}
+
+	minimum := r.Minimum
+	if r.Requirement != "" {
+		minimum = fmt.Sprintf("%s (%s)", r.Minimum, r.Requirement)
+	}
+
+	if r.Deadline == "" {
+		return fmt.Sprintf("%s must be at least %s", subject, minimum)
+	}
+
+	return fmt.Sprintf("%s must be at least %s by %s", subject, minimum, r.Deadline)
+}
+
+func (r *Rule) matches(model, osVersion string) bool {
+	if r.Major != "" && major(osVersion) != r.Major {
+		return false
+	}
+
+	if len(r.Models) == 0 {
+		return true
+	}
+
+	model = strings.ToLower(model)
+	for _, m := range r.Models {
+		if ok, _ := path.Match(strings.ToLower(m), model); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// major returns the major version of the OS, ex: 13 for 13.6.4.
+func major(v string) string {
+	v = strings.TrimSpace(v)
+	if i := strings.Index(v, "."); i >= 0 {
+		return v[:i]
+	}
+
+	return v
+}
@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/catalog"
+)
+
+const testPolicy = `
+rules:
+  - name: Intel MacBook Pros
+    models:
+      - "MacBookPro15,*"
+    minimum: 13.6.4
+    deadline: 04-15-2024
+  - major: 13.x
+    minimum: 13.6.4
+  - major: 14
+    models:
+      - "Mac14,*"
+    minimum: 14.4.1
+    deadline: 03-01-2024
+`
+
+func loadTest(t *testing.T) *Policy {
+	t.Helper()
+
+	f := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(f, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(f, "14.5", "05-01-2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func TestMatch(t *testing.T) {
+	p := loadTest(t)
+
+	tests := []struct {
+		model, os string
+		minimum   string
+		deadline  string
+	}{
+		{"MacBookPro15,2", "12.7.1", "13.6.4", "04-15-2024"},
+		{"macbookpro15,1", "14.1", "13.6.4", "04-15-2024"},
+		{"Mac13,1", "13.5", "13.6.4", "05-01-2024"},
+		{"Mac14,2", "14.2", "14.4.1", "03-01-2024"},
+		{"Mac13,1", "14.2", "14.5", "05-01-2024"},
+		{"Mac14,2", "15.0", "14.5", "05-01-2024"},
+	}
+
+	for _, tt := range tests {
+		r := p.Match(tt.model, tt.os)
+		if r.Minimum != tt.minimum || r.Deadline != tt.deadline {
+			t.Errorf("Match(%q, %q) = %s. want %s by %s", tt.model, tt.os, r, tt.minimum, tt.deadline)
+		}
+	}
+}
+
+func TestCompliant(t *testing.T) {
+	p := loadTest(t)
+
+	if !p.Compliant("Mac13,1", "13.6.4") {
+		t.Error("13.6.4 should meet the 13.x rule")
+	}
+	if p.Compliant("Mac13,1", "13.6.3") {
+		t.Error("13.6.3 should not meet the 13.x rule")
+	}
+	if p.Compliant("Mac13,1", "14.4") {
+		t.Error("14.4 should not meet the default rule")
+	}
+	if p.Compliant("Mac13,1", "unknown") {
+		t.Error("an unparsable version should not be compliant")
+	}
+}
+
+func TestDeadlines(t *testing.T) {
+	p := loadTest(t)
+
+	got, err := p.Deadlines("18:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []time.Time{
+		time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 15, 18, 0, 0, 0, time.UTC),
+		time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got deadlines %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("deadline %d is %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	p := loadTest(t)
+
+	tests := []struct {
+		rule *Rule
+		want string
+	}{
+		{&p.Rules[0], "Intel MacBook Pros must be at least 13.6.4 by 04-15-2024"},
+		{&p.Rules[1], "13.x must be at least 13.6.4 by 05-01-2024"},
+		{&p.Rules[2], "14.x on Mac14,* must be at least 14.4.1 by 03-01-2024"},
+		{&p.Default, "All devices must be at least 14.5 by 05-01-2024"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rule.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(f, []byte("rules:\n  - minimum: newest\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(f, "14.5", "05-01-2024"); err == nil {
+		t.Error("expected an error for an invalid minimum version")
+	}
+}
+
+func TestMatchLatest(t *testing.T) {
+	c, err := catalog.Parse([]byte(`{"OSVersions": [
+		{"OSVersion": "Sonoma 14", "Latest": {"ProductVersion": "14.4.1"}},
+		{"OSVersion": "Ventura 13", "Latest": {"ProductVersion": "13.6.6"}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := New("latest-1", "05-01-2024")
+	if !p.NeedsCatalog() {
+		t.Fatal("latest-1 should need a catalog")
+	}
+	p.SetCatalog(c)
+
+	r := p.Match("Mac13,1", "13.5")
+	if r.Minimum != "13.6.6" || r.Requirement != "latest-1" {
+		t.Errorf("got %s, want 13.6.6 from latest-1", r)
+	}
+	if p.Default.Minimum != "latest-1" {
+		t.Error("resolving should not change the rule")
+	}
+
+	if got, want := r.String(), "All devices must be at least 13.6.6 (latest-1) by 05-01-2024"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if p.Compliant("Mac13,1", "13.6.5") || !p.Compliant("Mac13,1", "13.6.6") {
+		t.Error("13.x devices should need the latest 13.x release")
+	}
+	if p.Compliant("Mac14,2", "14.4") {
+		t.Error("14.x devices should need the latest 14.x release")
+	}
+}
@@ -1,17 +1,20 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/bot"
 	"github.com/johnmikee/cuebert/cuebert/handlers"
+	"github.com/johnmikee/cuebert/cuebert/metrics"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 )
 
-func (c *Cuebert) Poll(t time.Time) {
+func (c *Cuebert) Poll(ctx context.Context, t time.Time) {
 	br, err := c.tables.GetBotTableInfo()
 	if err != nil {
 		c.log.Err(err).Msg("could not get info from the bot results table")
+		metrics.RoutineFailed(metrics.Poll)
 		return
 	}
 
@@ -33,6 +36,7 @@ func (c *Cuebert) Poll(t time.Time) {
 					Str("delay_time", br[i].DelayTime).
 					AnErr("could not get locale difference", err).
 					Send()
+				metrics.RoutineFailed(metrics.Poll)
 				return
 			}
 			c.log.Debug().
@@ -63,7 +67,7 @@ func (c *Cuebert) Poll(t time.Time) {
 					Msg("reminder set")
 
 				// grab their device info
-				var os string
+				var os, model string
 				di, err := c.tables.DeviceBySerial(br[i].SerialNumber)
 				if err != nil {
 					c.log.Debug().
@@ -75,17 +79,20 @@ func (c *Cuebert) Poll(t time.Time) {
 					os = "unknown"
 				} else {
 					os = di[0].OSVersion
+					model = di[0].Model
 				}
+				rule := c.policy.Match(model, os)
 				// sleep until its time and then fire off the alert
-				go c.bot.ScheduleReminder(diff,
+				go c.bot.ScheduleReminder(ctx, diff,
 					&bot.ReminderInfo{
-						Deadline: c.flags.deadline,
+						Deadline: rule.Deadline,
 						Cutoff:   c.flags.cutoffTime,
 						User:     br[i].SlackID,
 						Serial:   br[i].SerialNumber,
-						Version:  c.flags.requiredVers,
+						Version:  rule.Minimum,
 						OS:       os,
 						Text:     ":wave: Here is your requested reminder to update your device!",
+						Policy:   rule.String(),
 					},
 				)
 			}
@@ -93,7 +100,7 @@ func (c *Cuebert) Poll(t time.Time) {
 	}
 
 	// run method specific implementations
-	c.method.Poll(t)
+	c.method.Poll(ctx, t)
 
 	go c.statusHandler.UpdateStatus(
 		&handlers.RoutineUpdate{
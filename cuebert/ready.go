@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/handlers"
+)
+
+// mdmStaleDiffs is how many device diffs can be missed before the mdm is
+// reported as not ready.
+const mdmStaleDiffs = 3
+
+// addReadyChecks adds the dependencies checked by /readyz.
+func (c *Cuebert) addReadyChecks() {
+	c.statusHandler.AddCheck("db", c.dbReady)
+	c.statusHandler.AddCheck("slack", c.bot.SocketReady)
+	c.statusHandler.AddCheck("mdm", c.mdmReady)
+}
+
+// dbReady pings the database and records the result in the status.
+func (c *Cuebert) dbReady(ctx context.Context) error {
+	err := c.db.Ping(ctx)
+
+	status := c.statusHandler.GetStatus()
+	status.DB = &handlers.DBStatus{Connected: err == nil}
+	c.statusHandler.SetStatus(status)
+
+	return err
+}
+
+// mdmPulled records a successful pull of the devices from the mdm.
+func (c *Cuebert) mdmPulled(t time.Time) {
+	c.mdmPulledAt.Store(t.UnixNano())
+}
+
+// mdmReady reports if the devices were pulled from the mdm within the last
+// few device diffs. The pulls only happen while the routines run, which is
+// read from the same atomic state Start and Stop swap so the probe does not
+// race the routines.
+func (c *Cuebert) mdmReady(context.Context) error {
+	if !c.Running() {
+		return nil
+	}
+
+	last := c.mdmPulledAt.Load()
+	if last == 0 {
+		return errors.New("no successful pull yet")
+	}
+
+	age := time.Since(time.Unix(0, last))
+	if age > mdmStaleDiffs*time.Duration(c.flags.deviceDiffInterval)*time.Minute {
+		return fmt.Errorf("last successful pull was %s ago", age.Round(time.Second))
+	}
+
+	return nil
+}
@@ -1,237 +1,397 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/johnmikee/cuebert/cuebert/bot"
 	"github.com/johnmikee/cuebert/cuebert/device"
+	"github.com/johnmikee/cuebert/cuebert/events"
 	"github.com/johnmikee/cuebert/cuebert/handlers"
 	"github.com/johnmikee/cuebert/cuebert/method"
 	mc "github.com/johnmikee/cuebert/cuebert/method/config"
+	"github.com/johnmikee/cuebert/cuebert/metrics"
+	"github.com/johnmikee/cuebert/cuebert/policy"
 	"github.com/johnmikee/cuebert/cuebert/tables"
+	"github.com/johnmikee/cuebert/cuebert/tracing"
 	"github.com/johnmikee/cuebert/cuebert/user"
 	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/db/campaigns"
+	"github.com/johnmikee/cuebert/db/sqlite"
 	"github.com/johnmikee/cuebert/idp"
 	ic "github.com/johnmikee/cuebert/idp/client"
+	"github.com/johnmikee/cuebert/idp/ldap"
 	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/mdm/fleet"
 
 	mdmclient "github.com/johnmikee/cuebert/mdm/client"
 	"github.com/johnmikee/cuebert/pkg/env"
 	"github.com/johnmikee/cuebert/pkg/logger"
+	"github.com/johnmikee/cuebert/pkg/version"
 	"github.com/slack-go/slack"
 )
 
-func loadEnv() (*Cuebert, []string) {
+// loadEnv parses the flags in args, not including the program name, and
+// loads the config for the env they name.
+func loadEnv(args []string) (*Cuebert, []string) {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	f := &Flags{
 		authUsers:               "",
 		authUsersFromIDP:        true,
 		checkInterval:           15,
-		clearTables:             true,
+		clearTables:             false,
 		cutoffTime:              "",
 		dailyReport:             false,
+		dbDriver:                db.DriverPostgres,
+		dbPath:                  "cuebert.db",
 		deadline:                "",
+		deadlineAction:          "",
+		deadlineGroup:           "",
 		defaultReminderInterval: 60,
 		deviceDiffInterval:      30,
 		envType:                 "dev",
+		healthAddr:              ":8888",
 		helpDocsURL:             "https://help.megacorp.com/cuebert",
 		helpRepoURL:             "https://github.com/johnmikee/cuebert",
 		helpTicketURL:           "https://tickets.megacorp.com/cuebert",
 		idp:                     "okta",
 		init:                    true,
+		ldapBaseDN:              "",
+		ldapUserFilter:          ldap.DefaultUserFilter,
 		logLevel:                "trace",
 		logToFile:               false,
 		mdm:                     "kandji",
+		mdmBurst:                10,
+		mdmCacheTTL:             60,
+		mdmMaxRetries:           3,
+		mdmRateLimit:            5,
 		method:                  "manager",
+		osCatalog:               "",
+		otlpEndpoint:            "",
+		otlpInsecure:            false,
+		osPolicy:                "",
+		playbook:                "",
 		pollInterval:            10,
 		requiredVers:            "13.4.1",
 		rebuildTablesOnFailure:  false,
 		sendManagerMissing:      false,
 		serviceName:             "cuebert",
-		tableNames:              strings.Join(db.CueTables, ","),
+		slackAddr:               ":3000",
+		slackMode:               "socket",
+		tableNames:              strings.Join(db.ResetTables, ","),
 		testing:                 true,
 		testingEndTime:          "17:00",
 		testingStartTime:        "11:00",
 		testingUsers:            "",
 	}
 
-	flag.BoolVar(
+	fs.BoolVar(
+		&f.apiAllowStop,
+		"api-allow-stop",
+		f.apiAllowStop,
+		"let the admin api stop cuebert. the api token stands in for the second approval asked for in slack.",
+	)
+	fs.BoolVar(
 		&f.authUsersFromIDP,
 		"auth-users-from-idp",
 		f.authUsersFromIDP,
 		"Set whether to pull authorized users from the IDP.",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.authUsers,
 		"auth-users",
 		f.authUsers,
 		"Set which users can perform authorized functions. (comma separated)",
 	)
-	flag.BoolVar(
+	fs.BoolVar(
 		&f.clearTables,
 		"clear-tables",
 		f.clearTables,
-		"Drop all info from tables on initialization.",
+		"Drop the info in -table-names on initialization instead of reconciling it.",
 	)
-	flag.IntVar(
+	fs.IntVar(
 		&f.checkInterval,
 		"check-interval",
 		f.checkInterval,
 		"the number of minutes between device messaging checks and db clean-ups.",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.cutoffTime,
 		"cutoff-time",
 		f.cutoffTime,
 		"the hour when the install must be done by (HH:MM:SS).",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.deadline,
 		"deadline-date",
 		f.deadline,
 		"the date the install must be done by (YYYY:MM:DD).",
 	)
-	flag.IntVar(
+	fs.StringVar(
+		&f.deadlineAction,
+		"deadline-action",
+		f.deadlineAction,
+		"the action the MDM takes on devices left at the deadline. Options are [schedule_update, force_install, remediate]. Unset takes no action.",
+	)
+	fs.StringVar(
+		&f.deadlineGroup,
+		"deadline-group",
+		f.deadlineGroup,
+		"the blueprint, group or team id devices are moved to by the remediate deadline action.",
+	)
+	fs.IntVar(
 		&f.defaultReminderInterval,
 		"default-reminder-interval",
 		f.defaultReminderInterval,
 		"the number of minutes between reminders.",
 	)
-	flag.IntVar(
+	fs.IntVar(
 		&f.deviceDiffInterval,
 		"device-diff-interval",
 		f.deviceDiffInterval,
 		"the number of minutes between device diff checks.",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.envType,
 		"env-type",
 		f.envType,
 		"Set the env type. Options are [prod, dev].",
 	)
-	flag.StringVar(
+	fs.StringVar(
+		&f.healthAddr,
+		"health-addr",
+		f.healthAddr,
+		"the address the /health, /livez, /readyz and /metrics endpoints listen on.",
+	)
+	fs.UintVar(
+		&f.fleetLabelID,
+		"fleet-label-id",
+		f.fleetLabelID,
+		"limit the hosts pulled from fleet to this label id.",
+	)
+	fs.UintVar(
+		&f.fleetTeamID,
+		"fleet-team-id",
+		f.fleetTeamID,
+		"limit the hosts pulled from fleet to this team id.",
+	)
+	fs.StringVar(
 		&f.helpDocsURL,
 		"help-docs-url",
 		f.helpDocsURL,
 		"the url to the cuebert docs.",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.helpRepoURL,
 		"help-repo-url",
 		f.helpRepoURL,
 		"the url to the cuebert repo.",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.helpTicketURL,
 		"help-ticket-url",
 		f.helpTicketURL,
 		"the url to the cuebert ticketing system.",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.idp,
 		"idp",
 		f.idp,
-		"Set the IDP to use. Options are [okta].",
+		fmt.Sprintf("Set the IDP to use. Options are [%s].", strings.Join(idp.Providers(), ", ")),
 	)
-	flag.BoolVar(
+	fs.BoolVar(
 		&f.init,
 		"init",
 		f.init,
 		"Start the program, load the config, and wait for input before running.",
 	)
-	flag.BoolVar(
+	fs.StringVar(
+		&f.ldapBaseDN,
+		"ldap-base-dn",
+		f.ldapBaseDN,
+		"the base dn to search the directory from. defaults to the idp domain.",
+	)
+	fs.StringVar(
+		&f.ldapUserFilter,
+		"ldap-user-filter",
+		f.ldapUserFilter,
+		"the ldap filter used to find users.",
+	)
+	fs.BoolVar(
 		&f.logToFile,
 		"log-to-file",
 		f.logToFile,
 		"Log results to file.",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.logLevel,
 		"log-level",
 		f.logLevel,
 		"Set the log level.",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.mdm,
 		"mdm",
 		f.mdm,
-		"Set the MDM to use. Options are [jamf, kandji].",
-	)
-	flag.StringVar(
+		fmt.Sprintf("Set the MDM to use. Options are [%s].", strings.Join(mdm.Providers(), ", ")),
+	)
+	fs.IntVar(
+		&f.mdmBurst,
+		"mdm-burst",
+		f.mdmBurst,
+		"the number of requests that can be sent to the MDM at once before the rate limit applies.",
+	)
+	fs.IntVar(
+		&f.mdmCacheTTL,
+		"mdm-cache-ttl",
+		f.mdmCacheTTL,
+		"the number of seconds MDM results are reused. 0 disables the cache.",
+	)
+	fs.IntVar(
+		&f.mdmMaxRetries,
+		"mdm-max-retries",
+		f.mdmMaxRetries,
+		"the number of times a throttled or failed MDM request is retried.",
+	)
+	fs.Float64Var(
+		&f.mdmRateLimit,
+		"mdm-rate-limit",
+		f.mdmRateLimit,
+		"the number of requests per second sent to the MDM. 0 disables the limit.",
+	)
+	fs.StringVar(
 		&f.method,
 		"method",
 		f.method,
 		"Set the method to use. Options are [manager, device].",
 	)
-	flag.BoolVar(
+	fs.BoolVar(
 		&f.rebuildTablesOnFailure,
 		"rebuild-tables-on-failure",
 		f.rebuildTablesOnFailure,
 		"rebuild tables on an abnormal exit.",
 	)
-	flag.BoolVar(
+	fs.BoolVar(
 		&f.sendManagerMissing,
 		"send-manager-missing",
 		f.sendManagerMissing,
 		"send a message to the alert channel of missing managers",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.serviceName,
 		"service-name",
 		f.serviceName,
 		"if using the dev env the service name to store keys under.",
 	)
-	flag.StringVar(
+	fs.StringVar(
+		&f.slackAddr,
+		"slack-addr",
+		f.slackAddr,
+		"the address slack sends events, interactivity and slash commands to when -slack-mode is http.",
+	)
+	fs.StringVar(
+		&f.slackMode,
+		"slack-mode",
+		f.slackMode,
+		"how slack reaches cuebert. Options are [socket, http].",
+	)
+	fs.StringVar(
 		&f.tableNames,
 		"table-names",
 		f.tableNames,
 		"a list of tables to clear on initialization. (comma separated)",
 	)
-	flag.BoolVar(
+	fs.BoolVar(
 		&f.testing,
 		"testing",
 		f.testing,
 		"Log actions that would take place instead of performing them.",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.testingEndTime,
 		"testing-end-time",
 		f.testingEndTime,
 		"the time to end testing (HH:MM).",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.testingStartTime,
 		"testing-start-time",
 		f.testingStartTime,
 		"the time to start testing (HH:MM).",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.testingUsers,
 		"testing-users",
 		f.testingUsers,
 		"a list of slack id's to perform the actions on during testing instead of every user. (comma separated)",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&f.requiredVers,
 		"required-os",
 		f.requiredVers,
 		"the version to require for the fleet",
 	)
-	flag.IntVar(
+	fs.StringVar(
+		&f.osCatalog,
+		"os-catalog",
+		f.osCatalog,
+		"path or url to a SOFA format macOS release feed used to resolve latest and latest-N required versions.",
+	)
+	fs.StringVar(
+		&f.otlpEndpoint,
+		"otlp-endpoint",
+		f.otlpEndpoint,
+		"the host:port of an OTLP/HTTP collector to send traces to. Unset disables tracing.",
+	)
+	fs.BoolVar(
+		&f.otlpInsecure,
+		"otlp-insecure",
+		f.otlpInsecure,
+		"send traces to the collector over http instead of https.",
+	)
+	fs.StringVar(
+		&f.osPolicy,
+		"os-policy",
+		f.osPolicy,
+		"path to a .json or .yaml policy of minimum versions and deadlines per model or major version. falls back to -required-os.",
+	)
+	fs.StringVar(
+		&f.playbook,
+		"playbook",
+		f.playbook,
+		"path to a .json or .yaml escalation playbook run against the deadline instead of the deadline action.",
+	)
+	fs.IntVar(
 		&f.pollInterval,
 		"poll-interval",
 		f.pollInterval,
 		"the number of minutes between device polling checks.",
 	)
-	flag.BoolVar(
+	fs.BoolVar(
 		&f.dailyReport,
 		"daily-report",
 		f.dailyReport,
 		"send a daily report to the admin alert channel.",
 	)
+	fs.StringVar(
+		&f.dbDriver,
+		"db-driver",
+		f.dbDriver,
+		"the database to store the tables in. Options are [postgres, sqlite].",
+	)
+	fs.StringVar(
+		&f.dbPath,
+		"db-path",
+		f.dbPath,
+		"the file the tables are stored in when -db-driver is sqlite.",
+	)
 
-	flag.Parse()
+	_ = fs.Parse(args)
 
 	envArgs := os.Args[0:]
 
@@ -265,6 +425,38 @@ func loadEnv() (*Cuebert, []string) {
 		config: &cfg,
 	}
 
+	if f.slackMode != "socket" && f.slackMode != "http" {
+		log.Info().Str("mode", f.slackMode).Msg("unknown slack mode, options are [socket, http], exiting")
+		os.Exit(1)
+	}
+
+	if f.deadlineAction != "" && !mdm.ValidAction(mdm.Action(f.deadlineAction)) {
+		log.Info().Str("action", f.deadlineAction).Msg("unknown deadline action, exiting")
+		os.Exit(1)
+	}
+
+	config.policy = policy.New(f.requiredVers, f.deadline)
+	if f.osPolicy != "" {
+		p, err := policy.Load(f.osPolicy, f.requiredVers, f.deadline)
+		if err != nil {
+			log.Err(err).Msg("could not load os policy, exiting")
+			os.Exit(1)
+		}
+		config.policy = p
+	}
+
+	if f.osCatalog != "" {
+		if err := config.policy.LoadCatalog(f.osCatalog); err != nil {
+			log.Err(err).Msg("could not load os catalog, exiting")
+			os.Exit(1)
+		}
+	}
+
+	if config.policy.NeedsCatalog() && config.policy.Catalog() == nil {
+		log.Info().Msg("latest and latest-N required versions need -os-catalog, exiting")
+		os.Exit(1)
+	}
+
 	if f.testing {
 		if f.testingUsers != "" {
 			userSlice := strings.Split(f.testingUsers, ",")
@@ -275,8 +467,22 @@ func loadEnv() (*Cuebert, []string) {
 	return config, envArgs
 }
 
-func setup() *Cuebert {
-	cb, _ := loadEnv()
+// connect opens the database picked with -db-driver.
+func (cb *Cuebert) connect() db.Store {
+	switch cb.flags.dbDriver {
+	case db.DriverPostgres:
+	case db.DriverSQLite:
+		store, err := sqlite.Open(cb.flags.dbPath)
+		if err != nil {
+			cb.log.Info().AnErr("opening sqlite db", err).Send()
+			os.Exit(3)
+		}
+
+		return store
+	default:
+		cb.log.Info().Str("driver", cb.flags.dbDriver).Msg("unknown db driver, options are [postgres, sqlite]")
+		os.Exit(3)
+	}
 
 	conn, err := tables.Connect(
 		&tables.Conf{
@@ -292,41 +498,47 @@ func setup() *Cuebert {
 		cb.log.Info().AnErr("connecting to db", err).Send()
 		os.Exit(3)
 	}
-	cb.db = conn.DB
 
-	idpclient := ic.New(
-		&ic.IDP{
-			IDP: idp.IDP(cb.flags.idp),
-			Config: idp.Config{
-				Domain: cb.config.IDPDomain,
-				URL:    cb.config.IDPURL,
-				Token:  cb.config.IDPToken,
-				Client: nil,
-				Log:    cb.log,
-			},
-		},
-	)
-	mdmclient := mdmclient.New(
+	return conn.Store
+}
+
+// mdmClient returns the client for the mdm picked with -mdm.
+func (cb *Cuebert) mdmClient() (mdm.Provider, error) {
+	return mdmclient.New(
 		&mdmclient.MDM{
 			MDM: mdm.MDM(cb.flags.mdm),
 			Config: mdm.Config{
-				Domain: cb.config.IDPDomain,
-				MDM:    mdm.MDM(cb.flags.mdm),
-				URL:    cb.config.MDMURL,
-				Token:  cb.config.MDMKey,
-				Client: nil,
-				Log:    cb.log,
+				Domain:                 cb.config.IDPDomain,
+				MDM:                    mdm.MDM(cb.flags.mdm),
+				URL:                    cb.config.MDMURL,
+				User:                   cb.config.MDMUser,
+				Password:               cb.config.MDMPassword,
+				Token:                  cb.config.MDMKey,
+				TokenURL:               cb.config.MDMTokenURL,
+				Client:                 nil,
+				Log:                    cb.log,
+				ProviderSpecificConfig: mdmProviderConfig(cb.flags),
 			},
+			CacheTTL:   time.Duration(cb.flags.mdmCacheTTL) * time.Second,
+			RateLimit:  cb.flags.mdmRateLimit,
+			Burst:      cb.flags.mdmBurst,
+			MaxRetries: cb.flags.mdmMaxRetries,
+			Instrument: metrics.InstrumentMDM,
 		},
 	)
-	tables := tables.New(
-		conn.DB,
+}
+
+// newTables returns the tables. the devices and users are pulled from the
+// mdm client when the tables are built.
+func (cb *Cuebert) newTables(client mdm.Provider) *tables.Config {
+	return tables.New(
+		cb.db,
 		&cb.log,
 		tables.WithDevices(
 			device.New(
 				&device.Config{
-					Client: mdmclient,
-					DB:     conn.DB,
+					Client: client,
+					DB:     cb.db,
 					Log:    &cb.log,
 				},
 			),
@@ -334,20 +546,91 @@ func setup() *Cuebert {
 		tables.WithUsers(
 			user.New(
 				&user.Config{
-					DB:     conn.DB,
+					DB:     cb.db,
 					Log:    &cb.log,
 					Slack:  slack.New(cb.config.SlackBotToken),
-					Client: mdmclient,
+					Client: client,
 				},
 			),
 		),
 	)
+}
+
+func setup() *Cuebert {
+	cb, _ := loadEnv(os.Args[1:])
+
+	shutdown, err := tracing.Setup(context.Background(), &tracing.Config{
+		Endpoint: cb.flags.otlpEndpoint,
+		Insecure: cb.flags.otlpInsecure,
+		Service:  cb.flags.serviceName,
+		Version:  version.Version().Version,
+	})
+	if err != nil {
+		cb.log.Err(err).Msg("could not set up tracing")
+		os.Exit(3)
+	}
+	cb.shutdownTracing = shutdown
+
+	// the slack, mdm and idp clients, including the one slacker builds, use
+	// the default transport so their calls are recorded and traced here.
+	http.DefaultTransport = tracing.Transport(metrics.SlackTransport(http.DefaultTransport))
+
+	if cb.flags.slackMode == "http" {
+		cb.events, err = events.New(&events.Config{
+			Addr:          cb.flags.slackAddr,
+			SigningSecret: cb.config.SlackSigningSecret,
+			Log:           cb.log,
+		})
+		if err != nil {
+			cb.log.Err(err).Msg("could not set up http mode")
+			os.Exit(3)
+		}
+		// socket mode connects to the relay instead of slack.
+		http.DefaultTransport = cb.events.Transport(http.DefaultTransport)
+	}
+
+	cb.db = tracing.Store(cb.connect())
+
+	// bring the schema up to date before anything reads from it.
+	if err := cb.migrate("up", 0); err != nil {
+		cb.log.Err(err).Msg("could not migrate the database")
+		os.Exit(3)
+	}
+
+	idpclient, err := ic.New(
+		&ic.IDP{
+			IDP: idp.IDP(cb.flags.idp),
+			Config: idp.Config{
+				Domain:                 cb.config.IDPDomain,
+				URL:                    cb.config.IDPURL,
+				User:                   cb.config.IDPUser,
+				Password:               cb.config.IDPPassword,
+				Token:                  cb.config.IDPToken,
+				TokenURL:               cb.config.IDPTokenURL,
+				Client:                 nil,
+				Log:                    cb.log,
+				ProviderSpecificConfig: idpProviderConfig(cb.flags),
+			},
+		},
+	)
+	if err != nil {
+		cb.log.Err(err).Msg("could not create idp client")
+		os.Exit(3)
+	}
+	idpclient = metrics.InstrumentIDP(idpclient)
+
+	mdmclient, err := cb.mdmClient()
+	if err != nil {
+		cb.log.Err(err).Msg("could not create mdm client")
+		os.Exit(3)
+	}
+	tables := cb.newTables(mdmclient)
 	method := mc.New(
 		&mc.Method{
 			Method: method.Option(cb.flags.method),
 			Config: method.Config{
 				Log:               cb.log,
-				Tables:            tables,
+				Tables:            tables.ForCampaign(campaigns.Default),
 				Bot:               cb.bot,
 				StatusHandler:     cb.statusHandler,
 				SlackClient:       slack.New(cb.config.SlackBotToken),
@@ -356,7 +639,10 @@ func setup() *Cuebert {
 				SlackAlertChannel: cb.config.SlackAlertChannel,
 				CutoffTime:        cb.flags.cutoffTime,
 				Deadline:          cb.flags.deadline,
+				DeadlineAction:    mdm.Action(cb.flags.deadlineAction),
+				DeadlineGroup:     cb.flags.deadlineGroup,
 				RequiredVers:      cb.flags.requiredVers,
+				Policy:            cb.policy,
 				Testing:           cb.flags.testing,
 				TestingUsers:      cb.testUsers,
 				PollInterval:      cb.flags.pollInterval,
@@ -370,15 +656,15 @@ func setup() *Cuebert {
 	cb.reloadSignal = make(chan struct{})
 	cb.statusChan = make(chan handlers.StatusMessage)
 	cb.statusHandler = &handlers.StatusHandler{}
+	cb.setCatalogStatus(nil)
 	cb.startSignal = make(chan struct{})
 	cb.stopSignal = make(chan struct{})
-	cb.isRunning = false
 
 	cb.bot = bot.New(
 		&bot.Config{
 			SlackBotToken: cb.config.SlackBotToken,
 			SlackAppToken: cb.config.SlackAppToken,
-			DB:            conn.DB,
+			DB:            cb.db,
 			IDP:           idpclient,
 			MDM:           mdmclient,
 			Log:           cb.log,
@@ -400,6 +686,7 @@ func setup() *Cuebert {
 				bot.WithHelpTicketURL(cb.flags.helpTicketURL),
 				bot.WithLogLevel(cb.flags.logLevel),
 				bot.WithLogToFile(cb.flags.logToFile),
+				bot.WithPolicy(cb.policy),
 				bot.WithPollInterval(cb.flags.pollInterval),
 				bot.WithRequiredVers(cb.flags.requiredVers),
 				bot.WithSlackAlertChannel(cb.config.SlackAlertChannel),
@@ -414,19 +701,21 @@ func setup() *Cuebert {
 	)
 
 	if cb.flags.authUsersFromIDP {
-		oid, err := cb.idp.GetAdminGroup(cb.config.AdminGroupID)
+		ctx, span := tracing.Start(context.Background(), "authUsersFromIDP")
+		oid, err := tracing.IDP(ctx, cb.idp).GetAdminGroup(cb.config.AdminGroupID)
 		if err != nil {
 			cb.log.Err(err).Msg("could not get admin group")
 			os.Exit(3)
 		}
 		for _, e := range oid {
-			sid, err := cb.bot.Client().GetUserByEmail(e)
+			sid, err := cb.bot.Client().GetUserByEmailContext(ctx, e)
 			if err != nil {
 				cb.log.Debug().Str("user", e).Msg("could not get user id")
 				continue
 			}
 			cb.authUsers = append(cb.authUsers, sid.ID)
 		}
+		span.End()
 	} else if cb.flags.authUsers != "" {
 		userSlice := strings.Split(cb.flags.authUsers, ",")
 		cb.authUsers = userSlice
@@ -434,5 +723,42 @@ func setup() *Cuebert {
 
 	cb.bot.UpdateCfg(bot.WithAuthUsers(cb.authUsers))
 
+	if cb.flags.playbook != "" {
+		pb, err := cb.newPlaybook(cb.flags.playbook)
+		if err != nil {
+			cb.log.Err(err).Msg("could not load playbook")
+			os.Exit(3)
+		}
+		cb.playbook = pb
+	}
+
 	return cb
 }
+
+// mdmProviderConfig returns the provider specific configuration for the
+// selected MDM, if it takes any.
+func mdmProviderConfig(f *Flags) interface{} {
+	switch mdm.MDM(f.mdm) {
+	case mdm.Fleet:
+		return &fleet.Filter{
+			TeamID:  f.fleetTeamID,
+			LabelID: f.fleetLabelID,
+		}
+	default:
+		return nil
+	}
+}
+
+// idpProviderConfig returns the provider specific configuration for the
+// selected IDP, if it takes any.
+func idpProviderConfig(f *Flags) interface{} {
+	switch idp.IDP(f.idp) {
+	case idp.LDAP:
+		return &ldap.Options{
+			BaseDN:     f.ldapBaseDN,
+			UserFilter: f.ldapUserFilter,
+		}
+	default:
+		return nil
+	}
+}
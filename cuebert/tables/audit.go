@@ -0,0 +1,66 @@
+package tables
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/johnmikee/cuebert/db/audit"
+	"github.com/johnmikee/cuebert/db/compare"
+)
+
+type Audit = Config
+
+// Record appends an event to the audit log. before and after are stored as
+// json and left empty when nil.
+func (c *Audit) Record(actor string, action audit.Action, user, serial string, before, after any) error {
+	b, err := auditState(before)
+	if err != nil {
+		return err
+	}
+
+	a, err := auditState(after)
+	if err != nil {
+		return err
+	}
+
+	return c.au(c.db, &c.log).Add(&audit.Info{
+		Actor:        actor,
+		Action:       action,
+		TargetUser:   user,
+		TargetSerial: serial,
+		Before:       b,
+		After:        a,
+	})
+}
+
+func auditState(v any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// AuditByUser returns the events the users took or that were taken on them.
+func (c *Audit) AuditByUser(user ...string) (audit.AI, error) {
+	return c.au(c.db, &c.log).Query().User(user...).Query()
+}
+
+// AuditBySerial returns the events taken on the devices.
+func (c *Audit) AuditBySerial(serial ...string) (audit.AI, error) {
+	return c.au(c.db, &c.log).Query().Serial(serial...).Query()
+}
+
+// AuditSince returns the events recorded after the time. The time is passed
+// without a zone so sqlite compares it as text the same way postgres compares
+// the timestamp.
+func (c *Audit) AuditSince(t time.Time) (audit.AI, error) {
+	return c.au(c.db, &c.log).Query().
+		Created(t.UTC().Format("2006-01-02 15:04:05"), compare.GreaterThanOrEqual).
+		Query()
+}
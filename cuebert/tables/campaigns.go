@@ -0,0 +1,76 @@
+package tables
+
+import (
+	"github.com/johnmikee/cuebert/db/campaigns"
+)
+
+type Campaigns = Config
+
+// AddCampaign creates the campaign or replaces the settings and status of an
+// existing campaign with the same id.
+func (c *Campaigns) AddCampaign(info *campaigns.Info) error {
+	_, err := c.cp(c.db, &c.log).Add().
+		ID(info.ID).
+		Name(info.Name).
+		RequiredOS(info.RequiredOS).
+		Deadline(info.Deadline).
+		CutoffTime(info.CutoffTime).
+		Status(info.Status).
+		CreatedBy(info.CreatedBy).
+		Execute()
+
+	return err
+}
+
+// OpenCampaign creates the campaign or replaces the settings of an existing
+// campaign with the same id. An existing campaign keeps its status so one
+// paused or closed from slack stays that way.
+func (c *Campaigns) OpenCampaign(info *campaigns.Info) error {
+	_, err := c.cp(c.db, &c.log).Add().
+		ID(info.ID).
+		Name(info.Name).
+		RequiredOS(info.RequiredOS).
+		Deadline(info.Deadline).
+		CutoffTime(info.CutoffTime).
+		Status(info.Status).
+		CreatedBy(info.CreatedBy).
+		KeepStatus().
+		Execute()
+
+	return err
+}
+
+// Campaigns returns the campaigns in any of the statuses. All campaigns are
+// returned when no status is passed.
+func (c *Campaigns) Campaigns(status ...campaigns.Status) (campaigns.CI, error) {
+	q := c.cp(c.db, &c.log).Query()
+	if len(status) == 0 {
+		return q.All().Query()
+	}
+
+	return q.Status(status...).Query()
+}
+
+// CampaignByID returns the campaign with the id.
+func (c *Campaigns) CampaignByID(id string) (campaigns.CI, error) {
+	return c.cp(c.db, &c.log).Query().ID(id).Query()
+}
+
+// SetCampaignStatus moves the campaign to the status.
+func (c *Campaigns) SetCampaignStatus(id string, status campaigns.Status) error {
+	_, err := c.cp(c.db, &c.log).Update(id).Status(status).Send()
+
+	return err
+}
+
+// CloseCampaign closes the campaign and removes its bot results so nobody is
+// reminded about it again.
+func (c *Campaigns) CloseCampaign(id string) error {
+	if err := c.SetCampaignStatus(id, campaigns.Closed); err != nil {
+		return err
+	}
+
+	_, err := c.ForCampaign(id).RemoveBRBy().All().Execute()
+
+	return err
+}
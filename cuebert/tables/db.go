@@ -7,9 +7,13 @@ import (
 	"github.com/johnmikee/cuebert/cuebert/device"
 	"github.com/johnmikee/cuebert/cuebert/user"
 	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/db/audit"
 	"github.com/johnmikee/cuebert/db/bot"
+	"github.com/johnmikee/cuebert/db/campaigns"
 	"github.com/johnmikee/cuebert/db/devices"
 	"github.com/johnmikee/cuebert/db/exclusions"
+	"github.com/johnmikee/cuebert/db/history"
+	"github.com/johnmikee/cuebert/db/playbook"
 	"github.com/johnmikee/cuebert/db/users"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
@@ -17,40 +21,65 @@ import (
 // Config is a struct to hold config for connecting to the
 // different tables in the DB.
 type Config struct {
-	user       func(*db.DB, *logger.Logger) *users.Config
-	exclusions func(*db.DB, *logger.Logger) *exclusions.Config
-	dev        func(*db.DB, *logger.Logger) *devices.Config
-	br         func(*db.DB, *logger.Logger) *bot.Config
+	user       func(db.Store, *logger.Logger) *users.Config
+	exclusions func(db.Store, *logger.Logger) *exclusions.Config
+	dev        func(db.Store, *logger.Logger) *devices.Config
+	br         func(db.Store, *logger.Logger) *bot.Config
+	pb         func(db.Store, *logger.Logger) *playbook.Config
+	cp         func(db.Store, *logger.Logger) *campaigns.Config
+	hs         func(db.Store, *logger.Logger) *history.Config
+	au         func(db.Store, *logger.Logger) *audit.Config
+
+	db       db.Store
+	log      logger.Logger
+	devices  *device.Device
+	users    *user.User
+	campaign string
+}
 
-	db      *db.DB
-	log     logger.Logger
-	devices *device.Device
-	users   *user.User
+func a(db db.Store, l *logger.Logger) *audit.Config {
+	return audit.Audit(db, l)
 }
 
-func b(db *db.DB, l *logger.Logger) *bot.Config {
+func b(db db.Store, l *logger.Logger) *bot.Config {
 	return bot.Bot(db, l)
 }
 
-func e(db *db.DB, l *logger.Logger) *exclusions.Config {
+func c(db db.Store, l *logger.Logger) *campaigns.Config {
+	return campaigns.Campaign(db, l)
+}
+
+func e(db db.Store, l *logger.Logger) *exclusions.Config {
 	return exclusions.Exclusion(db, l)
 }
 
-func d(db *db.DB, l *logger.Logger) *devices.Config {
+func d(db db.Store, l *logger.Logger) *devices.Config {
 	return devices.Device(db, l)
 }
 
-func u(db *db.DB, l *logger.Logger) *users.Config {
+func h(db db.Store, l *logger.Logger) *history.Config {
+	return history.History(db, l)
+}
+
+func p(db db.Store, l *logger.Logger) *playbook.Config {
+	return playbook.Playbook(db, l)
+}
+
+func u(db db.Store, l *logger.Logger) *users.Config {
 	return users.User(db, l)
 }
 
 // New returns a new db connection
-func New(db *db.DB, log *logger.Logger, opts ...Option) *Config {
+func New(db db.Store, log *logger.Logger, opts ...Option) *Config {
 	config := &Config{
 		user:       u,
 		exclusions: e,
 		dev:        d,
 		br:         b,
+		pb:         p,
+		cp:         c,
+		hs:         h,
+		au:         a,
 		log:        logger.ChildLogger("tables", log),
 		db:         db,
 	}
@@ -62,6 +91,32 @@ func New(db *db.DB, log *logger.Logger, opts ...Option) *Config {
 	return config
 }
 
+// ForCampaign returns a copy of the config whose bot_results queries, updates
+// and removals only touch the rows of the campaign.
+func (c *Config) ForCampaign(id string) *Config {
+	scoped := *c
+	scoped.campaign = id
+	scoped.br = func(db db.Store, l *logger.Logger) *bot.Config {
+		return c.br(db, l).Campaign(id)
+	}
+
+	return &scoped
+}
+
+// WithContext returns a copy of the config whose statements are traced as
+// children of the span in ctx.
+func (c *Config) WithContext(ctx context.Context) *Config {
+	scoped := *c
+	scoped.db = db.WithContext(c.db, ctx)
+
+	return &scoped
+}
+
+// CampaignID returns the campaign the config is scoped to, if any.
+func (c *Config) CampaignID() string {
+	return c.campaign
+}
+
 type Option func(*Config)
 
 func WithUsers(u *user.User) func(*Config) {
@@ -102,19 +157,24 @@ const (
 	UserName Get = "username"
 )
 
-// Print out args for connection to the DB
-func (db *Config) Print(item Get) string {
+// Print out args for connection to the DB. Only postgres has them.
+func (c *Config) Print(item Get) string {
+	pg, ok := db.AsPostgres(c.db)
+	if !ok {
+		return ""
+	}
+
 	switch item {
 	case Host:
-		return db.db.Config().ConnConfig.Host
+		return pg.Pool.Config().ConnConfig.Host
 	case Name:
-		return db.db.Config().ConnConfig.Database
+		return pg.Pool.Config().ConnConfig.Database
 	case Password:
-		return db.db.Config().ConnConfig.Password
+		return pg.Pool.Config().ConnConfig.Password
 	case Port:
-		return strconv.Itoa(int(db.db.Config().ConnConfig.Port))
+		return strconv.Itoa(int(pg.Pool.Config().ConnConfig.Port))
 	case UserName:
-		return db.db.Config().ConnConfig.User
+		return pg.Pool.Config().ConnConfig.User
 	default:
 		return ""
 	}
@@ -13,6 +13,7 @@ type Overlap struct {
 	User     string
 	SlackID  string
 	OS       string
+	Model    string
 	TZOffset int64
 	FullName string
 }
@@ -101,7 +102,7 @@ func (d *Devices) DevicesByUser(user string) (devices.DI, error) {
 // DeviceUserOverlap returns a list of devices that have a user name and a slack id
 func (d *Devices) DeviceUserOverlap() ([]Overlap, error) {
 	query, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
-		Select("user_name, user_long_name, serial_number, user_slack_id, os_version, tz_offset").
+		Select("user_name, user_long_name, serial_number, user_slack_id, os_version, model, tz_offset").
 		From("devices d").
 		Join("users ON (user_email = d.user_name)").
 		Where(sq.And{sq.NotEq{"user_name": ""}, sq.NotEq{"user_slack_id": ""}}).
@@ -112,10 +113,17 @@ func (d *Devices) DeviceUserOverlap() ([]Overlap, error) {
 		return nil, fmt.Errorf("device overlap query failed %w", err)
 	}
 
-	rows, err := d.db.Query(context.Background(), query, args...)
+	conn, err := d.db.Acquire(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("acquiring connection failed %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(context.Background(), query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("bot response query failed %w", err)
 	}
+	defer rows.Close()
 
 	resp := []Overlap{}
 	for rows.Next() {
@@ -127,6 +135,7 @@ func (d *Devices) DeviceUserOverlap() ([]Overlap, error) {
 			&br.Serial,
 			&br.SlackID,
 			&br.OS,
+			&br.Model,
 			&br.TZOffset,
 		)
 
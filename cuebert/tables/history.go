@@ -0,0 +1,71 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/policy"
+	"github.com/johnmikee/cuebert/db/bot"
+	"github.com/johnmikee/cuebert/db/compare"
+	"github.com/johnmikee/cuebert/db/history"
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+)
+
+type History = Config
+
+// ArchiveResults records the results of devices that now meet the policy in
+// compliance_history. The os version comes from the mdm snapshot and the
+// required version from the rule the device matched.
+func (c *History) ArchiveResults(results bot.BR, md mdm.DeviceResults, p *policy.Policy) error {
+	h := archive(results, md, p, helpers.UpdateTime())
+	if h.Empty() {
+		return nil
+	}
+
+	_, err := c.hs(c.db, &c.log).AddAll(h)
+
+	return err
+}
+
+// archive builds the history rows for the results.
+func archive(results bot.BR, md mdm.DeviceResults, p *policy.Policy, now time.Time) history.HI {
+	devices := make(map[string]mdm.Device, len(md))
+	for i := range md {
+		devices[md[i].SerialNumber] = md[i]
+	}
+
+	h := make(history.HI, 0, len(results))
+	for i := range results {
+		d := devices[results[i].SerialNumber]
+
+		h = append(h, history.Info{
+			SerialNumber:         results[i].SerialNumber,
+			CampaignID:           results[i].CampaignID,
+			SlackID:              results[i].SlackID,
+			UserEmail:            results[i].UserEmail,
+			FullName:             results[i].FullName,
+			RequiredOS:           p.Match(d.Model, d.OSVersion).Minimum,
+			OSVersion:            d.OSVersion,
+			FirstMessageSentAt:   results[i].FirstMessageSentAt,
+			FirstACKTime:         results[i].FirstACKTime,
+			ManagerMessageSentAt: results[i].ManagerMessageSentAt,
+			CompletedAt:          now,
+		})
+	}
+
+	return h
+}
+
+// HistoryByEmail returns the archived results for the users devices.
+func (c *History) HistoryByEmail(email string) (history.HI, error) {
+	return c.hs(c.db, &c.log).Query().UserEmail(email).Query()
+}
+
+// HistorySince returns the devices that were completed after the time. The
+// time is passed without a zone so sqlite compares it as text the same way
+// postgres compares the timestamp.
+func (c *History) HistorySince(t time.Time) (history.HI, error) {
+	return c.hs(c.db, &c.log).Query().
+		Completed(t.UTC().Format("2006-01-02 15:04:05"), compare.GreaterThanOrEqual).
+		Query()
+}
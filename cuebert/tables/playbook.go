@@ -0,0 +1,32 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/johnmikee/cuebert/db/playbook"
+)
+
+type Playbook = Config
+
+// CompleteStep records that the escalation step ran for the serial.
+func (p *Playbook) CompleteStep(serial, step string, at time.Time) error {
+	_, err := p.pb(p.db, &p.log).Add().
+		SerialNumber(serial).
+		Step(step).
+		CompletedAt(at).
+		Execute()
+
+	return err
+}
+
+// CompletedSteps returns the escalation steps that ran for the serials.
+func (p *Playbook) CompletedSteps(serial ...string) (playbook.PI, error) {
+	return p.pb(p.db, &p.log).Query().Serial(serial...).Query()
+}
+
+// RemoveSteps removes the completed escalation steps for the serial.
+func (p *Playbook) RemoveSteps(serial string) error {
+	_, err := p.pb(p.db, &p.log).Remove().Serial(serial).Execute()
+
+	return err
+}
@@ -8,6 +8,7 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/johnmikee/cuebert/cuebert/device"
+	"github.com/johnmikee/cuebert/cuebert/policy"
 	"github.com/johnmikee/cuebert/cuebert/user"
 	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/db/bot"
@@ -22,16 +23,22 @@ import (
 type Check struct {
 	Serial string
 	OS     string
+	Model  string
+}
+
+// Compliance reports if a device meets the version it is required to run.
+type Compliance interface {
+	Compliant(model, osVersion string) bool
 }
 
 type DBClient struct {
-	DB     *db.DB
+	DB     db.Store
 	Log    logger.Logger
 	Device *device.Device
 	User   *user.User
 }
 
-func (c *Config) InitTables(reqVers string) ([]string, error) {
+func (c *Config) InitTables(p *policy.Policy) ([]string, error) {
 	check, err := c.users.AddAllUsers()
 	if err != nil {
 		c.log.Err(err).Msg("could not add user table")
@@ -43,14 +50,17 @@ func (c *Config) InitTables(reqVers string) ([]string, error) {
 	}
 
 	// build bot_results table. we need this built before we can do anything else
-	c.BuildBotResTable(reqVers)
+	c.BuildBotResTable(p)
 
 	return check, err
 }
 
 // buildBotResTable builds the bot results table. this is used to track
-// the users that need to be reminded to update their devices.
-func (c *Config) BuildBotResTable(reqVers string) {
+// the users that need to be reminded to update their devices. each device
+// is checked against the policy rule matching its model and os version.
+// devices that already have a result only have the user details refreshed
+// so the acknowledgement and reminder state survives a rebuild.
+func (c *Config) BuildBotResTable(p *policy.Policy) {
 	updates := bot.BR{}
 
 	br, err := c.DeviceUserOverlap()
@@ -60,15 +70,12 @@ func (c *Config) BuildBotResTable(reqVers string) {
 	}
 
 	for i := range br {
-		ok, err := helpers.CompareOSVer(br[i].OS, reqVers)
-		if err != nil {
-			c.log.Err(err).Msg("could not compare os versions")
-		}
-
-		if !ok {
+		rule := p.Match(br[i].Model, br[i].OS)
+		if !rule.Compliant(br[i].OS) {
 			c.log.Debug().
 				Str("serial", br[i].Serial).
 				Str("os", br[i].OS).
+				Str("rule", rule.String()).
 				Msg("needs update")
 
 			u := bot.Info{
@@ -79,6 +86,7 @@ func (c *Config) BuildBotResTable(reqVers string) {
 				FirstACKTime: time.Time{},
 				DelayAt:      time.Time{},
 				TZOffset:     br[i].TZOffset,
+				CampaignID:   c.campaign,
 			}
 			updates = append(updates, u)
 		}
@@ -91,7 +99,7 @@ func (c *Config) BuildBotResTable(reqVers string) {
 }
 
 // we take the users from the iDP and compare them to the users in the database.
-func (c *Config) AddCheckMissing(idpUsers []idp.User, users []string, sc *slack.Client) {
+func (c *Config) AddCheckMissing(ctx context.Context, idpUsers []idp.User, users []string, sc *slack.Client) {
 	emails := make([]string, len(idpUsers))
 	emailProfileMap := make(map[string]idp.User)
 	for i := range idpUsers {
@@ -103,7 +111,7 @@ func (c *Config) AddCheckMissing(idpUsers []idp.User, users []string, sc *slack.
 		c.log.Trace().Str("user", users[u]).Msg("checking user")
 		if helpers.Contains(emails, users[u]) {
 			// now we need their slackID
-			su, err := sc.GetUserByEmail(users[u])
+			su, err := sc.GetUserByEmailContext(ctx, users[u])
 			c.log.Trace().Str("email", users[u]).Msg("getting user by email")
 
 			if err != nil {
@@ -161,6 +169,7 @@ func (c *Config) GatherDiffDevicesDB() ([]string, []Check, error) {
 		versCheck = append(versCheck, Check{
 			Serial: dbd[i].SerialNumber,
 			OS:     dbd[i].OSVersion,
+			Model:  dbd[i].Model,
 		})
 	}
 
@@ -186,6 +195,25 @@ func (c *Config) IsExcluded(serial string) (bool, bool) {
 	return false, false
 }
 
+// ExclusionActive reports if the serial has an approved exclusion that has
+// not expired. An exclusion without an end date never expires. Callers acting
+// on the device should skip it when an error is returned since the exclusion
+// could not be ruled out.
+func (c *Config) ExclusionActive(serial string, now time.Time) (bool, error) {
+	ex, err := c.SerialExcluded(serial)
+	if err != nil {
+		return false, fmt.Errorf("checking exclusions for %s: %w", serial, err)
+	}
+
+	for i := range ex {
+		if ex[i].Approved && (ex[i].Until.IsZero() || ex[i].Until.After(now)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // UpdateReminderTime updates the reminder time in the db.
 func (c *Config) UpdateReminderTime(dv, tv, sid string) {
 	dateString := fmt.Sprintf("%s %s", dv, tv)
@@ -201,6 +229,12 @@ func (c *Config) UpdateReminderTime(dv, tv, sid string) {
 }
 
 func (c *Config) delete(tables []string) error {
+	conn, err := c.db.Acquire(context.Background())
+	if err != nil {
+		return fmt.Errorf("acquiring connection failed: %s", err)
+	}
+	defer conn.Release()
+
 	for _, t := range tables {
 		if !helpers.Contains(db.CueTables, t) {
 			return fmt.Errorf("%s is not a table", t)
@@ -220,7 +254,7 @@ func (c *Config) delete(tables []string) error {
 			return fmt.Errorf("building delete query for %s failed: %s", t, err)
 		}
 
-		_, err = c.db.Exec(context.Background(), query, args...)
+		_, err = conn.Exec(context.Background(), query, args...)
 		if err != nil {
 			return fmt.Errorf("deleting table %s failed: %s", t, err)
 		}
@@ -230,27 +264,15 @@ func (c *Config) delete(tables []string) error {
 }
 
 // checkStaleDevices checks for devices that no longer need to be in the DB.
-// If the OS on the device is greater than the required version, it will be removed.
-func CheckStaleDevices(reqVers string, versCheck []Check, md mdm.DeviceResults) []string {
+// If the OS the MDM reports for the device meets the policy rule matching the
+// device, it will be removed.
+func CheckStaleDevices(p Compliance, versCheck []Check, md mdm.DeviceResults) []string {
 	remove := []string{}
 
 	for i := range md {
 		for _, x := range versCheck {
-			if md[i].SerialNumber == x.Serial {
-				ok, _ := helpers.CompareOSVer(x.OS, md[i].OSVersion)
-				// if the MDM version is greater than the DB version
-				// double check that its not above the required
-				if ok {
-					doubleCheck, _ := helpers.CompareOSVer(reqVers, x.OS)
-					if !doubleCheck {
-						remove = append(remove, x.Serial)
-					}
-				} else {
-					ok, _ := helpers.CompareOSVer(reqVers, x.OS)
-					if ok {
-						remove = append(remove, x.Serial)
-					}
-				}
+			if md[i].SerialNumber == x.Serial && p.Compliant(md[i].Model, md[i].OSVersion) {
+				remove = append(remove, x.Serial)
 			}
 		}
 	}
@@ -0,0 +1,357 @@
+package tables
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/cuebert/policy"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/db/audit"
+	"github.com/johnmikee/cuebert/db/campaigns"
+	"github.com/johnmikee/cuebert/db/devices"
+	"github.com/johnmikee/cuebert/db/sqlite"
+	"github.com/johnmikee/cuebert/db/users"
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// newTestTables returns tables stored in a new sqlite database with two
+// users, one on an old os, and their devices.
+func newTestTables(t *testing.T) *Config {
+	t.Helper()
+
+	store, err := sqlite.Open(filepath.Join(t.TempDir(), "cue.db"))
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	t.Cleanup(store.Close)
+
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+
+	_, err = users.User(store, &log).AddAllUsers(users.UI{
+		{MDMID: "1", UserLongName: "Old Mac", UserEmail: "old@example.com", UserSlackID: "U1", TZOffset: -25200},
+		{MDMID: "2", UserLongName: "New Mac", UserEmail: "new@example.com", UserSlackID: "U2"},
+	})
+	if err != nil {
+		t.Fatalf("adding users: %v", err)
+	}
+
+	_, err = devices.Device(store, &log).AddAllDevices(devices.DI{
+		{DeviceID: "d1", DeviceName: "old", Model: "Mac14,2", SerialNumber: "S1", Platform: "Mac", OSVersion: "13.3", User: "old@example.com", UserMDMID: "1"},
+		{DeviceID: "d2", DeviceName: "new", Model: "Mac14,2", SerialNumber: "S2", Platform: "Mac", OSVersion: "13.4.1", User: "new@example.com", UserMDMID: "2"},
+	})
+	if err != nil {
+		t.Fatalf("adding devices: %v", err)
+	}
+
+	return New(store, &log)
+}
+
+func TestBuildBotResTable(t *testing.T) {
+	c := newTestTables(t)
+	p := policy.New("13.4.1", "06-30-2023")
+
+	c.BuildBotResTable(p)
+
+	br, err := c.GetBotTableInfo()
+	if err != nil {
+		t.Fatalf("getting bot results: %v", err)
+	}
+	if len(br) != 1 || br[0].SerialNumber != "S1" {
+		t.Fatalf("bot results = %+v, want only S1", br)
+	}
+	if br[0].SlackID != "U1" || br[0].TZOffset != -25200 {
+		t.Errorf("bot result = %+v, want slack id U1 and offset -25200", br[0])
+	}
+
+	ack := time.Date(2023, time.June, 1, 9, 0, 0, 0, time.UTC)
+	if err := c.ACKACKD("U1", ack); err != nil {
+		t.Fatalf("acknowledging: %v", err)
+	}
+
+	// rebuilding refreshes the row without losing the acknowledgement.
+	c.BuildBotResTable(p)
+
+	br, err = c.GetBotTableInfo()
+	if err != nil {
+		t.Fatalf("getting bot results: %v", err)
+	}
+	if len(br) != 1 {
+		t.Fatalf("got %d bot results after rebuild, want 1", len(br))
+	}
+	if !br[0].FirstACK || !br[0].FirstACKTime.Equal(ack) {
+		t.Errorf("ack = %v at %v, want true at %v", br[0].FirstACK, br[0].FirstACKTime, ack)
+	}
+}
+
+func TestCampaignScope(t *testing.T) {
+	c := newTestTables(t)
+
+	c.ForCampaign("default").BuildBotResTable(policy.New("13.4.1", "06-30-2023"))
+	c.ForCampaign("sonoma").BuildBotResTable(policy.New("14.0", "06-30-2023"))
+
+	all, err := c.GetBotTableInfo()
+	if err != nil {
+		t.Fatalf("getting bot results: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d bot results, want 3", len(all))
+	}
+
+	sonoma, err := c.ForCampaign("sonoma").GetBotTableInfo()
+	if err != nil {
+		t.Fatalf("getting campaign results: %v", err)
+	}
+	if len(sonoma) != 2 {
+		t.Fatalf("got %d sonoma results, want 2", len(sonoma))
+	}
+
+	_, err = c.ForCampaign("sonoma").RemoveBRBy().Serial("S1").Execute()
+	if err != nil {
+		t.Fatalf("removing result: %v", err)
+	}
+
+	def, err := c.ForCampaign("default").GetBotTableInfo()
+	if err != nil {
+		t.Fatalf("getting default results: %v", err)
+	}
+	if len(def) != 1 || def[0].SerialNumber != "S1" {
+		t.Errorf("default results = %+v, want S1 to be kept", def)
+	}
+}
+
+func TestOpenCampaign(t *testing.T) {
+	c := newTestTables(t)
+
+	info := &campaigns.Info{
+		ID:         campaigns.Default,
+		Name:       campaigns.Default,
+		RequiredOS: "13.4.1",
+		Deadline:   "06-30-2023",
+		CutoffTime: "17:00",
+		Status:     campaigns.Active,
+	}
+	if err := c.OpenCampaign(info); err != nil {
+		t.Fatalf("opening campaign: %v", err)
+	}
+	if err := c.SetCampaignStatus(campaigns.Default, campaigns.Paused); err != nil {
+		t.Fatalf("pausing campaign: %v", err)
+	}
+
+	// the routines start again with a new deadline.
+	info.Deadline = "07-30-2023"
+	if err := c.OpenCampaign(info); err != nil {
+		t.Fatalf("reopening campaign: %v", err)
+	}
+
+	cs, err := c.CampaignByID(campaigns.Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 1 || cs[0].Status != campaigns.Paused || cs[0].Deadline != "07-30-2023" {
+		t.Errorf("got campaigns %+v, want the paused campaign with the new deadline", cs)
+	}
+}
+
+func TestExclusions(t *testing.T) {
+	c := newTestTables(t)
+	until := time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := c.SetExclusion("S1", "travelling", until); err != nil {
+		t.Fatalf("requesting exclusion: %v", err)
+	}
+
+	if err := c.ApproveExclusion("travelling", "S1", until); err != nil {
+		t.Fatalf("approving exclusion: %v", err)
+	}
+
+	ex, err := c.SerialExcluded("S1")
+	if err != nil {
+		t.Fatalf("getting exclusion: %v", err)
+	}
+	if len(ex) != 1 || !ex[0].Approved || ex[0].UserEmail != "old@example.com" || !ex[0].Until.Equal(until) {
+		t.Errorf("exclusion = %+v, want an approved exclusion for old@example.com until %v", ex, until)
+	}
+}
+
+// failingStore is a store whose queries fail, ex: the database went away.
+type failingStore struct{ db.Store }
+
+func (s failingStore) Acquire(ctx context.Context) (db.Conn, error) {
+	conn, err := s.Store.Acquire(ctx)
+	return failingConn{conn}, err
+}
+
+type failingConn struct{ db.Conn }
+
+func (failingConn) Query(context.Context, string, ...any) (db.Rows, error) {
+	return nil, errors.New("connection reset")
+}
+
+func TestExclusionActive(t *testing.T) {
+	c := newTestTables(t)
+	now := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := c.AddExclusion("S1", "lab", now.AddDate(0, 1, 0)); err != nil {
+		t.Fatalf("adding exclusion: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		serial string
+		now    time.Time
+		want   bool
+	}{
+		{"active", "S1", now, true},
+		{"expired", "S1", now.AddDate(0, 2, 0), false},
+		{"none", "S2", now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ExclusionActive(tt.serial, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ExclusionActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// a failed lookup must not read as "not excluded" or the device would be
+	// enforced on.
+	failing := New(failingStore{c.db}, &c.log)
+	if _, err := failing.ExclusionActive("S1", now); err == nil {
+		t.Error("ExclusionActive() with a failing store did not return an error")
+	}
+}
+
+func TestArchiveResults(t *testing.T) {
+	c := newTestTables(t)
+	p := policy.New("13.4.1", "06-30-2023")
+
+	c.BuildBotResTable(p)
+
+	br, err := c.GetBotTableInfo()
+	if err != nil {
+		t.Fatalf("getting bot results: %v", err)
+	}
+
+	md := mdm.DeviceResults{{SerialNumber: "S1", Model: "Mac14,2", OSVersion: "13.4.1"}}
+	if err := c.ArchiveResults(br, md, p); err != nil {
+		t.Fatalf("archiving: %v", err)
+	}
+
+	h, err := c.HistorySince(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("getting history: %v", err)
+	}
+	if len(h) != 1 {
+		t.Fatalf("got %d archived rows, want 1", len(h))
+	}
+	if h[0].UserEmail != "old@example.com" || h[0].OSVersion != "13.4.1" || h[0].RequiredOS != "13.4.1" {
+		t.Errorf("archived row = %+v", h[0])
+	}
+
+	h, err = c.HistorySince(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("getting history: %v", err)
+	}
+	if len(h) != 0 {
+		t.Errorf("got %d rows completed in the future, want 0", len(h))
+	}
+}
+
+func TestDeleteTables(t *testing.T) {
+	c := newTestTables(t)
+
+	if err := c.DeleteTables("devices,users"); err != nil {
+		t.Fatalf("deleting tables: %v", err)
+	}
+
+	d, err := c.GetAllDevices()
+	if err != nil {
+		t.Fatalf("getting devices: %v", err)
+	}
+	if len(d) != 0 {
+		t.Errorf("got %d devices after delete, want 0", len(d))
+	}
+
+	if err := c.DeleteTables("not_a_table"); err == nil {
+		t.Error("deleting an unknown table did not fail")
+	}
+}
+
+func TestAudit(t *testing.T) {
+	c := newTestTables(t)
+
+	before := map[string]string{"reason": "old"}
+	after := map[string]string{"reason": "lab"}
+	if err := c.Record("U1", audit.ExclusionApproved, "U2", "S1", before, after); err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+	if err := c.Record(audit.System, audit.ReminderDelivered, "U2", "S2", nil, nil); err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+
+	a, err := c.AuditByUser("U1")
+	if err != nil {
+		t.Fatalf("getting audit by user: %v", err)
+	}
+	if len(a) != 1 || a[0].Action != audit.ExclusionApproved {
+		t.Fatalf("audit by actor = %+v, want the approval", a)
+	}
+	if a[0].Before != `{"reason":"old"}` || a[0].After != `{"reason":"lab"}` {
+		t.Errorf("audit state = %q, %q", a[0].Before, a[0].After)
+	}
+
+	a, err = c.AuditByUser("U2")
+	if err != nil {
+		t.Fatalf("getting audit by user: %v", err)
+	}
+	if len(a) != 2 {
+		t.Errorf("got %d events for the target user, want 2", len(a))
+	}
+
+	a, err = c.AuditBySerial("S2")
+	if err != nil {
+		t.Fatalf("getting audit by serial: %v", err)
+	}
+	if len(a) != 1 || a[0].Actor != audit.System || a[0].Before != "" {
+		t.Errorf("audit by serial = %+v", a)
+	}
+
+	a, err = c.AuditSince(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("getting audit since: %v", err)
+	}
+	if len(a) != 2 {
+		t.Errorf("got %d events in the last hour, want 2", len(a))
+	}
+
+	a, err = c.AuditSince(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("getting audit since: %v", err)
+	}
+	if len(a) != 0 {
+		t.Errorf("got %d events in the future, want 0", len(a))
+	}
+
+	// the log is append only.
+	conn, err := c.db.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquiring: %v", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(context.Background(), "DELETE FROM audit_events"); err == nil {
+		t.Error("deleting audit events succeeded, want an error")
+	}
+	if _, err := conn.Exec(context.Background(), "UPDATE audit_events SET actor = 'U3'"); err == nil {
+		t.Error("updating audit events succeeded, want an error")
+	}
+}
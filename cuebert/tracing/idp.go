@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/johnmikee/cuebert/idp"
+)
+
+// idpProvider adds a span for each call to the provider.
+type idpProvider struct {
+	idp.Provider
+	ctx context.Context
+}
+
+// IDP returns p with its calls traced as children of the span in ctx. The
+// provider is bound to the span of each call so the requests it makes are
+// nested under it.
+func IDP(ctx context.Context, p idp.Provider) idp.Provider {
+	return &idpProvider{Provider: p, ctx: ctx}
+}
+
+// WithContext implements idp.Binder.
+func (i *idpProvider) WithContext(ctx context.Context) idp.Provider {
+	return IDP(ctx, i.Provider)
+}
+
+// GetAdminGroup implements idp.Provider.
+func (i *idpProvider) GetAdminGroup(group string) ([]string, error) {
+	ctx, span := Start(i.ctx, "idp GetAdminGroup")
+	g, err := idp.WithContext(i.Provider, ctx).GetAdminGroup(group)
+	End(span, err)
+
+	return g, err
+}
+
+// GetAllUsers implements idp.Provider.
+func (i *idpProvider) GetAllUsers() ([]idp.User, error) {
+	ctx, span := Start(i.ctx, "idp GetAllUsers")
+	u, err := idp.WithContext(i.Provider, ctx).GetAllUsers()
+	End(span, err)
+
+	return u, err
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/johnmikee/cuebert/mdm"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// mdmProvider adds a span for each call to the provider.
+type mdmProvider struct {
+	mdm.Provider
+	ctx context.Context
+}
+
+// MDM returns p with its calls traced as children of the span in ctx. The
+// provider is bound to the span of each call so the requests it makes are
+// nested under it.
+func MDM(ctx context.Context, p mdm.Provider) mdm.Provider {
+	return &mdmProvider{Provider: p, ctx: ctx}
+}
+
+// Unwrap implements mdm.Wrapper. The provider is bound to the context so the
+// requests of an Enforcer found through it are still nested.
+func (m *mdmProvider) Unwrap() mdm.Provider {
+	return mdm.WithContext(m.Provider, m.ctx)
+}
+
+// WithContext implements mdm.Binder.
+func (m *mdmProvider) WithContext(ctx context.Context) mdm.Provider {
+	return MDM(ctx, m.Provider)
+}
+
+// ListDevices implements mdm.Provider.
+func (m *mdmProvider) ListDevices() ([]mdm.Device, error) {
+	ctx, span := Start(m.ctx, "mdm ListDevices")
+	d, err := mdm.WithContext(m.Provider, ctx).ListDevices()
+	End(span, err)
+
+	return d, err
+}
+
+// GetDevice implements mdm.Provider.
+func (m *mdmProvider) GetDevice(deviceID string) (*mdm.Device, error) {
+	ctx, span := Start(m.ctx, "mdm GetDevice")
+	d, err := mdm.WithContext(m.Provider, ctx).GetDevice(deviceID)
+	End(span, err)
+
+	return d, err
+}
+
+// QueryDevices implements mdm.Provider.
+func (m *mdmProvider) QueryDevices(opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
+	ctx, span := Start(m.ctx, "mdm QueryDevices")
+	d, err := mdm.WithContext(m.Provider, ctx).QueryDevices(opts)
+	End(span, err)
+
+	return d, err
+}
+
+// GetUsers implements mdm.Provider.
+func (m *mdmProvider) GetUsers(opts *mdm.QueryOpts) ([]mdm.User, error) {
+	ctx, span := Start(m.ctx, "mdm GetUsers")
+	u, err := mdm.WithContext(m.Provider, ctx).GetUsers(opts)
+	End(span, err)
+
+	return u, err
+}
+
+// enforcer adds a span for each action sent to the MDM.
+type enforcer struct {
+	p   mdm.Provider
+	ctx context.Context
+}
+
+// Enforcer returns the Enforcer of p with its actions traced as children of
+// the span in ctx, and false if the provider cannot enforce.
+func Enforcer(ctx context.Context, p mdm.Provider) (mdm.Enforcer, bool) {
+	if _, ok := mdm.AsEnforcer(p); !ok {
+		return nil, false
+	}
+
+	return &enforcer{p: p, ctx: ctx}, true
+}
+
+// Enforce implements mdm.Enforcer.
+func (e *enforcer) Enforce(action mdm.Action, device *mdm.Device, opts *mdm.EnforceOpts) error {
+	ctx, span := Start(e.ctx, "mdm Enforce",
+		attribute.String("mdm.action", string(action)),
+		attribute.String("device.serial", device.SerialNumber),
+	)
+
+	en, _ := mdm.AsEnforcer(mdm.WithContext(e.p, ctx))
+	err := en.Enforce(action, device, opts)
+	End(span, err)
+
+	return err
+}
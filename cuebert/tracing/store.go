@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/johnmikee/cuebert/db"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// store adds a span for every statement run on its connections. Statements
+// run with a context holding no span are children of the context the store
+// is bound to.
+type store struct {
+	db.Store
+	parent context.Context
+}
+
+// Store returns s with a span for every statement.
+func Store(s db.Store) db.Store {
+	return &store{Store: s, parent: context.Background()}
+}
+
+// Unwrap implements db.Wrapper.
+func (s *store) Unwrap() db.Store {
+	return s.Store
+}
+
+// WithContext implements db.Binder.
+func (s *store) WithContext(ctx context.Context) db.Store {
+	return &store{Store: s.Store, parent: ctx}
+}
+
+// Acquire implements db.Store.
+func (s *store) Acquire(ctx context.Context) (db.Conn, error) {
+	c, err := s.Store.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{Conn: c, parent: s.parent, driver: s.Driver()}, nil
+}
+
+type conn struct {
+	db.Conn
+	parent context.Context
+	driver string
+}
+
+// start starts the span of the statement under the span in ctx or, if there
+// is none, under the span the store was bound to.
+func (c *conn) start(ctx context.Context, sql string) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.ContextWithSpan(ctx, trace.SpanFromContext(c.parent))
+	}
+
+	return Start(ctx, "db "+operation(sql),
+		attribute.String("db.system", c.driver),
+		attribute.String("db.statement", sql),
+	)
+}
+
+// Exec implements db.Conn.
+func (c *conn) Exec(ctx context.Context, sql string, args ...any) (db.Result, error) {
+	ctx, span := c.start(ctx, sql)
+	res, err := c.Conn.Exec(ctx, sql, args...)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", res.RowsAffected()))
+	}
+	End(span, err)
+
+	return res, err
+}
+
+// Query implements db.Conn. The span ends once the query returns, before the
+// rows are read.
+func (c *conn) Query(ctx context.Context, sql string, args ...any) (db.Rows, error) {
+	ctx, span := c.start(ctx, sql)
+	rows, err := c.Conn.Query(ctx, sql, args...)
+	End(span, err)
+
+	return rows, err
+}
+
+// Batch implements db.Conn.
+func (c *conn) Batch(ctx context.Context, stmts []db.Statement) (int64, error) {
+	sql := ""
+	if len(stmts) > 0 {
+		sql = stmts[0].SQL
+	}
+
+	ctx, span := c.start(ctx, sql)
+	span.SetAttributes(attribute.Int("db.batch_size", len(stmts)))
+	n, err := c.Conn.Batch(ctx, stmts)
+	End(span, err)
+
+	return n, err
+}
+
+// operation returns the first keyword of the statement, ex: SELECT.
+func operation(sql string) string {
+	op, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+
+	return strings.ToUpper(op)
+}
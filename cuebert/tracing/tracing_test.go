@@ -0,0 +1,146 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/db/sqlite"
+	"github.com/johnmikee/cuebert/mdm"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	return sr
+}
+
+func TestStore(t *testing.T) {
+	sr := newRecorder(t)
+
+	s, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	traced := Store(s)
+
+	if _, ok := traced.(db.Wrapper); !ok {
+		t.Fatal("store does not implement db.Wrapper")
+	}
+
+	ctx, root := Start(context.Background(), "check")
+
+	conn, err := db.WithContext(traced, ctx).Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(context.Background(), "create table t (id integer)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.End()
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	stmt := spans[0]
+	if stmt.Name() != "db CREATE" {
+		t.Errorf("got span %q, want db CREATE", stmt.Name())
+	}
+	if stmt.Parent().SpanID() != root.SpanContext().SpanID() {
+		t.Error("statement span is not a child of the bound span")
+	}
+}
+
+// httpProvider lists devices with a request made with the context it is
+// bound to, like the providers do.
+type httpProvider struct {
+	mdm.Provider
+	url string
+	ctx context.Context
+}
+
+func (p *httpProvider) WithContext(ctx context.Context) mdm.Provider {
+	return &httpProvider{url: p.url, ctx: ctx}
+}
+
+func (p *httpProvider) ListDevices() ([]mdm.Device, error) {
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodGet, p.url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := (&http.Client{Transport: Transport(http.DefaultTransport)}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return nil, nil
+}
+
+func TestMDM(t *testing.T) {
+	sr := newRecorder(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer ts.Close()
+
+	ctx, root := Start(context.Background(), "deviceDiff")
+	_, err := MDM(ctx, &httpProvider{url: ts.URL, ctx: context.Background()}).ListDevices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.End()
+
+	spans := sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+
+	req, call := spans[0], spans[1]
+	if call.Name() != "mdm ListDevices" {
+		t.Errorf("got span %q, want mdm ListDevices", call.Name())
+	}
+	if call.Parent().SpanID() != root.SpanContext().SpanID() {
+		t.Error("mdm span is not a child of the routine span")
+	}
+	if req.Parent().SpanID() != call.SpanContext().SpanID() {
+		t.Errorf("request span %q is not a child of the mdm span", req.Name())
+	}
+}
+
+func TestOperation(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT * FROM users", "SELECT"},
+		{"\n\tinsert into devices values ($1)", "INSERT"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := operation(tt.sql); got != tt.want {
+			t.Errorf("operation(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
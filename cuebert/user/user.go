@@ -16,7 +16,7 @@ import (
 // User is the internal struct for the user package
 type User struct {
 	sc     *slack.Client
-	db     *db.DB
+	db     db.Store
 	client mdm.Provider
 	log    logger.Logger
 }
@@ -26,7 +26,7 @@ type User struct {
 type Config struct {
 	Slack  *slack.Client
 	Client mdm.Provider
-	DB     *db.DB
+	DB     db.Store
 	Log    *logger.Logger
 }
 
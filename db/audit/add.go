@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/pkg/errors"
+)
+
+// Add appends the event. created_at defaults to now when unset. events are
+// never updated or removed once written.
+func (c *Config) Add(a *Info) error {
+	defer c.db.Release()
+
+	created := a.CreatedAt
+	if created.IsZero() {
+		created = helpers.UpdateTime()
+	}
+
+	query, args, err := c.st.Insert(table).
+		Columns(columns...).
+		Values(
+			a.Actor,
+			string(a.Action),
+			a.TargetUser,
+			a.TargetSerial,
+			a.Before,
+			a.After,
+			created,
+		).
+		ToSql()
+
+	c.log.Trace().Str("query", query).Interface("args", args).Msg("composed sql")
+
+	if err != nil {
+		return errors.Wrap(err, "failed to build insert statement")
+	}
+
+	_, err = c.db.Exec(c.ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute query")
+	}
+
+	return nil
+}
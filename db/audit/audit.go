@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Info represents the columns in the audit_events table
+type Info struct {
+	ID           int64     `json:"id"`
+	Actor        string    `json:"actor"`
+	Action       Action    `json:"action"`
+	TargetUser   string    `json:"target_user"`
+	TargetSerial string    `json:"target_serial"`
+	Before       string    `json:"before"`
+	After        string    `json:"after"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type AI []Info
+
+func (a AI) Empty() bool {
+	return len(a) == 0
+}
+
+// Action is what was done.
+type Action string
+
+const (
+	ExclusionRequested Action = "exclusion_requested"
+	ExclusionAdded     Action = "exclusion_added"
+	ExclusionApproved  Action = "exclusion_approved"
+	ExclusionDenied    Action = "exclusion_denied"
+	ExclusionRevoked   Action = "exclusion_revoked"
+	StopApproved       Action = "stop_approved"
+	StopDenied         Action = "stop_denied"
+	StopOverride       Action = "stop_override"
+	Started            Action = "started"
+	Stopped            Action = "stopped"
+	ConfigUpdated      Action = "config_updated"
+	FirstMessageSent   Action = "first_message_sent"
+	ReminderDelivered  Action = "reminder_delivered"
+	ManagerMessageSent Action = "manager_message_sent"
+)
+
+// System is the actor recorded for actions the bot takes on its own.
+const System = "cuebert"
+
+type Config struct {
+	db  db.Conn
+	ctx context.Context
+	log logger.Logger
+	st  sq.StatementBuilderType
+}
+
+const table = "audit_events"
+
+// columns are the columns written on insert. the id is generated.
+var columns = []string{
+	"actor",
+	"action",
+	"target_user",
+	"target_serial",
+	"before_state",
+	"after_state",
+	"created_at",
+}
+
+// Audit returns a new client used to interact with the audit_events table
+func Audit(d db.Store, l *logger.Logger) *Config {
+	conn, err := d.Acquire(context.Background())
+	if err != nil {
+		l.Info().AnErr("acquiring connection", err).Msg("failed to acquire lock")
+		return nil
+	}
+
+	return &Config{
+		ctx: context.Background(),
+		db:  conn,
+		log: logger.ChildLogger("db/audit", l),
+		st:  sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/db/compare"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Query holds the configuration for the building and executing the query.
+type Query struct {
+	db  db.Conn
+	log logger.Logger
+	sql sq.SelectBuilder
+	st  sq.StatementBuilderType
+}
+
+// Query returns a new client used to interact with specific columns
+// in the audit_events table.
+func (c *Config) Query() *Query {
+	return &Query{
+		db:  c.db,
+		log: c.log,
+		st:  c.st,
+	}
+}
+
+// Query executes the query against the db with built query. events are
+// returned oldest first.
+func (q *Query) Query() (AI, error) {
+	defer q.db.Release()
+
+	sql, args, err := q.sql.OrderBy("created_at", "id").ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("sql generation failed %w", err)
+	}
+
+	q.log.Trace().Str("query", sql).Msg("composed sql query")
+
+	events := []Info{}
+
+	rows, err := q.db.Query(
+		context.Background(),
+		sql, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("audit query failed %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			a      Info
+			action string
+		)
+
+		err = rows.Scan(
+			&a.ID,
+			&a.Actor,
+			&action,
+			&a.TargetUser,
+			&a.TargetSerial,
+			&a.Before,
+			&a.After,
+			&a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("audit row query failed %w", err)
+		}
+		a.Action = Action(action)
+		events = append(events, a)
+	}
+
+	return events, rows.Err()
+}
+
+func (q *Query) selectAll() sq.SelectBuilder {
+	return q.st.Select(append([]string{"id"}, columns...)...).From(table)
+}
+
+// All returns all events in the table
+func (q *Query) All() *Query {
+	q.sql = q.selectAll()
+
+	return q
+}
+
+// User queries the audit_events table for the events the users took or
+// that were taken on them.
+func (q *Query) User(user ...string) *Query {
+	q.sql = q.selectAll().Where(sq.Or{
+		sq.Eq{"actor": user},
+		sq.Eq{"target_user": user},
+	})
+
+	return q
+}
+
+// Serial queries the audit_events table for the events on the devices
+func (q *Query) Serial(serial ...string) *Query {
+	q.sql = q.selectAll().Where(sq.Eq{"target_serial": serial})
+
+	return q
+}
+
+// Action queries the audit_events table for the actions
+func (q *Query) Action(action ...Action) *Query {
+	a := make([]string, len(action))
+	for i := range action {
+		a[i] = string(action[i])
+	}
+	q.sql = q.selectAll().Where(sq.Eq{"action": a})
+
+	return q
+}
+
+// Created queries the audit_events table comparing created_at to the value
+func (q *Query) Created(created string, op compare.Compare) *Query {
+	q.sql = compare.Comparison(q.selectAll(), "created_at", created, op)
+
+	return q
+}
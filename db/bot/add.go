@@ -4,8 +4,7 @@ import (
 	"context"
 	"time"
 
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/pkg/errors"
 
 	sq "github.com/Masterminds/squirrel"
@@ -16,10 +15,11 @@ import (
 type Update struct {
 	args  []interface{}
 	bresp Info
-	db    *pgxpool.Conn
+	db    db.Conn
 	query string
 
-	st sq.StatementBuilderType
+	campaign string
+	st       sq.StatementBuilderType
 
 	ctx context.Context
 	log logger.Logger
@@ -32,51 +32,62 @@ type Update struct {
 // will be inserted once Execute is called.
 func (c *Config) Add() *Update {
 	return &Update{
-		db:  c.db,
-		ctx: context.Background(),
-		log: c.log,
-		st:  sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		db:       c.db,
+		ctx:      context.Background(),
+		log:      c.log,
+		st:       sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		campaign: c.campaignID(""),
 	}
 }
 
+// AddAllDevices adds a result for each device passed. Devices that already
+// have a result for the campaign only have the user details refreshed so the
+// acknowledgement, reminder and manager state is kept.
 func (c *Config) AddAllDevices(b []Info) (int64, error) {
-	rows := [][]interface{}{}
+	batch := []db.Statement{}
 	for i := range b {
-		row := []interface{}{
-			b[i].SlackID,
-			b[i].UserEmail,
-			b[i].ManagerSlackID,
-			b[i].FirstACK,
-			b[i].FirstACKTime,
-			b[i].FirstMessageSent,
-			b[i].FirstMessageSentAt,
-			b[i].FirstMessageWaiting,
-			b[i].ManagerMessageSent,
-			b[i].ManagerMessageSentAt,
-			b[i].FullName,
-			b[i].DelayAt,
-			b[i].DelayDate,
-			b[i].DelayTime,
-			b[i].DelaySent,
-			b[i].ReminderInterval,
-			b[i].ReminderWaiting,
-			b[i].SerialNumber,
-			b[i].TZOffset,
-			helpers.UpdateTime(),
-			helpers.UpdateTime(),
+		query, args, err := c.st.Insert(table).
+			Columns(columns...).
+			Values(
+				b[i].SlackID,
+				b[i].UserEmail,
+				b[i].ManagerSlackID,
+				b[i].FirstACK,
+				b[i].FirstACKTime,
+				b[i].FirstMessageSent,
+				b[i].FirstMessageSentAt,
+				b[i].FirstMessageWaiting,
+				b[i].ManagerMessageSent,
+				b[i].ManagerMessageSentAt,
+				b[i].FullName,
+				b[i].DelayAt,
+				b[i].DelayDate,
+				b[i].DelayTime,
+				b[i].DelaySent,
+				b[i].ReminderInterval,
+				b[i].ReminderWaiting,
+				b[i].SerialNumber,
+				b[i].TZOffset,
+				helpers.UpdateTime(),
+				helpers.UpdateTime(),
+				c.campaignID(b[i].CampaignID),
+			).
+			Suffix(`ON CONFLICT (serial_number, campaign_id) DO UPDATE SET
+	slack_id = EXCLUDED.slack_id,
+	user_email = EXCLUDED.user_email,
+	full_name = EXCLUDED.full_name,
+	tz_offset = EXCLUDED.tz_offset,
+	updated_at = EXCLUDED.updated_at`).
+			ToSql()
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to build sql statement")
 		}
-		rows = append(rows, row)
+		batch = append(batch, db.Statement{SQL: query, Args: args})
 	}
 
-	copyCount, err := c.db.CopyFrom(
-		context.Background(),
-		pgx.Identifier{table},
-		columns,
-		pgx.CopyFromRows(rows))
-
-	c.db.Release()
+	defer c.db.Release()
 
-	return copyCount, err
+	return c.db.Batch(context.Background(), batch)
 }
 
 // Execute sends the statement to add the bot result after it has been composed.
@@ -106,6 +117,8 @@ func (u *Update) Execute() error {
 			u.bresp.SerialNumber,
 			u.bresp.TZOffset,
 			helpers.UpdateTime(),
+			helpers.UpdateTime(),
+			u.campaignID(),
 		).
 		ToSql()
 
@@ -132,6 +145,23 @@ func (u *Update) Execute() error {
 	return nil
 }
 
+// campaignID returns the campaign set on the result or the one the client
+// is scoped to.
+func (u *Update) campaignID() string {
+	if u.bresp.CampaignID != "" {
+		return u.bresp.CampaignID
+	}
+
+	return u.campaign
+}
+
+// CampaignID will update the value of the campaign the result belongs to
+func (u *Update) CampaignID(id string) *Update {
+	u.bresp.CampaignID = id
+
+	return u
+}
+
 // Serial will update the value of the serial for the user response
 func (u *Update) Serial(serial string) *Update {
 	u.bresp.SerialNumber = serial
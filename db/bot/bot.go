@@ -5,7 +5,8 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/db/campaigns"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
@@ -32,6 +33,7 @@ type Info struct {
 	TZOffset             int64     `json:"tz_offset"`
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
+	CampaignID           string    `json:"campaign_id"`
 }
 
 type BR []Info
@@ -41,10 +43,12 @@ func (b BR) Empty() bool {
 }
 
 type Config struct {
-	db  *pgxpool.Conn
+	db  db.Conn
 	ctx context.Context
 	log logger.Logger
 	st  sq.StatementBuilderType
+
+	campaign string
 }
 
 const table = "bot_results"
@@ -66,14 +70,16 @@ var columns = []string{
 	"delay_time",
 	"delay_sent",
 	"reminder_interval",
+	"reminder_waiting",
 	"serial_number",
 	"tz_offset",
 	"created_at",
 	"updated_at",
+	"campaign_id",
 }
 
 // Device returns a new client used to interact with the devices table
-func Bot(d *pgxpool.Pool, l *logger.Logger) *Config {
+func Bot(d db.Store, l *logger.Logger) *Config {
 	conn, err := d.Acquire(context.Background())
 	if err != nil {
 		l.Info().AnErr("acquiring connection", err).Msg("failed to acquire lock")
@@ -87,3 +93,27 @@ func Bot(d *pgxpool.Pool, l *logger.Logger) *Config {
 		st:  sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
 	}
 }
+
+// Campaign scopes the client to the results of a single campaign. Queries,
+// updates and removals only match rows for the campaign and rows added
+// without a campaign id are assigned to it.
+func (c *Config) Campaign(id string) *Config {
+	if c == nil {
+		return nil
+	}
+	c.campaign = id
+
+	return c
+}
+
+// campaignID returns the campaign rows are added to when none is set.
+func (c *Config) campaignID(id string) string {
+	if id != "" {
+		return id
+	}
+	if c.campaign != "" {
+		return c.campaign
+	}
+
+	return campaigns.Default
+}
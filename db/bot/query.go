@@ -6,7 +6,7 @@ import (
 	"strconv"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/db/compare"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
@@ -17,8 +17,10 @@ type Query struct {
 	sql sq.SelectBuilder
 	st  sq.StatementBuilderType
 
-	db  *pgxpool.Conn
+	db  db.Conn
 	log logger.Logger
+
+	campaign string
 }
 
 // By returns a new client used to interact with specific columns
@@ -28,15 +30,20 @@ type Query struct {
 // by any of the methods of Query below.
 func (c *Config) Query() *Query {
 	return &Query{
-		db:  c.db,
-		log: c.log,
-		ctx: c.ctx,
-		st:  c.st,
+		db:       c.db,
+		log:      c.log,
+		ctx:      c.ctx,
+		st:       c.st,
+		campaign: c.campaign,
 	}
 }
 
 // Query executes the query against the db with built query.
 func (q *Query) Query() (BR, error) {
+	if q.campaign != "" {
+		q.sql = q.sql.Where(sq.Eq{"campaign_id": q.campaign})
+	}
+
 	sql, args, err := q.sql.ToSql()
 
 	if err != nil {
@@ -78,7 +85,8 @@ func (q *Query) Query() (BR, error) {
 			&br.SerialNumber,
 			&br.TZOffset,
 			&br.CreatedAt,
-			&br.UpdatedAt)
+			&br.UpdatedAt,
+			&br.CampaignID)
 		if err != nil {
 			return nil, fmt.Errorf("bot row query failed %w", err)
 		}
@@ -92,104 +100,111 @@ func (q *Query) Query() (BR, error) {
 
 // All returns all results in the table
 func (q *Query) All() *Query {
-	q.sql = q.st.Select("*").From(table)
+	q.sql = q.st.Select(columns...).From(table)
+	return q
+}
+
+// CampaignID queries the table for the results of specific campaigns
+func (q *Query) CampaignID(id ...string) *Query {
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"campaign_id": id})
+
 	return q
 }
 
 // FirstACK queries the devices table for users who have acknowledged the first message
 func (q *Query) FirstACK(serial string) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"first_ack": serial})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"first_ack": serial})
 
 	return q
 }
 
 // FirstACKTime queries the devices table for users who have acknowledged the first message
 func (q *Query) FirstACKTime(fm string, op compare.Compare) *Query {
-	q.sql = compare.Comparison(q.st.Select("*").From(table), "first_ack_time", fm, op)
+	q.sql = compare.Comparison(q.st.Select(columns...).From(table), "first_ack_time", fm, op)
 
 	return q
 }
 
 // FirstMessageSent queries the devices table for users who have received the first message
 func (q *Query) FirstMessageSent(fm bool) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"first_message_sent": fm})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"first_message_sent": fm})
 
 	return q
 }
 
 // DelayAt queries the devices table for users who delayed at a specific time
 func (q *Query) DelayAt(d string, op compare.Compare) *Query {
-	q.sql = compare.Comparison(q.st.Select("*").From(table), "delay_at", d, op)
+	q.sql = compare.Comparison(q.st.Select(columns...).From(table), "delay_at", d, op)
 
 	return q
 }
 
 // DelaySent queries the devices table for users who have received the delay message
 func (q *Query) DelaySent(d string) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"delay_sent": d})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"delay_sent": d})
 
 	return q
 }
 
 // SlackID queries the table for a specific slack id value
 func (q *Query) SlackID(id ...string) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"slack_id": id})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"slack_id": id})
 
 	return q
 }
 
 // ManagerSlackID queries the table for a specific slack id value
 func (q *Query) ManagerSlackID(id ...string) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"manager_slack_id": id})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"manager_slack_id": id})
 
 	return q
 }
 
 // FirstMessageWaiting queries the devices table for users who have been sent the first message but not delivered
 func (q *Query) FirstMessageWaiting(fm bool) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"first_message_waiting": fm})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"first_message_waiting": fm})
 
 	return q
 }
 
 // FullName queries the devices table for a specific full_name value
 func (q *Query) FullName(name ...string) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"full_name": name})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"full_name": name})
 
 	return q
 }
 
 // ManagerMessageSent queries the devices table for a specific manager_message_sent value
 func (q *Query) ManagerMessageSent(sent bool) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"manager_message_sent": sent})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"manager_message_sent": sent})
 
 	return q
 }
 
 // Serial queries the devices table for a specific serial_number value
 func (q *Query) Serial(serial ...string) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"serial_number": serial})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"serial_number": serial})
 
 	return q
 }
 
 // ReminderInterval queries the devices table for a specific reminder_interval value
 func (q *Query) ReminderInterval(interval ...int) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"reminder_interval": interval})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"reminder_interval": interval})
 
 	return q
 }
 
 // ReminderWaiting queries the devices table for a devices with reminders already sent
 func (q *Query) ReminderWaiting(waiting bool) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"reminder_waiting": waiting})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"reminder_waiting": waiting})
 
 	return q
 }
 
 // TZ queries the devices table for a specific tz_offset value
 func (q *Query) TZ(tz int64) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"tz_offset": tz})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"tz_offset": tz})
 
 	return q
 }
@@ -201,28 +216,28 @@ func (q *Query) TZS(tzs ...int64) *Query {
 		s = append(s, strconv.Itoa(int(i)))
 	}
 
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"tz_offset": s})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"tz_offset": s})
 
 	return q
 }
 
 // UserEmail queries the devices table for a specific user_email value
 func (q *Query) UserEmail(email ...string) *Query {
-	q.sql = q.st.Select("*").From(table).Where(sq.Eq{"user_email": email})
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"user_email": email})
 
 	return q
 }
 
 // Created queries the devices table for a specific created_at value
 func (q *Query) Created(created string, op compare.Compare) *Query {
-	q.sql = compare.Comparison(q.st.Select("*").From(table), "created_at", created, op)
+	q.sql = compare.Comparison(q.st.Select(columns...).From(table), "created_at", created, op)
 
 	return q
 }
 
 // Created queries the devices table for a specific created_at value
 func (q *Query) Updated(updated string, op compare.Compare) *Query {
-	q.sql = compare.Comparison(q.st.Select("*").From(table), "updated_at", updated, op)
+	q.sql = compare.Comparison(q.st.Select(columns...).From(table), "updated_at", updated, op)
 
 	return q
 }
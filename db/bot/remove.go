@@ -4,7 +4,7 @@ import (
 	"context"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
@@ -12,9 +12,11 @@ type Remove struct {
 	sql sq.DeleteBuilder
 	st  sq.StatementBuilderType
 
-	db  *pgxpool.Conn
+	db  db.Conn
 	ctx context.Context
 	log logger.Logger
+
+	campaign string
 }
 
 // Remove initializes a new Remove struct.
@@ -23,15 +25,20 @@ type Remove struct {
 // fields of the statement that will be inserted once Execute is called.
 func (c *Config) Remove() *Remove {
 	return &Remove{
-		db:  c.db,
-		ctx: context.Background(),
-		log: c.log,
-		st:  c.st,
+		db:       c.db,
+		ctx:      context.Background(),
+		log:      c.log,
+		st:       c.st,
+		campaign: c.campaign,
 	}
 }
 
 // Execute sends the statement to remove the device after it has been composed.
-func (u *Remove) Execute() (*pgxpool.Conn, error) {
+func (u *Remove) Execute() (db.Conn, error) {
+	if u.campaign != "" {
+		u.sql = u.sql.Where(sq.Eq{"campaign_id": u.campaign})
+	}
+
 	sql, args, err := u.sql.ToSql()
 	if err != nil {
 		return nil, err
@@ -58,6 +65,13 @@ func (u *Remove) All() *Remove {
 	return u
 }
 
+// CampaignID will remove the results of the campaigns
+func (u *Remove) CampaignID(id ...string) *Remove {
+	u.sql = u.st.Delete(table).Where(sq.Eq{"campaign_id": id})
+
+	return u
+}
+
 // DelaySent will remove based off if the delay has been sent or not
 func (u *Remove) DelaySent(d bool) *Remove {
 	u.sql = u.st.Delete(table).Where(sq.Eq{"delay_sent": d})
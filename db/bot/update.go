@@ -3,7 +3,8 @@ package bot
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/db/parser"
 )
 
@@ -14,15 +15,16 @@ import (
 // Those are parsed and the column we are using as the condition to match is passed as the index.
 func (c *Config) Update() *Update {
 	return &Update{
-		db:  c.db,
-		ctx: context.Background(),
-		log: c.log,
+		db:       c.db,
+		ctx:      context.Background(),
+		log:      c.log,
+		campaign: c.campaign,
 	}
 }
 
 // Update sends the statement to update the device after it has been composed.
 // returns the connection which should be closed after checking the error.
-func (u *Update) Send() (*pgxpool.Conn, error) {
+func (u *Update) Send() (db.Conn, error) {
 	u.log.Trace().Str("query", u.query).Interface("args", u.args).Msg("composed sql query")
 	_, err := u.db.Exec(
 		u.ctx,
@@ -183,6 +185,11 @@ func (u *Update) Parse(index, val string) *Update {
 		},
 	}
 
+	var scope sq.Eq
+	if u.campaign != "" {
+		scope = sq.Eq{"campaign_id": u.campaign}
+	}
+
 	query, args, err := parser.ParseInput(
 		&parser.Parser{
 			Index:  index,
@@ -191,6 +198,7 @@ func (u *Update) Parse(index, val string) *Update {
 			Check:  check,
 			Method: parser.Update,
 			Into:   Info{},
+			Scope:  scope,
 		},
 	)
 	if err != nil {
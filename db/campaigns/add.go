@@ -0,0 +1,141 @@
+package campaigns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/johnmikee/cuebert/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+type Update struct {
+	c          Info
+	keepStatus bool
+	db         db.Conn
+	st         sq.StatementBuilderType
+	ctx        context.Context
+	log        logger.Logger
+}
+
+// Add initializes a new Update struct.
+//
+// the functions below that are methods of Update
+// are used to modify specific fields of the statement that
+// will be inserted once Execute is called.
+func (c *Config) Add() *Update {
+	return &Update{
+		db:  c.db,
+		ctx: context.Background(),
+		log: c.log,
+		st:  c.st,
+	}
+}
+
+// Execute sends the statement to add the campaign after it has been composed.
+// adding a campaign that already exists replaces its settings and status,
+// or only its settings after KeepStatus.
+//
+// returns the connection which should be closed after checking the error.
+func (u *Update) Execute() (db.Conn, error) {
+	now := helpers.UpdateTime()
+
+	set := []string{"name", "required_os", "deadline", "cutoff_time", "status", "updated_at"}
+	if u.keepStatus {
+		set = []string{"name", "required_os", "deadline", "cutoff_time", "updated_at"}
+	}
+	for i := range set {
+		set[i] = fmt.Sprintf("%s = EXCLUDED.%s", set[i], set[i])
+	}
+
+	query, args, err := u.st.Insert(table).
+		Columns(columns...).
+		Values(
+			u.c.ID,
+			u.c.Name,
+			u.c.RequiredOS,
+			u.c.Deadline,
+			u.c.CutoffTime,
+			u.c.Status,
+			u.c.CreatedBy,
+			now,
+			now,
+		).
+		Suffix("ON CONFLICT (id) DO UPDATE SET " + strings.Join(set, ", ")).
+		ToSql()
+
+	u.log.Trace().Str("query", query).Interface("args", args).Msg("composed sql")
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build insert statement")
+	}
+
+	_, err = u.db.Exec(u.ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute query")
+	}
+
+	u.db.Release()
+	u.log.Info().Msg("input was successfully submitted")
+	return u.db, nil
+}
+
+// ID will update the value of the campaign id
+func (u *Update) ID(id string) *Update {
+	u.c.ID = id
+
+	return u
+}
+
+// Name will update the value of the campaign name
+func (u *Update) Name(name string) *Update {
+	u.c.Name = name
+
+	return u
+}
+
+// RequiredOS will update the value of the version the campaign requires
+func (u *Update) RequiredOS(v string) *Update {
+	u.c.RequiredOS = v
+
+	return u
+}
+
+// Deadline will update the value of the campaign deadline date
+func (u *Update) Deadline(d string) *Update {
+	u.c.Deadline = d
+
+	return u
+}
+
+// CutoffTime will update the value of the time of day the deadline falls on
+func (u *Update) CutoffTime(t string) *Update {
+	u.c.CutoffTime = t
+
+	return u
+}
+
+// Status will update the value of the campaign status
+func (u *Update) Status(s Status) *Update {
+	u.c.Status = s
+
+	return u
+}
+
+// CreatedBy will update the value of who created the campaign
+func (u *Update) CreatedBy(id string) *Update {
+	u.c.CreatedBy = id
+
+	return u
+}
+
+// KeepStatus leaves the status of a campaign that already exists as it is.
+// the status is only set when the campaign is created.
+func (u *Update) KeepStatus() *Update {
+	u.keepStatus = true
+
+	return u
+}
@@ -0,0 +1,82 @@
+package campaigns
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Info represents the columns in the campaigns table
+type Info struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	RequiredOS string    `json:"required_os"`
+	Deadline   string    `json:"deadline"`
+	CutoffTime string    `json:"cutoff_time"`
+	Status     Status    `json:"status"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type CI []Info
+
+func (c CI) Empty() bool {
+	return len(c) == 0
+}
+
+// Status is where a campaign is in its lifecycle.
+type Status string
+
+const (
+	// Active campaigns have their routines run.
+	Active Status = "active"
+	// Paused campaigns keep their results but are skipped until resumed.
+	Paused Status = "paused"
+	// Closed campaigns are finished and their results are removed.
+	Closed Status = "closed"
+)
+
+// Default is the campaign built from the required version, deadline and
+// cutoff time flags. bot_results rows without a campaign belong to it.
+const Default = "default"
+
+type Config struct {
+	db  db.Conn
+	ctx context.Context
+	log logger.Logger
+	st  sq.StatementBuilderType
+}
+
+const table = "campaigns"
+
+var columns = []string{
+	"id",
+	"name",
+	"required_os",
+	"deadline",
+	"cutoff_time",
+	"status",
+	"created_by",
+	"created_at",
+	"updated_at",
+}
+
+// Campaign returns a new client used to interact with the campaigns table
+func Campaign(d db.Store, l *logger.Logger) *Config {
+	conn, err := d.Acquire(context.Background())
+	if err != nil {
+		l.Info().AnErr("acquiring connection", err).Msg("failed to acquire lock")
+		return nil
+	}
+
+	return &Config{
+		ctx: context.Background(),
+		db:  conn,
+		log: logger.ChildLogger("db/campaigns", l),
+		st:  sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
@@ -0,0 +1,93 @@
+package campaigns
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Query holds the configuration for the building and executing the query.
+type Query struct {
+	db  db.Conn
+	log logger.Logger
+	sql sq.SelectBuilder
+	st  sq.StatementBuilderType
+}
+
+// Query returns a new client used to interact with specific columns
+// in the campaigns table.
+func (c *Config) Query() *Query {
+	return &Query{
+		db:  c.db,
+		log: c.log,
+		st:  c.st,
+	}
+}
+
+// Query executes the query against the db with built query.
+func (q *Query) Query() (CI, error) {
+	sql, args, err := q.sql.OrderBy("created_at").ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("sql generation failed %w", err)
+	}
+
+	q.log.Trace().Str("query", sql).Msg("composed sql query")
+
+	campaigns := []Info{}
+
+	rows, err := q.db.Query(
+		context.Background(),
+		sql, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("campaign query failed %w", err)
+	}
+
+	for rows.Next() {
+		var c Info
+
+		err = rows.Scan(
+			&c.ID,
+			&c.Name,
+			&c.RequiredOS,
+			&c.Deadline,
+			&c.CutoffTime,
+			&c.Status,
+			&c.CreatedBy,
+			&c.CreatedAt,
+			&c.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("campaign row query failed %w", err)
+		}
+		campaigns = append(campaigns, c)
+	}
+
+	q.db.Release()
+
+	return campaigns, nil
+}
+
+// All returns all campaigns in the table
+func (q *Query) All() *Query {
+	q.sql = q.st.Select(columns...).From(table)
+
+	return q
+}
+
+// ID queries the campaigns table for the campaigns with the ids
+func (q *Query) ID(id ...string) *Query {
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"id": id})
+
+	return q
+}
+
+// Status queries the campaigns table for the campaigns in a status
+func (q *Query) Status(s ...Status) *Query {
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"status": s})
+
+	return q
+}
@@ -0,0 +1,62 @@
+package campaigns
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+type Remove struct {
+	db  db.Conn
+	dt  sq.StatementBuilderType
+	sql sq.DeleteBuilder
+	ctx context.Context
+	log logger.Logger
+}
+
+// Remove initializes a new Remove struct.
+//
+// the methods of Remove are used to designate specific
+// fields of the statement that will be inserted once Execute is called.
+func (c *Config) Remove() *Remove {
+	return &Remove{
+		db:  c.db,
+		ctx: context.Background(),
+		log: c.log,
+		dt:  c.st,
+	}
+}
+
+// Execute sends the statement to remove the campaigns after it has been composed.
+//
+// returns the connection which should be closed after checking the error.
+func (r *Remove) Execute() (db.Conn, error) {
+	sql, args, err := r.sql.ToSql()
+
+	r.log.Trace().Str("query", sql).Interface("args", args).Msg("composed sql query")
+	if err != nil {
+		return nil, fmt.Errorf("sql generation failed %w", err)
+	}
+
+	_, err = r.db.Exec(
+		r.ctx,
+		sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("campaign query failed %w", err)
+	}
+
+	r.db.Release()
+	r.log.Info().Msg("campaigns were successfully removed")
+
+	return r.db, nil
+}
+
+// ID will remove the campaign with the id
+func (r *Remove) ID(id ...string) *Remove {
+	r.sql = r.dt.Delete(table).Where(sq.Eq{"id": id})
+
+	return r
+}
@@ -0,0 +1,68 @@
+package campaigns
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Change holds the values to update on an existing campaign.
+type Change struct {
+	id  string
+	sql sq.UpdateBuilder
+	db  db.Conn
+	ctx context.Context
+	log logger.Logger
+}
+
+// Update initializes a new Change for the campaign.
+//
+// the methods of Change are used to set the values that will be
+// updated once Send is called.
+func (c *Config) Update(id string) *Change {
+	return &Change{
+		id:  id,
+		sql: c.st.Update(table).Where(sq.Eq{"id": id}),
+		db:  c.db,
+		ctx: context.Background(),
+		log: c.log,
+	}
+}
+
+// Status will update the status of the campaign
+func (u *Change) Status(s Status) *Change {
+	u.sql = u.sql.Set("status", s)
+
+	return u
+}
+
+// Send sends the statement to update the campaign after it has been composed.
+//
+// returns the connection which should be closed after checking the error.
+func (u *Change) Send() (db.Conn, error) {
+	sql, args, err := u.sql.Set("updated_at", helpers.UpdateTime()).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("sql generation failed %w", err)
+	}
+
+	u.log.Trace().Str("query", sql).Interface("args", args).Msg("composed sql query")
+
+	tag, err := u.db.Exec(u.ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("campaign update failed %w", err)
+	}
+
+	u.db.Release()
+
+	if tag.RowsAffected() == 0 {
+		return u.db, fmt.Errorf("no campaign found with id %s", u.id)
+	}
+
+	u.log.Info().Str("id", u.id).Msg("campaign was successfully updated")
+
+	return u.db, nil
+}
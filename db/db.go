@@ -44,14 +44,19 @@ type Close func(context.Context, *pgxpool.Pool)
 // interact with the DB.
 type PGDB struct {
 	DB     *pgxpool.Pool
+	Store  Store
 	Ctx    context.Context
 	Logger logger.Logger
 	Close  Close
 }
 
-type DB = pgxpool.Pool
+var CueTables = []string{"bot_results", "campaigns", "compliance_history", "devices", "exclusions", "playbook_steps", "users"}
 
-var CueTables = []string{"bot_results", "devices", "exclusions", "users"}
+// ResetTables are the tables cleared when -clear-tables is set. exclusions
+// are left out so approvals survive, playbook_steps so a restart does not
+// repeat escalation steps, campaigns so campaigns created from slack outlive
+// a restart and compliance_history so reporting keeps who updated when.
+var ResetTables = []string{"bot_results", "devices", "users"}
 
 // New returns a new empty dbconfig. The methods of dbconfig below help to configure
 // the required items to form a connection with the DB.
@@ -93,6 +98,7 @@ func (d *DBConfig) Connect() (*PGDB, error) {
 
 	return &PGDB{
 		DB:     conn,
+		Store:  NewPostgres(conn),
 		Ctx:    d.ctx,
 		Logger: d.log,
 		Close:  d.closeConnection,
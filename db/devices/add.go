@@ -4,8 +4,7 @@ import (
 	"context"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 	"github.com/johnmikee/cuebert/pkg/logger"
 	"github.com/pkg/errors"
@@ -15,7 +14,7 @@ type Update struct {
 	args   []interface{}
 	ctx    context.Context
 	device *Info
-	db     *pgxpool.Conn
+	db     db.Conn
 	log    logger.Logger
 	st     sq.StatementBuilderType
 	query  string
@@ -36,44 +35,50 @@ func (c *Config) Add() *Update {
 	}
 }
 
-// AddAllDevices should only be used in specific cases.
-//
-// - to initialize the DB with values
-// - if a table is dropped and rebuilt
-//
-// This will add all devices passed to the DB.
+// AddAllDevices adds the devices passed to the DB. Devices that are already
+// in the table are updated with the snapshot so restarts keep every row.
 func (c *Config) AddAllDevices(devices DI) (int64, error) {
-	rows := [][]interface{}{}
+	batch := []db.Statement{}
 	for i := range devices {
-		row := []interface{}{
-			devices[i].DeviceID,
-			devices[i].DeviceName,
-			devices[i].Model,
-			devices[i].SerialNumber,
-			devices[i].Platform,
-			devices[i].OSVersion,
-			devices[i].User,
-			devices[i].UserMDMID,
-			devices[i].LastCheckIn,
-			helpers.UpdateTime(),
-			helpers.UpdateTime(),
+		query, args, err := c.st.Insert(table).
+			Columns(columns...).
+			Values(
+				devices[i].DeviceID,
+				devices[i].DeviceName,
+				devices[i].Model,
+				devices[i].SerialNumber,
+				devices[i].Platform,
+				devices[i].OSVersion,
+				devices[i].User,
+				devices[i].UserMDMID,
+				devices[i].LastCheckIn,
+				helpers.UpdateTime(),
+				helpers.UpdateTime(),
+			).
+			Suffix(`ON CONFLICT (device_id) DO UPDATE SET
+	device_name = EXCLUDED.device_name,
+	model = EXCLUDED.model,
+	serial_number = EXCLUDED.serial_number,
+	platform = EXCLUDED.platform,
+	os_version = EXCLUDED.os_version,
+	user_name = EXCLUDED.user_name,
+	user_mdm_id = EXCLUDED.user_mdm_id,
+	last_check_in = EXCLUDED.last_check_in,
+	updated_at = EXCLUDED.updated_at`).
+			ToSql()
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to build insert query")
 		}
-		rows = append(rows, row)
+		batch = append(batch, db.Statement{SQL: query, Args: args})
 	}
 
-	copyCount, err := c.db.CopyFrom(
-		context.Background(),
-		pgx.Identifier{table},
-		columns,
-		pgx.CopyFromRows(rows))
-
-	c.db.Release()
+	defer c.db.Release()
 
-	return copyCount, err
+	return c.db.Batch(context.Background(), batch)
 }
 
 // Execute sends the statement to add the device after it has been composed.
-func (u *Update) Execute() (*pgxpool.Conn, error) {
+func (u *Update) Execute() (db.Conn, error) {
 	query, args, err := u.st.Insert(table).
 		Columns(columns...).
 		Values(
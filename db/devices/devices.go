@@ -5,7 +5,7 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
@@ -32,7 +32,7 @@ func (d DI) Empty() bool {
 
 type Config struct {
 	ctx context.Context
-	db  *pgxpool.Conn
+	db  db.Conn
 	log logger.Logger
 	st  sq.StatementBuilderType
 }
@@ -54,7 +54,7 @@ var columns = []string{
 }
 
 // Device returns a new client used to interact with the devices table
-func Device(d *pgxpool.Pool, l *logger.Logger) *Config {
+func Device(d db.Store, l *logger.Logger) *Config {
 	conn, err := d.Acquire(context.Background())
 	if err != nil {
 		l.Info().AnErr("acquiring connection", err).Msg("failed to acquire lock")
@@ -5,12 +5,12 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
 type Query struct {
-	db  *pgxpool.Conn
+	db  db.Conn
 	log logger.Logger
 	sql sq.SelectBuilder
 	st  sq.StatementBuilderType
@@ -5,13 +5,13 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
 type Remove struct {
 	ctx context.Context
-	db  *pgxpool.Conn
+	db  db.Conn
 	log logger.Logger
 	sql sq.DeleteBuilder
 	st  sq.StatementBuilderType
@@ -31,7 +31,7 @@ func (c *Config) Remove() *Remove {
 }
 
 // Remove sends the statement to remove the device after it has been composed.
-func (u *Remove) Execute() (*pgxpool.Conn, error) {
+func (u *Remove) Execute() (db.Conn, error) {
 	sql, args, err := u.sql.ToSql()
 
 	if err != nil {
@@ -3,7 +3,7 @@ package devices
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/db/parser"
 )
 
@@ -23,7 +23,7 @@ func (c *Config) Update() *Update {
 }
 
 // Send sends the statement to update the device after it has been composed.
-func (u *Update) Send() (*pgxpool.Conn, error) {
+func (u *Update) Send() (db.Conn, error) {
 	_, err := u.db.Exec(
 		u.ctx,
 		u.query, u.args...)
@@ -5,7 +5,7 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 	"github.com/johnmikee/cuebert/pkg/logger"
 	"github.com/pkg/errors"
@@ -14,7 +14,7 @@ import (
 type Update struct {
 	args  []interface{}
 	e     Info
-	db    *pgxpool.Conn
+	db    db.Conn
 	st    sq.StatementBuilderType
 	query string
 	ctx   context.Context
@@ -38,7 +38,7 @@ func (c *Config) Add() *Update {
 // Execute sends the statement to add the user after it has been composed.
 //
 // returns the connection which should be closed after checking the error.
-func (e *Update) Execute() (*pgxpool.Conn, error) {
+func (e *Update) Execute() (db.Conn, error) {
 	query, args, err := e.st.Insert(table).
 		Columns(columns...).
 		Values(
@@ -5,7 +5,7 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
@@ -27,7 +27,7 @@ func (e EI) Empty() bool {
 }
 
 type Config struct {
-	db  *pgxpool.Conn
+	db  db.Conn
 	ctx context.Context
 	log logger.Logger
 	st  sq.StatementBuilderType
@@ -46,7 +46,7 @@ var columns = []string{
 }
 
 // Exclusion returns a new client used to interact with the exclusions table
-func Exclusion(d *pgxpool.Pool, l *logger.Logger) *Config {
+func Exclusion(d db.Store, l *logger.Logger) *Config {
 	conn, err := d.Acquire(context.Background())
 	if err != nil {
 		l.Info().AnErr("acquiring connection", err).Msg("failed to acquire lock")
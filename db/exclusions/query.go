@@ -5,13 +5,13 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
 // Query holds the configuration for the building and executing the query.
 type Query struct {
-	db  *pgxpool.Conn
+	db  db.Conn
 	log logger.Logger
 	sql sq.SelectBuilder
 	st  sq.StatementBuilderType
@@ -5,12 +5,12 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
 type Remove struct {
-	db  *pgxpool.Conn
+	db  db.Conn
 	dt  sq.StatementBuilderType
 	sql sq.DeleteBuilder
 	ctx context.Context
@@ -33,7 +33,7 @@ func (c *Config) Remove() *Remove {
 // Remove sends the statement to remove the exclusion after it has been composed.
 //
 // returns the connection which should be closed after checking the error.
-func (u *Remove) Execute() (*pgxpool.Conn, error) {
+func (u *Remove) Execute() (db.Conn, error) {
 	sql, args, err := u.sql.ToSql()
 
 	u.log.Trace().Str("query", sql).Interface("args", args).Msg("composed sql query")
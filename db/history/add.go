@@ -0,0 +1,48 @@
+package history
+
+import (
+	"context"
+
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/pkg/errors"
+)
+
+// AddAll archives the rows passed. completed_at defaults to now when unset.
+func (c *Config) AddAll(h HI) (int64, error) {
+	defer c.db.Release()
+
+	now := helpers.UpdateTime()
+
+	batch := []db.Statement{}
+	for i := range h {
+		completed := h[i].CompletedAt
+		if completed.IsZero() {
+			completed = now
+		}
+
+		query, args, err := c.st.Insert(table).
+			Columns(columns...).
+			Values(
+				h[i].SerialNumber,
+				h[i].CampaignID,
+				h[i].SlackID,
+				h[i].UserEmail,
+				h[i].FullName,
+				h[i].RequiredOS,
+				h[i].OSVersion,
+				h[i].FirstMessageSentAt,
+				h[i].FirstACKTime,
+				h[i].ManagerMessageSentAt,
+				completed,
+				now,
+			).
+			ToSql()
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to build insert query")
+		}
+		batch = append(batch, db.Statement{SQL: query, Args: args})
+	}
+
+	return c.db.Batch(context.Background(), batch)
+}
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Info represents the columns in the compliance_history table
+type Info struct {
+	ID                   int64     `json:"id"`
+	SerialNumber         string    `json:"serial_number"`
+	CampaignID           string    `json:"campaign_id"`
+	SlackID              string    `json:"slack_id"`
+	UserEmail            string    `json:"user_email"`
+	FullName             string    `json:"full_name"`
+	RequiredOS           string    `json:"required_os"`
+	OSVersion            string    `json:"os_version"`
+	FirstMessageSentAt   time.Time `json:"first_message_sent_at"`
+	FirstACKTime         time.Time `json:"first_ack_time"`
+	ManagerMessageSentAt time.Time `json:"manager_message_sent_at"`
+	CompletedAt          time.Time `json:"completed_at"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+type HI []Info
+
+func (h HI) Empty() bool {
+	return len(h) == 0
+}
+
+type Config struct {
+	db  db.Conn
+	ctx context.Context
+	log logger.Logger
+	st  sq.StatementBuilderType
+}
+
+const table = "compliance_history"
+
+// columns are the columns written on insert. the id is generated.
+var columns = []string{
+	"serial_number",
+	"campaign_id",
+	"slack_id",
+	"user_email",
+	"full_name",
+	"required_os",
+	"os_version",
+	"first_message_sent_at",
+	"first_ack_time",
+	"manager_message_sent_at",
+	"completed_at",
+	"created_at",
+}
+
+// History returns a new client used to interact with the compliance_history table
+func History(d db.Store, l *logger.Logger) *Config {
+	conn, err := d.Acquire(context.Background())
+	if err != nil {
+		l.Info().AnErr("acquiring connection", err).Msg("failed to acquire lock")
+		return nil
+	}
+
+	return &Config{
+		ctx: context.Background(),
+		db:  conn,
+		log: logger.ChildLogger("db/history", l),
+		st:  sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
@@ -0,0 +1,117 @@
+package history
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/db/compare"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Query holds the configuration for the building and executing the query.
+type Query struct {
+	db  db.Conn
+	log logger.Logger
+	sql sq.SelectBuilder
+	st  sq.StatementBuilderType
+}
+
+// Query returns a new client used to interact with specific columns
+// in the compliance_history table.
+func (c *Config) Query() *Query {
+	return &Query{
+		db:  c.db,
+		log: c.log,
+		st:  c.st,
+	}
+}
+
+// Query executes the query against the db with built query. rows are
+// returned oldest completion first.
+func (q *Query) Query() (HI, error) {
+	sql, args, err := q.sql.OrderBy("completed_at").ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("sql generation failed %w", err)
+	}
+
+	q.log.Trace().Str("query", sql).Msg("composed sql query")
+
+	history := []Info{}
+
+	rows, err := q.db.Query(
+		context.Background(),
+		sql, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("history query failed %w", err)
+	}
+
+	for rows.Next() {
+		var h Info
+
+		err = rows.Scan(
+			&h.ID,
+			&h.SerialNumber,
+			&h.CampaignID,
+			&h.SlackID,
+			&h.UserEmail,
+			&h.FullName,
+			&h.RequiredOS,
+			&h.OSVersion,
+			&h.FirstMessageSentAt,
+			&h.FirstACKTime,
+			&h.ManagerMessageSentAt,
+			&h.CompletedAt,
+			&h.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("history row query failed %w", err)
+		}
+		history = append(history, h)
+	}
+
+	q.db.Release()
+
+	return history, nil
+}
+
+func (q *Query) selectAll() sq.SelectBuilder {
+	return q.st.Select(append([]string{"id"}, columns...)...).From(table)
+}
+
+// All returns all archived rows in the table
+func (q *Query) All() *Query {
+	q.sql = q.selectAll()
+
+	return q
+}
+
+// Serial queries the compliance_history table for the devices with the serials
+func (q *Query) Serial(serial ...string) *Query {
+	q.sql = q.selectAll().Where(sq.Eq{"serial_number": serial})
+
+	return q
+}
+
+// UserEmail queries the compliance_history table for the devices of the users
+func (q *Query) UserEmail(email ...string) *Query {
+	q.sql = q.selectAll().Where(sq.Eq{"user_email": email})
+
+	return q
+}
+
+// CampaignID queries the compliance_history table for the campaigns
+func (q *Query) CampaignID(id ...string) *Query {
+	q.sql = q.selectAll().Where(sq.Eq{"campaign_id": id})
+
+	return q
+}
+
+// Completed queries the compliance_history table comparing completed_at to the value
+func (q *Query) Completed(completed string, op compare.Compare) *Query {
+	q.sql = compare.Comparison(q.selectAll(), "completed_at", completed, op)
+
+	return q
+}
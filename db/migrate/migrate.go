@@ -0,0 +1,286 @@
+package migrate
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+/*
+this package keeps the database schema up to date. each change to the schema
+is a numbered pair of up and down sql files under migrations. the versions that
+have been applied are recorded in the schema_migrations table so upgrades only
+run what is new and never drop data.
+*/
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+const dir = "migrations"
+
+// lockID is the advisory lock held while migrating so two instances starting
+// at once do not apply the same migration.
+const lockID = 73616
+
+// Migration is a single numbered change to the schema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and when it was applied.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Config holds the pool migrations are run with and the embedded migrations.
+type Config struct {
+	db         *pgxpool.Pool
+	log        logger.Logger
+	migrations []Migration
+}
+
+// file matches migration file names, ex: 0001_create_tables.up.sql
+var file = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migrate returns a new client used to migrate the database with the
+// embedded migrations.
+func Migrate(d *pgxpool.Pool, l *logger.Logger) (*Config, error) {
+	m, err := Load(migrations, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		db:         d,
+		log:        logger.ChildLogger("db/migrate", l),
+		migrations: m,
+	}, nil
+}
+
+// Load reads the migrations in the directory sorted by version. Every
+// version needs both an up and a down file.
+func Load(fsys fs.FS, root string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading migrations")
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		m := file.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("%s is not a migration, ex: 0001_name.up.sql", e.Name())
+		}
+
+		version, err := strconv.Atoi(m[1])
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("%s has an invalid version", e.Name())
+		}
+
+		b, err := fs.ReadFile(fsys, path.Join(root, e.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", e.Name())
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("version %d is used by %s and %s", version, mig.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			mig.Up = string(b)
+		} else {
+			mig.Down = string(b)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs an up and a down file", mig.Version, mig.Name)
+		}
+		out = append(out, *mig)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+
+	return out, nil
+}
+
+// Status returns every migration and whether it has been applied.
+func (c *Config) Status(ctx context.Context) ([]Status, error) {
+	var status []Status
+
+	err := c.locked(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedAt(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range c.migrations {
+			at, ok := applied[m.Version]
+			status = append(status, Status{Migration: m, Applied: ok, AppliedAt: at})
+		}
+
+		return nil
+	})
+
+	return status, err
+}
+
+// Up applies the migrations that have not been applied yet, oldest first.
+// Each migration runs in its own transaction.
+func (c *Config) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+
+	err := c.locked(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedAt(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range c.migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+
+			c.log.Info().Int("version", m.Version).Str("name", m.Name).Msg("applying migration")
+
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, m.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx,
+					"INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)",
+					m.Version, m.Name, time.Now().UTC())
+				return err
+			})
+			if err != nil {
+				return errors.Wrapf(err, "applying migration %d_%s", m.Version, m.Name)
+			}
+
+			done = append(done, m)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down reverts the latest applied migrations, newest first.
+func (c *Config) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+
+	err := c.locked(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedAt(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(c.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			m := c.migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+
+			c.log.Info().Int("version", m.Version).Str("name", m.Name).Msg("reverting migration")
+
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, m.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", m.Version)
+				return err
+			})
+			if err != nil {
+				return errors.Wrapf(err, "reverting migration %d_%s", m.Version, m.Name)
+			}
+
+			done = append(done, m)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// locked runs fn on a single connection while holding the migration lock.
+// The schema_migrations table is created first if it does not exist.
+func (c *Config) locked(ctx context.Context, fn func(*pgxpool.Conn) error) error {
+	conn, err := c.db.Acquire(ctx)
+	if err != nil {
+		return errors.Wrap(err, "acquiring connection")
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return errors.Wrap(err, "taking migration lock")
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockID); err != nil {
+			c.log.Err(err).Msg("releasing migration lock")
+		}
+	}()
+
+	_, err = conn.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version bigint NOT NULL,
+	name character varying(255) NOT NULL,
+	applied_at timestamp NOT NULL,
+	PRIMARY KEY (version)
+);`)
+	if err != nil {
+		return errors.Wrap(err, "creating schema_migrations")
+	}
+
+	return fn(conn)
+}
+
+// appliedAt returns when each applied version was applied.
+func appliedAt(ctx context.Context, conn *pgxpool.Conn) (map[int]time.Time, error) {
+	rows, err := conn.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, errors.Wrap(err, "querying schema_migrations")
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var (
+			version int
+			at      time.Time
+		)
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, errors.Wrap(err, "scanning schema_migrations")
+		}
+		applied[version] = at
+	}
+
+	return applied, rows.Err()
+}
@@ -0,0 +1,83 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_second.up.sql":   {Data: []byte("up 2")},
+		"m/0002_second.down.sql": {Data: []byte("down 2")},
+		"m/0001_first.up.sql":    {Data: []byte("up 1")},
+		"m/0001_first.down.sql":  {Data: []byte("down 1")},
+	}
+
+	m, err := Load(fsys, "m")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(m) != 2 {
+		t.Fatalf("Load() returned %d migrations, want 2", len(m))
+	}
+	if m[0].Version != 1 || m[0].Name != "first" || m[0].Up != "up 1" || m[0].Down != "down 1" {
+		t.Errorf("Load()[0] = %+v", m[0])
+	}
+	if m[1].Version != 2 || m[1].Name != "second" {
+		t.Errorf("Load()[1] = %+v", m[1])
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "missing down",
+			fsys: fstest.MapFS{"m/0001_first.up.sql": {Data: []byte("up")}},
+		},
+		{
+			name: "bad name",
+			fsys: fstest.MapFS{"m/first.sql": {Data: []byte("up")}},
+		},
+		{
+			name: "zero version",
+			fsys: fstest.MapFS{
+				"m/0000_first.up.sql":   {Data: []byte("up")},
+				"m/0000_first.down.sql": {Data: []byte("down")},
+			},
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"m/0001_first.up.sql":    {Data: []byte("up")},
+				"m/0001_first.down.sql":  {Data: []byte("down")},
+				"m/0001_second.up.sql":   {Data: []byte("up")},
+				"m/0001_second.down.sql": {Data: []byte("down")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.fsys, "m"); err == nil {
+				t.Errorf("Load() expected an error")
+			}
+		})
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	m, err := Load(migrations, dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for i := range m {
+		if m[i].Version != i+1 {
+			t.Errorf("migration %s has version %d, want %d", m[i].Name, m[i].Version, i+1)
+		}
+	}
+}
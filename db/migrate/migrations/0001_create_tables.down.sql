@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS playbook_steps;
+DROP TABLE IF EXISTS bot_results;
+DROP TABLE IF EXISTS exclusions;
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,3 @@
+ALTER TABLE bot_results
+    DROP COLUMN IF EXISTS reminder_waiting,
+    DROP COLUMN IF EXISTS reminder_interval;
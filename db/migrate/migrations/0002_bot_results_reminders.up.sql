@@ -0,0 +1,15 @@
+-- bot.Info and the timebound method track reminders on each result.
+ALTER TABLE bot_results
+    ADD COLUMN IF NOT EXISTS reminder_interval int NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS reminder_waiting boolean NOT NULL DEFAULT false;
+
+-- tables made from create_tables.sql already have the columns but allow nulls,
+-- which cannot be scanned into bot.Info.
+UPDATE bot_results SET reminder_interval = 0 WHERE reminder_interval IS NULL;
+UPDATE bot_results SET reminder_waiting = false WHERE reminder_waiting IS NULL;
+
+ALTER TABLE bot_results
+    ALTER COLUMN reminder_interval SET DEFAULT 0,
+    ALTER COLUMN reminder_interval SET NOT NULL,
+    ALTER COLUMN reminder_waiting SET DEFAULT false,
+    ALTER COLUMN reminder_waiting SET NOT NULL;
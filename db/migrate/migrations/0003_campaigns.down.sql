@@ -0,0 +1,11 @@
+-- only the default campaign fits the single result per serial this reverts to.
+DELETE FROM bot_results WHERE campaign_id <> 'default';
+
+ALTER TABLE bot_results
+    DROP CONSTRAINT IF EXISTS bot_results_pkey,
+    ADD PRIMARY KEY (serial_number);
+
+ALTER TABLE bot_results
+    DROP COLUMN IF EXISTS campaign_id;
+
+DROP TABLE IF EXISTS campaigns;
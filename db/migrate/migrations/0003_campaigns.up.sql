@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id character varying(255) NOT NULL,
+    name character varying(255),
+    required_os character varying(255) NOT NULL,
+    deadline character varying(255) NOT NULL,
+    cutoff_time character varying(255) NOT NULL,
+    status character varying(255) NOT NULL,
+    created_by character varying(255),
+    created_at timestamp,
+    updated_at timestamp,
+    PRIMARY KEY (id)
+);
+
+-- a device has a result for each campaign it is behind on.
+ALTER TABLE bot_results
+    ADD COLUMN IF NOT EXISTS campaign_id character varying(255) NOT NULL DEFAULT 'default';
+
+ALTER TABLE bot_results
+    DROP CONSTRAINT IF EXISTS bot_results_pkey,
+    ADD PRIMARY KEY (serial_number, campaign_id);
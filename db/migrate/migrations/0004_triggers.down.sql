@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS devices_notify_event ON devices;
+DROP TRIGGER IF EXISTS bot_notify_event ON bot_results;
+DROP TRIGGER IF EXISTS update_exclusion_time ON exclusions;
+DROP TRIGGER IF EXISTS update_bot_time ON bot_results;
+DROP TRIGGER IF EXISTS update_user_time ON users;
+DROP TRIGGER IF EXISTS update_device_time ON devices;
+
+DROP FUNCTION IF EXISTS notify_table_event();
+DROP FUNCTION IF EXISTS log_last_updated();
@@ -0,0 +1,59 @@
+-- keep updated_at current on every write.
+CREATE OR REPLACE FUNCTION log_last_updated() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- publish bot_results and devices changes on a channel named after the table.
+CREATE OR REPLACE FUNCTION notify_table_event() RETURNS TRIGGER AS $$
+DECLARE
+    data json;
+BEGIN
+    IF (TG_OP = 'DELETE') THEN
+        data = row_to_json(OLD);
+    ELSE
+        data = row_to_json(NEW);
+    END IF;
+
+    PERFORM pg_notify(
+        TG_TABLE_NAME,
+        json_build_object('table', TG_TABLE_NAME, 'action', TG_OP, 'data', data)::text
+    );
+
+    -- the result is ignored since this is an AFTER trigger.
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS update_device_time ON devices;
+CREATE TRIGGER update_device_time
+BEFORE INSERT OR UPDATE ON devices
+FOR EACH ROW EXECUTE PROCEDURE log_last_updated();
+
+DROP TRIGGER IF EXISTS update_user_time ON users;
+CREATE TRIGGER update_user_time
+BEFORE INSERT OR UPDATE ON users
+FOR EACH ROW EXECUTE PROCEDURE log_last_updated();
+
+DROP TRIGGER IF EXISTS update_bot_time ON bot_results;
+CREATE TRIGGER update_bot_time
+BEFORE INSERT OR UPDATE ON bot_results
+FOR EACH ROW EXECUTE PROCEDURE log_last_updated();
+
+DROP TRIGGER IF EXISTS update_bot_time ON exclusions;
+DROP TRIGGER IF EXISTS update_exclusion_time ON exclusions;
+CREATE TRIGGER update_exclusion_time
+BEFORE INSERT OR UPDATE ON exclusions
+FOR EACH ROW EXECUTE PROCEDURE log_last_updated();
+
+DROP TRIGGER IF EXISTS bot_notify_event ON bot_results;
+CREATE TRIGGER bot_notify_event
+AFTER INSERT OR UPDATE OR DELETE ON bot_results
+FOR EACH ROW EXECUTE PROCEDURE notify_table_event();
+
+DROP TRIGGER IF EXISTS devices_notify_event ON devices;
+CREATE TRIGGER devices_notify_event
+AFTER INSERT OR UPDATE OR DELETE ON devices
+FOR EACH ROW EXECUTE PROCEDURE notify_table_event();
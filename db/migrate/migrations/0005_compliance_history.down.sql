@@ -0,0 +1 @@
+DROP TABLE IF EXISTS compliance_history;
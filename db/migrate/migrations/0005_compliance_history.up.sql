@@ -0,0 +1,20 @@
+-- a row is archived for each device once it meets the campaign it was behind
+-- on so reporting can show who updated when.
+CREATE TABLE IF NOT EXISTS compliance_history (
+    id bigserial NOT NULL,
+    serial_number character varying(255) NOT NULL,
+    campaign_id character varying(255) NOT NULL,
+    slack_id character varying(255),
+    user_email character varying(255),
+    full_name character varying(255),
+    required_os character varying(255),
+    os_version character varying(255),
+    first_message_sent_at timestamp NOT NULL,
+    first_ack_time timestamp NOT NULL,
+    manager_message_sent_at timestamp NOT NULL,
+    completed_at timestamp NOT NULL,
+    created_at timestamp,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS compliance_history_completed_at ON compliance_history (completed_at);
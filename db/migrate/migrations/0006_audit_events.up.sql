@@ -0,0 +1,31 @@
+-- a row is appended for every bot action and admin decision. the table is
+-- append only so the record of who did what cannot be rewritten.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id bigserial NOT NULL,
+    actor character varying(255) NOT NULL,
+    action character varying(255) NOT NULL,
+    target_user character varying(255) NOT NULL DEFAULT '',
+    target_serial character varying(255) NOT NULL DEFAULT '',
+    before_state text NOT NULL DEFAULT '',
+    after_state text NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_created_at ON audit_events (created_at);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE PROCEDURE audit_events_append_only();
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+CREATE TRIGGER audit_events_no_truncate
+BEFORE TRUNCATE ON audit_events
+FOR EACH STATEMENT EXECUTE PROCEDURE audit_events_append_only();
@@ -19,6 +19,9 @@ type Parser struct {
 	Check  []CheckInfo
 	Method Method
 	Into   interface{}
+	// Scope is added to the conditions of the statement, ex: limiting an
+	// update to the rows of a single campaign.
+	Scope sq.Eq
 }
 
 // Method is a type that holds the method to be used in the sql query.
@@ -163,6 +166,9 @@ func (p *Parser) update(base sq.UpdateBuilder) (string, []interface{}, error) {
 	}
 
 	base = base.Where(sq.Eq{p.Index: p.Val})
+	if len(p.Scope) > 0 {
+		base = base.Where(p.Scope)
+	}
 
 	return base.ToSql()
 }
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type row struct {
+	Serial   string `json:"serial_number"`
+	Name     string `json:"full_name"`
+	Campaign string `json:"campaign_id"`
+}
+
+func TestParseInputScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		scope    sq.Eq
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "unscoped",
+			wantSQL:  "UPDATE results SET full_name = $1 WHERE serial_number = $2",
+			wantArgs: []interface{}{"Jane", "C02XYZ"},
+		},
+		{
+			name:     "scoped to a campaign",
+			scope:    sq.Eq{"campaign_id": "rsr"},
+			wantSQL:  "UPDATE results SET full_name = $1 WHERE serial_number = $2 AND campaign_id = $3",
+			wantArgs: []interface{}{"Jane", "C02XYZ", "rsr"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := ParseInput(&Parser{
+				Index: "serial_number",
+				Table: "results",
+				Val:   "C02XYZ",
+				Check: []CheckInfo{
+					{Fn: Prim{S: "Jane"}, Key: "full_name", Trimmed: "Name"},
+				},
+				Method: Update,
+				Into:   row{},
+				Scope:  tt.scope,
+			})
+			if err != nil {
+				t.Fatalf("ParseInput() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ParseInput() sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ParseInput() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
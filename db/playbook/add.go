@@ -0,0 +1,86 @@
+package playbook
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+type Update struct {
+	p   Info
+	db  db.Conn
+	st  sq.StatementBuilderType
+	ctx context.Context
+	log logger.Logger
+}
+
+// Add initializes a new Update struct.
+//
+// the functions below that are methods of Update
+// are used to modify specific fields of the statement that
+// will be inserted once Execute is called.
+func (c *Config) Add() *Update {
+	return &Update{
+		db:  c.db,
+		ctx: context.Background(),
+		log: c.log,
+		st:  c.st,
+	}
+}
+
+// Execute sends the statement to record the step after it has been composed.
+// a step that was already recorded for the serial is left as is.
+//
+// returns the connection which should be closed after checking the error.
+func (p *Update) Execute() (db.Conn, error) {
+	query, args, err := p.st.Insert(table).
+		Columns(columns...).
+		Values(
+			p.p.SerialNumber,
+			p.p.Step,
+			p.p.CompletedAt,
+		).
+		Suffix("ON CONFLICT (serial_number, step) DO NOTHING").
+		ToSql()
+
+	p.log.Trace().Str("query", query).Interface("args", args).Msg("composed sql")
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build insert statement")
+	}
+
+	_, err = p.db.Exec(p.ctx, query, args...)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute query")
+	}
+
+	p.db.Release()
+	p.log.Info().Msg("input was successfully submitted")
+	return p.db, nil
+}
+
+// SerialNumber will update the value of the serial number the step ran for
+func (p *Update) SerialNumber(s string) *Update {
+	p.p.SerialNumber = s
+
+	return p
+}
+
+// Step will update the value of the name of the completed step
+func (p *Update) Step(s string) *Update {
+	p.p.Step = s
+
+	return p
+}
+
+// CompletedAt will update the value of when the step was completed
+func (p *Update) CompletedAt(t time.Time) *Update {
+	p.p.CompletedAt = t
+
+	return p
+}
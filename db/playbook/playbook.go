@@ -0,0 +1,54 @@
+package playbook
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Info represents the columns in the playbook_steps table
+type Info struct {
+	SerialNumber string    `json:"serial_number"`
+	Step         string    `json:"step"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+type PI []Info
+
+func (p PI) Empty() bool {
+	return len(p) == 0
+}
+
+type Config struct {
+	db  db.Conn
+	ctx context.Context
+	log logger.Logger
+	st  sq.StatementBuilderType
+}
+
+const table = "playbook_steps"
+
+var columns = []string{
+	"serial_number",
+	"step",
+	"completed_at",
+}
+
+// Playbook returns a new client used to interact with the playbook_steps table
+func Playbook(d db.Store, l *logger.Logger) *Config {
+	conn, err := d.Acquire(context.Background())
+	if err != nil {
+		l.Info().AnErr("acquiring connection", err).Msg("failed to acquire lock")
+		return nil
+	}
+
+	return &Config{
+		ctx: context.Background(),
+		db:  conn,
+		log: logger.ChildLogger("db/playbook", l),
+		st:  sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
@@ -0,0 +1,87 @@
+package playbook
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/johnmikee/cuebert/db"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Query holds the configuration for the building and executing the query.
+type Query struct {
+	db  db.Conn
+	log logger.Logger
+	sql sq.SelectBuilder
+	st  sq.StatementBuilderType
+}
+
+// Query returns a new client used to interact with specific columns
+// in the playbook_steps table.
+func (c *Config) Query() *Query {
+	return &Query{
+		db:  c.db,
+		log: c.log,
+		st:  c.st,
+	}
+}
+
+// Query executes the query against the db with built query.
+func (q *Query) Query() (PI, error) {
+	sql, args, err := q.sql.ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("sql generation failed %w", err)
+	}
+
+	q.log.Trace().Str("query", sql).Msg("composed sql query")
+
+	steps := []Info{}
+
+	rows, err := q.db.Query(
+		context.Background(),
+		sql, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("playbook query failed %w", err)
+	}
+
+	for rows.Next() {
+		var p Info
+
+		err = rows.Scan(
+			&p.SerialNumber,
+			&p.Step,
+			&p.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("playbook row query failed %w", err)
+		}
+		steps = append(steps, p)
+	}
+
+	q.db.Release()
+
+	return steps, nil
+}
+
+// All returns all completed steps in the table
+func (q *Query) All() *Query {
+	q.sql = q.st.Select(columns...).From(table)
+
+	return q
+}
+
+// Serial queries the playbook_steps table for the steps completed for the serial numbers
+func (q *Query) Serial(s ...string) *Query {
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"serial_number": s})
+
+	return q
+}
+
+// Step queries the playbook_steps table for the serials that completed a step
+func (q *Query) Step(s ...string) *Query {
+	q.sql = q.st.Select(columns...).From(table).Where(sq.Eq{"step": s})
+
+	return q
+}
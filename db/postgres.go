@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres is the Store backed by a pgx pool.
+type Postgres struct {
+	Pool *pgxpool.Pool
+}
+
+// NewPostgres returns a Store for the pool.
+func NewPostgres(pool *pgxpool.Pool) *Postgres {
+	return &Postgres{Pool: pool}
+}
+
+// AsPostgres returns the postgres store under any wrappers and false if the
+// store is not postgres.
+func AsPostgres(s Store) (*Postgres, bool) {
+	for s != nil {
+		if p, ok := s.(*Postgres); ok {
+			return p, true
+		}
+
+		w, ok := s.(Wrapper)
+		if !ok {
+			return nil, false
+		}
+		s = w.Unwrap()
+	}
+
+	return nil, false
+}
+
+// Acquire implements Store.
+func (p *Postgres) Acquire(ctx context.Context) (Conn, error) {
+	conn, err := p.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pgConn{conn: conn}, nil
+}
+
+// Ping implements Store.
+func (p *Postgres) Ping(ctx context.Context) error {
+	return p.Pool.Ping(ctx)
+}
+
+// Driver implements Store.
+func (p *Postgres) Driver() string {
+	return DriverPostgres
+}
+
+// Close implements Store.
+func (p *Postgres) Close() {
+	p.Pool.Close()
+}
+
+type pgConn struct {
+	conn *pgxpool.Conn
+}
+
+func (c *pgConn) Exec(ctx context.Context, sql string, args ...any) (Result, error) {
+	return c.conn.Exec(ctx, sql, args...)
+}
+
+func (c *pgConn) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
+	return c.conn.Query(ctx, sql, args...)
+}
+
+func (c *pgConn) Batch(ctx context.Context, stmts []Statement) (int64, error) {
+	batch := &pgx.Batch{}
+	for i := range stmts {
+		batch.Queue(stmts[i].SQL, stmts[i].Args...)
+	}
+
+	res := c.conn.SendBatch(ctx, batch)
+	defer res.Close()
+
+	var count int64
+	for i := 0; i < batch.Len(); i++ {
+		tag, err := res.Exec()
+		if err != nil {
+			return count, err
+		}
+		count += tag.RowsAffected()
+	}
+
+	return count, nil
+}
+
+func (c *pgConn) Release() {
+	c.conn.Release()
+}
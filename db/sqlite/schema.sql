@@ -0,0 +1,155 @@
+-- the sqlite schema matches the postgres tables after every migration in
+-- db/migrate/migrations. it is applied each time the store is opened so every
+-- statement must be safe to run again.
+CREATE TABLE IF NOT EXISTS users (
+    user_mdm_id character varying(255) NOT NULL,
+    user_long_name character varying(255),
+    user_email character varying(255),
+    user_slack_id character varying(255),
+    tz_offset int,
+    created_at timestamp,
+    updated_at timestamp,
+    PRIMARY KEY (user_slack_id)
+);
+
+CREATE TABLE IF NOT EXISTS devices (
+    device_id character varying(255) NOT NULL,
+    device_name character varying(255) NOT NULL,
+    model character varying(255) NOT NULL,
+    serial_number character varying(255) NOT NULL,
+    platform character varying(255) NOT NULL,
+    os_version character varying(255) NOT NULL,
+    user_name character varying(255) NOT NULL,
+    user_mdm_id character varying(255) NOT NULL,
+    last_check_in timestamp,
+    created_at timestamp,
+    updated_at timestamp,
+    PRIMARY KEY (device_id)
+);
+
+CREATE TABLE IF NOT EXISTS exclusions (
+    approved boolean,
+    serial_number character varying(255) NOT NULL,
+    user_email character varying(255) NOT NULL,
+    reason character varying(255) NOT NULL,
+    until timestamp NOT NULL,
+    created_at timestamp,
+    updated_at timestamp,
+    PRIMARY KEY (serial_number)
+);
+
+CREATE TABLE IF NOT EXISTS bot_results (
+    slack_id character varying(255) NOT NULL,
+    user_email character varying(255),
+    manager_slack_id character varying(255),
+    first_ack boolean,
+    first_ack_time timestamp NOT NULL,
+    first_message_sent boolean,
+    first_message_sent_at timestamp NOT NULL,
+    first_message_waiting boolean,
+    manager_message_sent boolean,
+    manager_message_sent_at timestamp NOT NULL,
+    full_name character varying(255),
+    delay_at timestamp NOT NULL,
+    delay_date character varying(255),
+    delay_time character varying(255),
+    delay_sent boolean,
+    reminder_interval int NOT NULL DEFAULT 0,
+    reminder_waiting boolean NOT NULL DEFAULT false,
+    serial_number character varying(255),
+    tz_offset int,
+    created_at timestamp,
+    updated_at timestamp,
+    campaign_id character varying(255) NOT NULL DEFAULT 'default',
+    PRIMARY KEY (serial_number, campaign_id)
+);
+
+CREATE TABLE IF NOT EXISTS playbook_steps (
+    serial_number character varying(255) NOT NULL,
+    step character varying(255) NOT NULL,
+    completed_at timestamp NOT NULL,
+    PRIMARY KEY (serial_number, step)
+);
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    id character varying(255) NOT NULL,
+    name character varying(255),
+    required_os character varying(255) NOT NULL,
+    deadline character varying(255) NOT NULL,
+    cutoff_time character varying(255) NOT NULL,
+    status character varying(255) NOT NULL,
+    created_by character varying(255),
+    created_at timestamp,
+    updated_at timestamp,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS compliance_history (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    serial_number character varying(255) NOT NULL,
+    campaign_id character varying(255) NOT NULL,
+    slack_id character varying(255),
+    user_email character varying(255),
+    full_name character varying(255),
+    required_os character varying(255),
+    os_version character varying(255),
+    first_message_sent_at timestamp NOT NULL,
+    first_ack_time timestamp NOT NULL,
+    manager_message_sent_at timestamp NOT NULL,
+    completed_at timestamp NOT NULL,
+    created_at timestamp
+);
+
+CREATE INDEX IF NOT EXISTS compliance_history_completed_at ON compliance_history (completed_at);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    actor character varying(255) NOT NULL,
+    action character varying(255) NOT NULL,
+    target_user character varying(255) NOT NULL DEFAULT '',
+    target_serial character varying(255) NOT NULL DEFAULT '',
+    before_state text NOT NULL DEFAULT '',
+    after_state text NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_created_at ON audit_events (created_at);
+
+-- keep updated_at current on every update. the postgres triggers also publish
+-- the changes with pg_notify, which sqlite has no equivalent for.
+CREATE TRIGGER IF NOT EXISTS update_device_time
+AFTER UPDATE ON devices FOR EACH ROW
+BEGIN
+    UPDATE devices SET updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now') WHERE rowid = NEW.rowid;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_user_time
+AFTER UPDATE ON users FOR EACH ROW
+BEGIN
+    UPDATE users SET updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now') WHERE rowid = NEW.rowid;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_bot_time
+AFTER UPDATE ON bot_results FOR EACH ROW
+BEGIN
+    UPDATE bot_results SET updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now') WHERE rowid = NEW.rowid;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_exclusion_time
+AFTER UPDATE ON exclusions FOR EACH ROW
+BEGIN
+    UPDATE exclusions SET updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now') WHERE rowid = NEW.rowid;
+END;
+
+-- audit_events is append only.
+CREATE TRIGGER IF NOT EXISTS audit_events_no_update
+BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_events_no_delete
+BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append only');
+END;
@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"net/url"
+
+	"github.com/johnmikee/cuebert/db"
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite"
+)
+
+/*
+this package stores the tables in a single sqlite file so cuebert can run
+without a postgres server. the driver is pure go so the binary still builds
+without cgo. the schema is embedded and applied when the store is opened.
+*/
+
+//go:embed schema.sql
+var schema string
+
+// busyTimeout is how long, in milliseconds, a write waits on another
+// connection holding the database.
+const busyTimeout = 5000
+
+// Store is the db.Store backed by a sqlite file.
+type Store struct {
+	db   *sql.DB
+	path string
+}
+
+// Open opens or creates the database at path and applies the schema.
+func Open(path string) (*Store, error) {
+	if path == "" {
+		return nil, errors.New("must include the sqlite database path")
+	}
+
+	q := url.Values{}
+	q.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", busyTimeout))
+	q.Add("_pragma", "journal_mode(WAL)")
+
+	d, err := sql.Open("sqlite", "file:"+path+"?"+q.Encode())
+	if err != nil {
+		return nil, errors.Wrap(err, "opening sqlite database")
+	}
+
+	if _, err := d.Exec(schema); err != nil {
+		_ = d.Close()
+		return nil, errors.Wrap(err, "applying sqlite schema")
+	}
+
+	return &Store{db: d, path: path}, nil
+}
+
+// Path is the file the database is stored in.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Acquire implements db.Store.
+func (s *Store) Acquire(ctx context.Context) (db.Conn, error) {
+	c, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{c: c}, nil
+}
+
+// Ping implements db.Store.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Driver implements db.Store.
+func (s *Store) Driver() string {
+	return db.DriverSQLite
+}
+
+// Close implements db.Store.
+func (s *Store) Close() {
+	_ = s.db.Close()
+}
+
+type conn struct {
+	c *sql.Conn
+}
+
+func (c *conn) Exec(ctx context.Context, query string, args ...any) (db.Result, error) {
+	res, err := c.c.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	return result(n), nil
+}
+
+func (c *conn) Query(ctx context.Context, query string, args ...any) (db.Rows, error) {
+	r, err := c.c.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rows{r: r}, nil
+}
+
+func (c *conn) Batch(ctx context.Context, stmts []db.Statement) (int64, error) {
+	tx, err := c.c.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for i := range stmts {
+		res, err := tx.ExecContext(ctx, stmts[i].SQL, stmts[i].Args...)
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+		count += n
+	}
+
+	return count, tx.Commit()
+}
+
+func (c *conn) Release() {
+	_ = c.c.Close()
+}
+
+type result int64
+
+func (r result) RowsAffected() int64 {
+	return int64(r)
+}
+
+// rows adapts sql.Rows to db.Rows. like pgx they are closed once Next
+// returns false.
+type rows struct {
+	r *sql.Rows
+}
+
+func (r *rows) Next() bool {
+	return r.r.Next()
+}
+
+func (r *rows) Scan(dest ...any) error {
+	return r.r.Scan(dest...)
+}
+
+func (r *rows) Err() error {
+	return r.r.Err()
+}
+
+func (r *rows) Close() {
+	_ = r.r.Close()
+}
@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/db"
+)
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cue.db")
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s.Close()
+
+	// the schema is applied again on every open.
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopening error = %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+
+	if s.Driver() != db.DriverSQLite {
+		t.Errorf("Driver() = %s, want %s", s.Driver(), db.DriverSQLite)
+	}
+
+	if _, err := Open(""); err == nil {
+		t.Error("Open() with no path did not fail")
+	}
+}
+
+func TestBatch(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "cue.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	conn, err := s.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer conn.Release()
+
+	insert := "INSERT INTO playbook_steps (serial_number, step, completed_at) VALUES ($1, $2, $3)"
+	now := time.Now().UTC()
+
+	n, err := conn.Batch(ctx, []db.Statement{
+		{SQL: insert, Args: []any{"S1", "message", now}},
+		{SQL: insert, Args: []any{"S1", "manager", now}},
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("Batch() = %d, %v, want 2 rows", n, err)
+	}
+
+	// the duplicate step fails the batch so the first insert is rolled back.
+	_, err = conn.Batch(ctx, []db.Statement{
+		{SQL: insert, Args: []any{"S2", "message", now}},
+		{SQL: insert, Args: []any{"S1", "message", now}},
+	})
+	if err == nil {
+		t.Fatal("Batch() with a duplicate key did not fail")
+	}
+
+	rows, err := conn.Query(ctx, "SELECT serial_number, completed_at FROM playbook_steps ORDER BY step")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		var (
+			serial string
+			at     time.Time
+		)
+		if err := rows.Scan(&serial, &at); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if serial != "S1" || !at.Equal(now) {
+			t.Errorf("row = %s at %v, want S1 at %v", serial, at, now)
+		}
+		count++
+	}
+
+	if count != 2 {
+		t.Errorf("got %d rows, want 2", count)
+	}
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+)
+
+// Store is the storage the table packages read and write through. Postgres is
+// the default, SQLite lets cuebert run as a single binary.
+type Store interface {
+	// Acquire returns a connection which must be released once done.
+	Acquire(ctx context.Context) (Conn, error)
+	// Ping checks the store can be reached.
+	Ping(ctx context.Context) error
+	// Driver is the name of the store, ex: postgres or sqlite.
+	Driver() string
+	// Close closes every connection to the store.
+	Close()
+}
+
+// Conn is a single connection to a Store. Statements use $1 style
+// placeholders, which both stores accept.
+type Conn interface {
+	Exec(ctx context.Context, sql string, args ...any) (Result, error)
+	Query(ctx context.Context, sql string, args ...any) (Rows, error)
+	// Batch runs the statements together and returns the rows they touched.
+	// Postgres sends them in one round trip, SQLite runs them in a
+	// transaction.
+	Batch(ctx context.Context, stmts []Statement) (int64, error)
+	Release()
+}
+
+// Result is the outcome of an Exec.
+type Result interface {
+	RowsAffected() int64
+}
+
+// Rows are the rows returned by a Query. They are closed once Next returns
+// false.
+type Rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close()
+}
+
+// Statement is a query and its arguments to run in a Batch.
+type Statement struct {
+	SQL  string
+	Args []any
+}
+
+// Drivers are the stores cuebert can run with.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Wrapper is implemented by stores that decorate another store, such as
+// tracing, so the store underneath can still be found.
+type Wrapper interface {
+	Unwrap() Store
+}
+
+// Binder is implemented by stores that can tie the statements run on their
+// connections to a context, such as the span of the routine running them.
+type Binder interface {
+	WithContext(ctx context.Context) Store
+}
+
+// WithContext returns the store bound to ctx, or the store as is if it cannot
+// be bound.
+func WithContext(s Store, ctx context.Context) Store {
+	if b, ok := s.(Binder); ok {
+		return b.WithContext(ctx)
+	}
+
+	return s
+}
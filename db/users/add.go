@@ -4,8 +4,7 @@ import (
 	"context"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 	"github.com/johnmikee/cuebert/pkg/logger"
 	"github.com/pkg/errors"
@@ -15,45 +14,47 @@ type Update struct {
 	args  []interface{}
 	user  Info
 	query string
-	db    *pgxpool.Conn
+	db    db.Conn
 	st    sq.StatementBuilderType
 	ctx   context.Context
 	log   logger.Logger
 }
 
-// AddAllUsers should only be used in specific cases.
-//
-// - to initialize the DB with values
-// - if a table is dropped and rebuilt
-//
-// This will add all users passed to the DB.
+// AddAllUsers adds the users passed to the DB. Users that are already in the
+// table are updated with the snapshot so restarts keep every row.
 func (c *Config) AddAllUsers(us []Info) (int64, error) {
-	rows := [][]interface{}{}
+	batch := []db.Statement{}
 	for _, u := range us {
-		if u.MDMID != "" {
-			row := []interface{}{
+		if u.MDMID == "" {
+			continue
+		}
+
+		query, args, err := c.st.Insert(table).
+			Columns(columns...).
+			Values(
 				u.MDMID,
 				u.UserLongName,
 				u.UserEmail,
 				u.UserSlackID,
 				u.TZOffset,
 				helpers.UpdateTime(),
-				helpers.UpdateTime(),
-			}
-			rows = append(rows, row)
+				helpers.UpdateTime()).
+			Suffix(`ON CONFLICT (user_slack_id) DO UPDATE SET
+	user_mdm_id = EXCLUDED.user_mdm_id,
+	user_long_name = EXCLUDED.user_long_name,
+	user_email = EXCLUDED.user_email,
+	tz_offset = EXCLUDED.tz_offset,
+	updated_at = EXCLUDED.updated_at`).
+			ToSql()
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to build query")
 		}
+		batch = append(batch, db.Statement{SQL: query, Args: args})
 	}
 
-	copyCount, err := c.db.CopyFrom(
-		context.Background(),
-		pgx.Identifier{table},
-		columns,
-		pgx.CopyFromRows(rows))
-
-	c.db.Release()
-
-	return copyCount, err
+	defer c.db.Release()
 
+	return c.db.Batch(context.Background(), batch)
 }
 
 // Add initializes a new Update struct.
@@ -73,7 +74,7 @@ func (c *Config) Add() *Update {
 // Execute sends the statement to add the user after it has been composed.
 //
 // returns the connection which should be closed after checking the error.
-func (u *Update) Execute() (*pgxpool.Conn, error) {
+func (u *Update) Execute() (db.Conn, error) {
 	query, args, err := u.st.Insert(table).
 		Columns(columns...).
 		Values(
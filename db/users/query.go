@@ -6,14 +6,14 @@ import (
 	"strconv"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
 type Query struct {
 	sql sq.SelectBuilder
 	st  sq.StatementBuilderType
-	db  *pgxpool.Conn
+	db  db.Conn
 	log logger.Logger
 }
 
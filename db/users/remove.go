@@ -4,14 +4,14 @@ import (
 	"context"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
 type Remove struct {
 	sql sq.DeleteBuilder
 	st  sq.StatementBuilderType
-	db  *pgxpool.Conn
+	db  db.Conn
 
 	ctx context.Context
 	log logger.Logger
@@ -34,7 +34,7 @@ func (c *Config) Remove() *Remove {
 // Run sends the statement to remove the user after it has been composed.
 //
 // returns the connection which should be closed after checking the error.
-func (u *Remove) Run() (*pgxpool.Conn, error) {
+func (u *Remove) Run() (db.Conn, error) {
 	sql, args, err := u.sql.ToSql()
 	if err != nil {
 		return nil, err
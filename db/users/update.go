@@ -1,7 +1,7 @@
 package users
 
 import (
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/db/parser"
 )
 
@@ -22,7 +22,7 @@ func (c *Config) Update() *Update {
 // Update sends the statement to update the device after it has been composed.
 //
 // returns the connection which should be closed after checking the error.
-func (u *Update) Send() (*pgxpool.Conn, error) {
+func (u *Update) Send() (db.Conn, error) {
 	_, err := u.db.Exec(
 		u.ctx,
 		u.query, u.args...)
@@ -5,7 +5,6 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/johnmikee/cuebert/db"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
@@ -29,7 +28,7 @@ func (u UI) Empty() bool {
 
 // Config is used to interact with the users table
 type Config struct {
-	db  *pgxpool.Conn
+	db  db.Conn
 	ctx context.Context
 	log logger.Logger
 	st  sq.StatementBuilderType
@@ -48,7 +47,7 @@ var columns = []string{
 }
 
 // User returns a new client used to interact with the users table
-func User(d *db.DB, l *logger.Logger) *Config {
+func User(d db.Store, l *logger.Logger) *Config {
 	conn, err := d.Acquire(context.Background())
 	if err != nil {
 		l.Info().AnErr("acquiring connection", err).Msg("failed to acquire lock")
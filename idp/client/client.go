@@ -2,7 +2,13 @@ package client
 
 import (
 	"github.com/johnmikee/cuebert/idp"
-	"github.com/johnmikee/cuebert/idp/okta"
+
+	// built in providers register themselves with idp.Register.
+	_ "github.com/johnmikee/cuebert/idp/entra"
+	_ "github.com/johnmikee/cuebert/idp/google"
+	_ "github.com/johnmikee/cuebert/idp/ldap"
+	_ "github.com/johnmikee/cuebert/idp/okta"
+	_ "github.com/johnmikee/cuebert/idp/scim"
 )
 
 type Config struct {
@@ -14,21 +20,21 @@ type IDP struct {
 	Config idp.Config
 }
 
-func New(i *IDP) idp.Provider {
+// New creates a new IDP provider based on the provided IDP configuration.
+// Providers are looked up in the idp registry, so out of tree providers only
+// need to be imported for their name to be accepted. It returns an error
+// listing the registered providers if the IDP type is unknown.
+func New(i *IDP) (idp.Provider, error) {
+	provider, err := idp.New(i.IDP)
+	if err != nil {
+		return nil, err
+	}
+
 	config := Config{
-		IDPProvider: createIDPProvider(i.IDP),
+		IDPProvider: provider,
 	}
 
 	config.IDPProvider.Setup(i.Config)
 
-	return config.IDPProvider
-}
-
-func createIDPProvider(providerName idp.IDP) idp.Provider {
-	switch providerName {
-	case idp.Okta:
-		return &okta.Client{}
-	default:
-		return nil
-	}
+	return config.IDPProvider, nil
 }
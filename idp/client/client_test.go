@@ -0,0 +1,78 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/johnmikee/cuebert/idp"
+	"github.com/johnmikee/cuebert/idp/entra"
+	"github.com/johnmikee/cuebert/idp/google"
+	"github.com/johnmikee/cuebert/idp/ldap"
+	"github.com/johnmikee/cuebert/idp/okta"
+	"github.com/johnmikee/cuebert/idp/scim"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		idp  idp.IDP
+		want func(idp.Provider) bool
+	}{
+		{
+			name: "entra",
+			idp:  idp.Entra,
+			want: func(p idp.Provider) bool { _, ok := p.(*entra.Client); return ok },
+		},
+		{
+			name: "google",
+			idp:  idp.Google,
+			want: func(p idp.Provider) bool { _, ok := p.(*google.Client); return ok },
+		},
+		{
+			name: "ldap",
+			idp:  idp.LDAP,
+			want: func(p idp.Provider) bool { _, ok := p.(*ldap.Client); return ok },
+		},
+		{
+			name: "okta",
+			idp:  idp.Okta,
+			want: func(p idp.Provider) bool { _, ok := p.(*okta.Client); return ok },
+		},
+		{
+			name: "scim",
+			idp:  idp.SCIM,
+			want: func(p idp.Provider) bool { _, ok := p.(*scim.Client); return ok },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := New(&IDP{
+				IDP:    tt.idp,
+				Config: idp.Config{Domain: "example.com"},
+			})
+			if err != nil {
+				t.Fatalf("New() returned error: %v", err)
+			}
+
+			if !tt.want(provider) {
+				t.Errorf("unexpected provider type %T", provider)
+			}
+		})
+	}
+}
+
+func TestNewUnsupported(t *testing.T) {
+	provider, err := New(&IDP{IDP: idp.IDP("unknown")})
+	if err == nil {
+		t.Fatal("Expected an error for an unsupported provider")
+	}
+
+	if !strings.Contains(err.Error(), "okta") {
+		t.Errorf("Expected the error to list the registered providers, got: %v", err)
+	}
+
+	if provider != nil {
+		t.Errorf("Expected a nil provider, but got %T", provider)
+	}
+}
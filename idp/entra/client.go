@@ -0,0 +1,73 @@
+package entra
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/johnmikee/cuebert/idp"
+	"github.com/johnmikee/cuebert/pkg/graph"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+func init() {
+	idp.Register(idp.Entra, func() idp.Provider { return &Client{} })
+}
+
+// Client represents the Entra ID client.
+//
+// The app registration used needs the User.Read.All and GroupMember.Read.All
+// application permissions.
+type Client struct {
+	graph *graph.Client
+	log   logger.Logger
+}
+
+// Config represents the configuration for the Entra ID client.
+type Config struct {
+	// Tenant is the tenant the app registration lives in. It is only
+	// used to build the token url if TokenURL is empty.
+	Tenant       string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	URL          string
+
+	Client *http.Client
+	Log    *logger.Logger
+}
+
+// Setup implements idp.Provider.
+//
+// User and Password are the client id and secret of the app registration.
+// If TokenURL is not set the token endpoint is built from Domain as the tenant.
+func (c *Client) Setup(i idp.Config) {
+	*c = *NewClient(&Config{
+		Tenant:       i.Domain,
+		TokenURL:     i.TokenURL,
+		ClientID:     i.User,
+		ClientSecret: i.Password,
+		URL:          i.URL,
+		Client:       i.Client,
+		Log:          &i.Log,
+	})
+}
+
+// WithContext implements idp.Binder.
+func (c *Client) WithContext(ctx context.Context) idp.Provider {
+	return &Client{graph: c.graph.WithContext(ctx), log: c.log}
+}
+
+// NewClient returns a pointer with the Client after validating the arguments passed.
+func NewClient(c *Config) *Client {
+	return &Client{
+		graph: graph.New(&graph.Config{
+			Tenant:       c.Tenant,
+			TokenURL:     c.TokenURL,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			BaseURL:      c.URL,
+			Client:       c.Client,
+		}),
+		log: logger.ChildLogger("idp/entra", c.Log),
+	}
+}
@@ -0,0 +1,121 @@
+package entra
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnmikee/cuebert/idp"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+func newGraphServer(t *testing.T) *httptest.Server {
+	var srv *httptest.Server
+	disabled := false
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "graph-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("/v1.0/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer graph-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Query().Get("$skiptoken") == "" {
+			if r.URL.Query().Get("$expand") == "" {
+				t.Error("expected the manager to be expanded")
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"value": []User{
+					{ID: "1", GivenName: "Big", Surname: "Boss", Mail: "boss@example.com", UserPrincipalName: "boss@example.com"},
+					{
+						ID:                "2",
+						GivenName:         "Hard",
+						Surname:           "Worker",
+						UserPrincipalName: "worker@example.com",
+						JobTitle:          "Engineer",
+						Department:        "IT",
+						Manager:           &Manager{ID: "1", DisplayName: "Big Boss", Mail: "boss@example.com"},
+					},
+				},
+				"@odata.nextLink": srv.URL + "/v1.0/users?$skiptoken=2",
+			})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"value": []User{
+				{ID: "3", UserPrincipalName: "gone@example.com", AccountEnabled: &disabled},
+			},
+		})
+	})
+	mux.HandleFunc("/v1.0/groups/admins/transitiveMembers/microsoft.graph.user", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"value": []User{
+				{ID: "1", Mail: "boss@example.com"},
+				{ID: "4", UserPrincipalName: "nested@example.com"},
+				{ID: "3", UserPrincipalName: "gone@example.com", AccountEnabled: &disabled},
+			},
+		})
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func testClient(srv *httptest.Server) *Client {
+	c := &Client{}
+	c.Setup(idp.Config{
+		URL:      srv.URL + "/v1.0",
+		TokenURL: srv.URL + "/token",
+		User:     "id",
+		Password: "secret",
+		Log:      logger.NewLogger(&logger.Config{Level: "error"}),
+	})
+
+	return c
+}
+
+func TestGetAllUsers(t *testing.T) {
+	c := testClient(newGraphServer(t))
+
+	users, err := c.GetAllUsers()
+	if err != nil {
+		t.Fatalf("GetAllUsers() returned error: %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("GetAllUsers() returned %d users, want 2", len(users))
+	}
+
+	w := users[1].Profile
+	if w.Email != "worker@example.com" ||
+		w.ManagerID != "boss@example.com" ||
+		w.Manager != "Big Boss" ||
+		w.Title != "Engineer" ||
+		w.Department != "IT" {
+		t.Errorf("unexpected profile: %+v", w)
+	}
+
+	if users[0].Profile.ManagerID != "" {
+		t.Errorf("expected no manager, got: %s", users[0].Profile.ManagerID)
+	}
+}
+
+func TestGetAdminGroup(t *testing.T) {
+	c := testClient(newGraphServer(t))
+
+	members, err := c.GetAdminGroup("admins")
+	if err != nil {
+		t.Fatalf("GetAdminGroup() returned error: %v", err)
+	}
+
+	if len(members) != 2 || members[0] != "boss@example.com" || members[1] != "nested@example.com" {
+		t.Errorf("unexpected members: %v", members)
+	}
+}
@@ -0,0 +1,43 @@
+package entra
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/johnmikee/cuebert/pkg/helpers"
+)
+
+// GetAdminGroup implements idp.Provider. Members of nested groups are
+// resolved through transitiveMembers.
+func (c *Client) GetAdminGroup(groupID string) ([]string, error) {
+	q := url.Values{}
+	q.Set("$select", "id,mail,userPrincipalName,accountEnabled")
+	q.Set("$top", "999")
+
+	u := fmt.Sprintf("groups/%s/transitiveMembers/microsoft.graph.user?%s", url.PathEscape(groupID), q.Encode())
+
+	emails := []string{}
+
+	err := c.graph.List(u, func(value json.RawMessage) error {
+		var page []User
+		if err := json.Unmarshal(value, &page); err != nil {
+			return err
+		}
+
+		for i := range page {
+			if page[i].AccountEnabled != nil && !*page[i].AccountEnabled {
+				continue
+			}
+			emails = append(emails, email(page[i].Mail, page[i].UserPrincipalName))
+		}
+
+		return nil
+	})
+	if err != nil {
+		c.log.Err(err).Str("group", groupID).Msg("error listing group members")
+		return nil, err
+	}
+
+	return helpers.RemoveEmpty(emails), nil
+}
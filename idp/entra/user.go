@@ -0,0 +1,107 @@
+package entra
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/johnmikee/cuebert/idp"
+)
+
+// userFields are the user properties needed to fill out an idp.User.
+const userFields = "id,givenName,surname,displayName,mail,userPrincipalName,jobTitle,department,accountEnabled,createdDateTime,userType"
+
+// User is the subset of the Graph user resource used by cuebert.
+//   - https://learn.microsoft.com/en-us/graph/api/user-list
+type User struct {
+	ID                string     `json:"id"`
+	GivenName         string     `json:"givenName"`
+	Surname           string     `json:"surname"`
+	DisplayName       string     `json:"displayName"`
+	Mail              string     `json:"mail"`
+	UserPrincipalName string     `json:"userPrincipalName"`
+	JobTitle          string     `json:"jobTitle"`
+	Department        string     `json:"department"`
+	AccountEnabled    *bool      `json:"accountEnabled"`
+	CreatedDateTime   *time.Time `json:"createdDateTime"`
+	UserType          string     `json:"userType"`
+	Manager           *Manager   `json:"manager"`
+}
+
+// Manager is the expanded manager of a user.
+type Manager struct {
+	ID                string `json:"id"`
+	DisplayName       string `json:"displayName"`
+	Mail              string `json:"mail"`
+	UserPrincipalName string `json:"userPrincipalName"`
+}
+
+// email returns the mail attribute falling back to the upn, which is
+// usually the same for cloud only accounts.
+func email(mail, upn string) string {
+	if mail != "" {
+		return mail
+	}
+
+	return upn
+}
+
+// GetAllUsers implements idp.Provider. Disabled accounts are skipped.
+func (c *Client) GetAllUsers() ([]idp.User, error) {
+	q := url.Values{}
+	q.Set("$select", userFields)
+	q.Set("$expand", "manager($select=id,displayName,mail,userPrincipalName)")
+	q.Set("$top", "100")
+
+	results := []idp.User{}
+
+	err := c.graph.List("users?"+q.Encode(), func(value json.RawMessage) error {
+		var page []User
+		if err := json.Unmarshal(value, &page); err != nil {
+			return err
+		}
+
+		for i := range page {
+			if page[i].AccountEnabled != nil && !*page[i].AccountEnabled {
+				continue
+			}
+			results = append(results, transform(&page[i]))
+		}
+
+		c.log.Trace().Int("users", len(results)).Msg("checking next page..")
+		return nil
+	})
+	if err != nil {
+		c.log.Err(err).Msg("error listing users")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func transform(u *User) idp.User {
+	user := idp.User{
+		ID:     u.ID,
+		Status: "ACTIVE",
+		Profile: idp.Profile{
+			LastName:   u.Surname,
+			Title:      u.JobTitle,
+			Login:      u.UserPrincipalName,
+			FirstName:  u.GivenName,
+			UserType:   u.UserType,
+			Department: u.Department,
+			Email:      email(u.Mail, u.UserPrincipalName),
+		},
+	}
+
+	if u.CreatedDateTime != nil {
+		user.Activated = *u.CreatedDateTime
+	}
+
+	if u.Manager != nil {
+		user.Profile.Manager = u.Manager.DisplayName
+		user.Profile.ManagerID = email(u.Manager.Mail, u.Manager.UserPrincipalName)
+	}
+
+	return user
+}
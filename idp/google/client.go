@@ -0,0 +1,204 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnmikee/cuebert/idp"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/johnmikee/cuebert/pkg/logger"
+	"github.com/johnmikee/cuebert/pkg/oauth"
+)
+
+func init() {
+	idp.Register(idp.Google, func() idp.Provider { return &Client{} })
+}
+
+// defaultURL is the Admin SDK Directory API base used when no URL is passed.
+const defaultURL = "https://admin.googleapis.com/admin/directory/v1/"
+
+// scopes are the read only scopes needed for users and group members.
+var scopes = []string{
+	"https://www.googleapis.com/auth/admin.directory.user.readonly",
+	"https://www.googleapis.com/auth/admin.directory.group.member.readonly",
+}
+
+// tokenSource returns an access token for the directory api.
+type tokenSource interface {
+	TokenContext(ctx context.Context) (string, error)
+	Invalidate()
+}
+
+// Client represents the Google Workspace client.
+type Client struct {
+	baseURL string
+	domain  string
+	creds   tokenSource
+	err     error
+
+	client *http.Client
+	log    logger.Logger
+	ctx    context.Context
+}
+
+// Config represents the configuration for the Google Workspace client.
+type Config struct {
+	// Domain limits users to a single Workspace domain. If empty every
+	// domain for the customer is returned.
+	Domain string
+	URL    string
+	// Key is the service account json key or a path to it.
+	Key string
+	// Subject is the admin the service account impersonates through
+	// domain-wide delegation.
+	Subject string
+	// TokenURL overrides the token_uri in the key.
+	TokenURL string
+
+	Client *http.Client
+	Log    *logger.Logger
+}
+
+// Setup implements idp.Provider.
+//
+// Token is the service account key (or a path to it) and User is the admin
+// to impersonate.
+func (c *Client) Setup(i idp.Config) {
+	*c = *NewClient(&Config{
+		Domain:   i.Domain,
+		URL:      i.URL,
+		Key:      i.Token,
+		Subject:  i.User,
+		TokenURL: i.TokenURL,
+		Client:   i.Client,
+		Log:      &i.Log,
+	})
+}
+
+// NewClient returns a pointer with the Client after validating the arguments passed.
+//
+// If the service account key cannot be read the error is returned on the first request.
+func NewClient(c *Config) *Client {
+	base := c.URL
+	if base == "" {
+		base = defaultURL
+	}
+
+	client := &Client{
+		baseURL: helpers.URLShaper(base, ""),
+		domain:  c.Domain,
+		client:  httpClient(c.Client),
+		log:     logger.ChildLogger("idp/google", c.Log),
+		ctx:     context.Background(),
+	}
+
+	key, err := readKey(c.Key, c.TokenURL)
+	if err != nil {
+		client.err = err
+		client.log.Err(err).Msg("could not read service account key")
+		return client
+	}
+
+	sa, err := oauth.NewServiceAccount(key, c.Subject, scopes, client.client)
+	if err != nil {
+		client.err = err
+		client.log.Err(err).Msg("could not load service account key")
+		return client
+	}
+	client.creds = sa
+
+	return client
+}
+
+// WithContext implements idp.Binder.
+func (c *Client) WithContext(ctx context.Context) idp.Provider {
+	bound := *c
+	bound.ctx = ctx
+
+	return &bound
+}
+
+// readKey returns the key as is if it looks like json, otherwise it is read
+// from disk. If tokenURL is set it replaces the token_uri in the key.
+func readKey(key, tokenURL string) ([]byte, error) {
+	var b []byte
+
+	if strings.HasPrefix(strings.TrimSpace(key), "{") {
+		b = []byte(key)
+	} else {
+		var err error
+		b, err = os.ReadFile(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading service account key")
+		}
+	}
+
+	if tokenURL == "" {
+		return b, nil
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, errors.Wrap(err, "parsing service account key")
+	}
+	m["token_uri"] = tokenURL
+
+	return json.Marshal(m)
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+
+	return &http.Client{
+		Timeout: time.Minute,
+	}
+}
+
+func (c *Client) newRequest(method, url string) (*http.Request, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, fmt.Sprintf("%s%s", c.baseURL, strings.TrimPrefix(url, "/")), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.creds.TokenContext(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", helpers.TokenValidator(token, "Bearer"))
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request, v interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		c.creds.Invalidate()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("error during request. status code=%d error: %s", resp.StatusCode, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,156 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+type staticToken string
+
+func (s staticToken) TokenContext(context.Context) (string, error) { return string(s), nil }
+func (s staticToken) Invalidate()                                  {}
+
+func newDirectoryServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ya29" || r.URL.Query().Get("domain") != "example.com" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Query().Get("pageToken") {
+		case "":
+			_ = json.NewEncoder(w).Encode(Users{
+				Users: []User{
+					{
+						ID:           "1",
+						PrimaryEmail: "boss@example.com",
+						Name:         Name{GivenName: "Big", FamilyName: "Boss", FullName: "Big Boss"},
+					},
+					{
+						ID:           "2",
+						PrimaryEmail: "worker@example.com",
+						Name:         Name{GivenName: "Hard", FamilyName: "Worker", FullName: "Hard Worker"},
+						Organizations: []Organization{
+							{Title: "old", Department: "old"},
+							{Title: "Engineer", Department: "IT", Primary: true},
+						},
+						Relations: []Relation{
+							{Type: "assistant", Value: "someone@example.com"},
+							{Type: "manager", Value: "boss@example.com"},
+						},
+					},
+				},
+				NextPageToken: "page2",
+			})
+		case "page2":
+			_ = json.NewEncoder(w).Encode(Users{
+				Users: []User{
+					{
+						ID:           "3",
+						PrimaryEmail: "contractor@example.com",
+						Relations:    []Relation{{Type: "manager", Value: "outside@partner.com"}},
+					},
+					{ID: "4", PrimaryEmail: "gone@example.com", Suspended: true},
+				},
+			})
+		}
+	})
+	mux.HandleFunc("/groups/admins@example.com/members", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("includeDerivedMembership") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if r.URL.Query().Get("pageToken") == "" {
+			_ = json.NewEncoder(w).Encode(Members{
+				Members: []Member{
+					{Email: "boss@example.com", Type: "USER", Status: "ACTIVE"},
+					{Email: "nested@example.com", Type: "GROUP"},
+				},
+				NextPageToken: "next",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Members{
+			Members: []Member{
+				{Email: "worker@example.com", Type: "USER", Status: "ACTIVE"},
+				{Email: "gone@example.com", Type: "USER", Status: "SUSPENDED"},
+			},
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func testClient(srv *httptest.Server) *Client {
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+	c := NewClient(&Config{
+		Domain: "example.com",
+		URL:    srv.URL,
+		Key:    `{}`,
+		Log:    &log,
+	})
+	c.err = nil
+	c.creds = staticToken("ya29")
+
+	return c
+}
+
+func TestGetAllUsers(t *testing.T) {
+	c := testClient(newDirectoryServer(t))
+
+	users, err := c.GetAllUsers()
+	if err != nil {
+		t.Fatalf("GetAllUsers() returned error: %v", err)
+	}
+
+	if len(users) != 3 {
+		t.Fatalf("GetAllUsers() returned %d users, want 3", len(users))
+	}
+
+	w := users[1].Profile
+	if w.Email != "worker@example.com" ||
+		w.ManagerID != "boss@example.com" ||
+		w.Manager != "Big Boss" ||
+		w.Title != "Engineer" ||
+		w.Department != "IT" ||
+		w.FirstName != "Hard" ||
+		w.LastName != "Worker" {
+		t.Errorf("unexpected profile: %+v", w)
+	}
+
+	if users[2].Profile.Manager != "outside@partner.com" {
+		t.Errorf("expected the manager email when the manager is unknown, got: %s", users[2].Profile.Manager)
+	}
+}
+
+func TestGetAdminGroup(t *testing.T) {
+	c := testClient(newDirectoryServer(t))
+
+	members, err := c.GetAdminGroup("admins@example.com")
+	if err != nil {
+		t.Fatalf("GetAdminGroup() returned error: %v", err)
+	}
+
+	if len(members) != 2 || members[0] != "boss@example.com" || members[1] != "worker@example.com" {
+		t.Errorf("unexpected members: %v", members)
+	}
+}
+
+func TestInvalidKey(t *testing.T) {
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+	c := NewClient(&Config{Key: `{"client_email": "a"}`, Log: &log})
+
+	if _, err := c.GetAllUsers(); err == nil {
+		t.Error("expected an error with an invalid service account key")
+	}
+}
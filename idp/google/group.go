@@ -0,0 +1,64 @@
+package google
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/johnmikee/cuebert/pkg/helpers"
+)
+
+// Members is a page of results from the group members endpoint.
+//   - https://developers.google.com/admin-sdk/directory/reference/rest/v1/members/list
+type Members struct {
+	Members       []Member `json:"members"`
+	NextPageToken string   `json:"nextPageToken"`
+}
+
+// Member is a single member of a group.
+type Member struct {
+	ID     string `json:"id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// GetAdminGroup implements idp.Provider. The group id may be the group
+// email or its unique id. Members of nested groups are included.
+func (c *Client) GetAdminGroup(groupID string) ([]string, error) {
+	emails := []string{}
+	pageToken := ""
+
+	for {
+		q := url.Values{}
+		q.Set("maxResults", "200")
+		q.Set("includeDerivedMembership", "true")
+		if pageToken != "" {
+			q.Set("pageToken", pageToken)
+		}
+
+		req, err := c.newRequest(http.MethodGet, fmt.Sprintf("groups/%s/members?%s", url.PathEscape(groupID), q.Encode()))
+		if err != nil {
+			return nil, err
+		}
+
+		var page Members
+		if err := c.do(req, &page); err != nil {
+			return nil, err
+		}
+
+		for _, m := range page.Members {
+			if m.Type == "USER" && (m.Status == "" || m.Status == "ACTIVE") {
+				emails = append(emails, m.Email)
+			}
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	return helpers.RemoveEmpty(emails), nil
+}
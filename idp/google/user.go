@@ -0,0 +1,174 @@
+package google
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/johnmikee/cuebert/idp"
+)
+
+// Users is a page of results from the users endpoint.
+//   - https://developers.google.com/admin-sdk/directory/reference/rest/v1/users/list
+type Users struct {
+	Users         []User `json:"users"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// User is the subset of the directory user resource used by cuebert.
+type User struct {
+	ID            string         `json:"id"`
+	PrimaryEmail  string         `json:"primaryEmail"`
+	Name          Name           `json:"name"`
+	Suspended     bool           `json:"suspended"`
+	Archived      bool           `json:"archived"`
+	CreationTime  time.Time      `json:"creationTime"`
+	Organizations []Organization `json:"organizations"`
+	Relations     []Relation     `json:"relations"`
+}
+
+// Name holds the users name.
+type Name struct {
+	GivenName  string `json:"givenName"`
+	FamilyName string `json:"familyName"`
+	FullName   string `json:"fullName"`
+}
+
+// Organization holds the users title and department.
+type Organization struct {
+	Title       string `json:"title"`
+	Department  string `json:"department"`
+	Primary     bool   `json:"primary"`
+	Description string `json:"description"`
+}
+
+// Relation ties the user to another person. The manager relation
+// holds the managers email as the value.
+type Relation struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// GetAllUsers implements idp.Provider.
+func (c *Client) GetAllUsers() ([]idp.User, error) {
+	users, err := c.listUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	for i := range users {
+		names[strings.ToLower(users[i].PrimaryEmail)] = users[i].Name.FullName
+	}
+
+	results := []idp.User{}
+	for i := range users {
+		if users[i].Suspended || users[i].Archived {
+			continue
+		}
+		results = append(results, transform(&users[i], names))
+	}
+
+	return results, nil
+}
+
+func (c *Client) listUsers() ([]User, error) {
+	results := []User{}
+	pageToken := ""
+
+	for {
+		q := url.Values{}
+		q.Set("maxResults", "500")
+		q.Set("projection", "full")
+		q.Set("orderBy", "email")
+		if c.domain != "" {
+			q.Set("domain", c.domain)
+		} else {
+			q.Set("customer", "my_customer")
+		}
+		if pageToken != "" {
+			q.Set("pageToken", pageToken)
+		}
+
+		req, err := c.newRequest(http.MethodGet, "users?"+q.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		var page Users
+		if err := c.do(req, &page); err != nil {
+			return nil, err
+		}
+		results = append(results, page.Users...)
+
+		if page.NextPageToken == "" {
+			c.log.Trace().Msg("no more responses from google")
+			break
+		}
+		pageToken = page.NextPageToken
+		c.log.Trace().Msg("checking next page..")
+	}
+
+	return results, nil
+}
+
+// manager returns the email of the users manager relation.
+func manager(r []Relation) string {
+	for i := range r {
+		if r[i].Type == "manager" {
+			return r[i].Value
+		}
+	}
+
+	return ""
+}
+
+// organization returns the primary organization or the first one listed.
+func organization(o []Organization) Organization {
+	for i := range o {
+		if o[i].Primary {
+			return o[i]
+		}
+	}
+
+	if len(o) > 0 {
+		return o[0]
+	}
+
+	return Organization{}
+}
+
+// transform maps a directory user into an idp.User. ManagerID holds the
+// managers email and Manager their name, falling back to the email if the
+// manager is not in the directory.
+func transform(u *User, names map[string]string) idp.User {
+	org := organization(u.Organizations)
+	mgrEmail := manager(u.Relations)
+
+	mgr := names[strings.ToLower(mgrEmail)]
+	if mgr == "" {
+		mgr = mgrEmail
+	}
+
+	status := "ACTIVE"
+	if u.Suspended {
+		status = "SUSPENDED"
+	}
+
+	return idp.User{
+		ID:        u.ID,
+		Status:    status,
+		Activated: u.CreationTime,
+		Profile: idp.Profile{
+			LastName:   u.Name.FamilyName,
+			Manager:    mgr,
+			ManagerID:  mgrEmail,
+			Title:      org.Title,
+			Login:      u.PrimaryEmail,
+			FirstName:  u.Name.GivenName,
+			Department: org.Department,
+			Email:      u.PrimaryEmail,
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package idp
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/johnmikee/cuebert/pkg/logger"
@@ -9,7 +10,11 @@ import (
 type IDP string
 
 const (
-	Okta IDP = "okta"
+	Entra  IDP = "entra"
+	Google IDP = "google"
+	LDAP   IDP = "ldap"
+	Okta   IDP = "okta"
+	SCIM   IDP = "scim"
 )
 
 type Provider interface {
@@ -18,10 +23,32 @@ type Provider interface {
 	GetAllUsers() ([]User, error)
 }
 
+// Binder is implemented by providers that can make their requests with a
+// context, such as the span of the routine calling them. Wrappers implement
+// it by binding the provider they wrap.
+type Binder interface {
+	WithContext(ctx context.Context) Provider
+}
+
+// WithContext returns the provider bound to ctx, or the provider as is if it
+// cannot be bound.
+func WithContext(p Provider, ctx context.Context) Provider {
+	if b, ok := p.(Binder); ok {
+		return b.WithContext(ctx)
+	}
+
+	return p
+}
+
 type Config struct {
-	Domain string        `json:"domain,omitempty"`
-	URL    string        `json:"url,omitempty"`
-	Token  string        `json:"token,omitempty"`
-	Client *http.Client  `json:"client,omitempty"`
-	Log    logger.Logger `json:"log,omitempty"`
+	Domain   string        `json:"domain,omitempty"`
+	URL      string        `json:"url,omitempty"`
+	User     string        `json:"user,omitempty"`
+	Password string        `json:"password,omitempty"`
+	Token    string        `json:"token,omitempty"`
+	TokenURL string        `json:"token_url,omitempty"`
+	Client   *http.Client  `json:"client,omitempty"`
+	Log      logger.Logger `json:"log,omitempty"`
+	// Provider-specific configuration fields
+	ProviderSpecificConfig interface{} `json:"provider_specific_config,omitempty"`
 }
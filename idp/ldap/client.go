@@ -0,0 +1,180 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+
+	"github.com/johnmikee/cuebert/idp"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+func init() {
+	idp.Register(idp.LDAP, func() idp.Provider { return &Client{} })
+}
+
+// DefaultUserFilter returns every person with an email address.
+const DefaultUserFilter = "(&(objectClass=person)(mail=*))"
+
+// pageSize is the number of entries requested per page of a search.
+const pageSize = 500
+
+// maxDepth is how deep nested groups are followed before giving up.
+const maxDepth = 10
+
+// userAttributes are the attributes requested for each user.
+var userAttributes = []string{
+	"mail",
+	"givenName",
+	"sn",
+	"cn",
+	"title",
+	"department",
+	"manager",
+	"uid",
+	"sAMAccountName",
+	"employeeType",
+}
+
+// Options holds the LDAP specific settings. Pass a *Options as
+// idp.Config.ProviderSpecificConfig to set them through Setup.
+type Options struct {
+	// BaseDN is where searches start. If empty it is built from the domain.
+	BaseDN string `json:"base_dn,omitempty"`
+	// UserFilter selects the users returned from GetAllUsers.
+	UserFilter string `json:"user_filter,omitempty"`
+	// InsecureSkipVerify disables certificate checks for ldaps and StartTLS.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+	// StartTLS upgrades a plain ldap:// connection before binding.
+	StartTLS bool `json:"start_tls,omitempty"`
+}
+
+// conn is the subset of *goldap.Conn used so tests can swap in a fake directory.
+type conn interface {
+	Bind(username, password string) error
+	Search(req *goldap.SearchRequest) (*goldap.SearchResult, error)
+	SearchWithPaging(req *goldap.SearchRequest, pagingSize uint32) (*goldap.SearchResult, error)
+	Close() error
+}
+
+// Client represents the LDAP client.
+type Client struct {
+	url      string
+	bindDN   string
+	password string
+	opts     Options
+
+	dial func() (conn, error)
+	log  logger.Logger
+}
+
+// Config represents the configuration for the LDAP client.
+type Config struct {
+	// URL is the ldap:// or ldaps:// address of the directory.
+	URL      string
+	BindDN   string
+	Password string
+	Domain   string
+	Options  Options
+
+	Log *logger.Logger
+}
+
+// Setup implements idp.Provider.
+//
+// User and Password are used to bind. Domain is used to build the base DN
+// if one is not set in the provider specific Options.
+func (c *Client) Setup(i idp.Config) {
+	var opts Options
+	switch o := i.ProviderSpecificConfig.(type) {
+	case *Options:
+		if o != nil {
+			opts = *o
+		}
+	case Options:
+		opts = o
+	}
+
+	*c = *NewClient(&Config{
+		URL:      i.URL,
+		BindDN:   i.User,
+		Password: i.Password,
+		Domain:   i.Domain,
+		Options:  opts,
+		Log:      &i.Log,
+	})
+}
+
+// NewClient returns a pointer with the Client after validating the arguments passed.
+func NewClient(c *Config) *Client {
+	opts := c.Options
+	if opts.BaseDN == "" {
+		opts.BaseDN = domainToDN(c.Domain)
+	}
+	if opts.UserFilter == "" {
+		opts.UserFilter = DefaultUserFilter
+	}
+
+	client := &Client{
+		url:      c.URL,
+		bindDN:   c.BindDN,
+		password: c.Password,
+		opts:     opts,
+		log:      logger.ChildLogger("idp/ldap", c.Log),
+	}
+	client.dial = client.dialURL
+
+	return client
+}
+
+// domainToDN turns example.com into dc=example,dc=com. Values that already
+// look like a DN are returned as is.
+func domainToDN(domain string) string {
+	if domain == "" || strings.Contains(domain, "=") {
+		return domain
+	}
+
+	parts := strings.Split(domain, ".")
+	for i := range parts {
+		parts[i] = "dc=" + parts[i]
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (c *Client) dialURL() (conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.opts.InsecureSkipVerify} // #nosec G402 -- opt in only
+
+	l, err := goldap.DialURL(c.url, goldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, errors.Wrap(err, "dialing directory")
+	}
+
+	if c.opts.StartTLS {
+		if err := l.StartTLS(tlsConfig); err != nil {
+			_ = l.Close()
+			return nil, errors.Wrap(err, "starting tls")
+		}
+	}
+
+	return l, nil
+}
+
+// connect dials and binds to the directory. The caller must close the connection.
+func (c *Client) connect() (conn, error) {
+	l, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.bindDN != "" {
+		if err := l.Bind(c.bindDN, c.password); err != nil {
+			_ = l.Close()
+			return nil, errors.Wrap(err, "binding to directory")
+		}
+	}
+
+	return l, nil
+}
@@ -0,0 +1,188 @@
+package ldap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	goldap "github.com/go-ldap/ldap/v3"
+
+	"github.com/johnmikee/cuebert/idp"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// fakeDir is an in memory directory. Subtree searches with the user filter
+// return every person, searches for a cn return the matching group and base
+// searches return the entry for the DN.
+type fakeDir struct {
+	entries map[string]*goldap.Entry
+	users   []string
+	bound   string
+}
+
+func entry(dn string, attrs map[string][]string) *goldap.Entry {
+	return goldap.NewEntry(dn, attrs)
+}
+
+func newFakeDir() *fakeDir {
+	d := &fakeDir{entries: map[string]*goldap.Entry{}}
+
+	add := func(e *goldap.Entry, user bool) {
+		d.entries[strings.ToLower(e.DN)] = e
+		if user {
+			d.users = append(d.users, e.DN)
+		}
+	}
+
+	add(entry("uid=boss,ou=people,dc=example,dc=com", map[string][]string{
+		"mail": {"boss@example.com"}, "givenName": {"Big"}, "sn": {"Boss"}, "uid": {"boss"},
+	}), true)
+	add(entry("uid=worker,ou=people,dc=example,dc=com", map[string][]string{
+		"mail":       {"worker@example.com"},
+		"givenName":  {"Hard"},
+		"sn":         {"Worker"},
+		"title":      {"Engineer"},
+		"department": {"IT"},
+		"uid":        {"worker"},
+		// written with different case and spacing than the boss entry.
+		"manager": {"UID=boss, OU=people, DC=example, DC=com"},
+	}), true)
+	add(entry("uid=contractor,ou=people,dc=example,dc=com", map[string][]string{
+		"mail":    {"contractor@example.com"},
+		"manager": {"uid=vp,ou=execs,dc=example,dc=com"},
+	}), true)
+	// outside of the user filter.
+	add(entry("uid=vp,ou=execs,dc=example,dc=com", map[string][]string{
+		"mail": {"vp@example.com"}, "cn": {"The VP"},
+	}), false)
+
+	add(entry("cn=admins,ou=groups,dc=example,dc=com", map[string][]string{
+		"member": {"uid=boss,ou=people,dc=example,dc=com", "cn=helpdesk,ou=groups,dc=example,dc=com"},
+	}), false)
+	add(entry("cn=helpdesk,ou=groups,dc=example,dc=com", map[string][]string{
+		"member": {"uid=worker,ou=people,dc=example,dc=com", "cn=admins,ou=groups,dc=example,dc=com"},
+	}), false)
+
+	return d
+}
+
+func (d *fakeDir) Bind(username, password string) error {
+	if password != "secret" {
+		return errors.New("invalid credentials")
+	}
+	d.bound = username
+	return nil
+}
+
+func (d *fakeDir) Search(req *goldap.SearchRequest) (*goldap.SearchResult, error) {
+	res := &goldap.SearchResult{}
+
+	switch {
+	case req.Scope == goldap.ScopeBaseObject:
+		if e, ok := d.entries[strings.ToLower(normalizeDN(req.BaseDN))]; ok {
+			res.Entries = append(res.Entries, e)
+		}
+	case strings.Contains(req.Filter, "(cn="):
+		for _, e := range d.entries {
+			cn := strings.SplitN(strings.TrimPrefix(e.DN, "cn="), ",", 2)[0]
+			if strings.HasPrefix(e.DN, "cn=") && strings.Contains(req.Filter, "(cn="+cn+")") {
+				res.Entries = append(res.Entries, e)
+			}
+		}
+	case req.Filter == DefaultUserFilter:
+		for _, dn := range d.users {
+			res.Entries = append(res.Entries, d.entries[dn])
+		}
+	}
+
+	return res, nil
+}
+
+func (d *fakeDir) SearchWithPaging(req *goldap.SearchRequest, _ uint32) (*goldap.SearchResult, error) {
+	return d.Search(req)
+}
+
+func (d *fakeDir) Close() error { return nil }
+
+func testClient(d *fakeDir, password string) *Client {
+	c := &Client{}
+	c.Setup(idp.Config{
+		URL:      "ldap://localhost",
+		Domain:   "example.com",
+		User:     "cn=cuebert,dc=example,dc=com",
+		Password: password,
+		Log:      logger.NewLogger(&logger.Config{Level: "error"}),
+	})
+	c.dial = func() (conn, error) { return d, nil }
+
+	return c
+}
+
+func TestGetAllUsers(t *testing.T) {
+	d := newFakeDir()
+	c := testClient(d, "secret")
+
+	users, err := c.GetAllUsers()
+	if err != nil {
+		t.Fatalf("GetAllUsers() returned error: %v", err)
+	}
+
+	if d.bound != "cn=cuebert,dc=example,dc=com" {
+		t.Errorf("expected to bind as the configured user, got: %s", d.bound)
+	}
+
+	if len(users) != 3 {
+		t.Fatalf("GetAllUsers() returned %d users, want 3", len(users))
+	}
+
+	w := users[1].Profile
+	if w.Email != "worker@example.com" ||
+		w.ManagerID != "boss@example.com" ||
+		w.Manager != "Big Boss" ||
+		w.Title != "Engineer" ||
+		w.Department != "IT" ||
+		w.Login != "worker" {
+		t.Errorf("unexpected profile: %+v", w)
+	}
+
+	if users[2].Profile.ManagerID != "vp@example.com" || users[2].Profile.Manager != "The VP" {
+		t.Errorf("expected the manager outside the filter to be looked up, got: %+v", users[2].Profile)
+	}
+}
+
+func TestGetAdminGroup(t *testing.T) {
+	c := testClient(newFakeDir(), "secret")
+
+	for _, id := range []string{"admins", "cn=admins,ou=groups,dc=example,dc=com"} {
+		members, err := c.GetAdminGroup(id)
+		if err != nil {
+			t.Fatalf("GetAdminGroup(%s) returned error: %v", id, err)
+		}
+
+		if len(members) != 2 || members[0] != "boss@example.com" || members[1] != "worker@example.com" {
+			t.Errorf("GetAdminGroup(%s) unexpected members: %v", id, members)
+		}
+	}
+
+	if _, err := c.GetAdminGroup("nope"); err == nil {
+		t.Error("expected an error for a missing group")
+	}
+}
+
+func TestBindFailure(t *testing.T) {
+	c := testClient(newFakeDir(), "wrong")
+
+	if _, err := c.GetAllUsers(); err == nil {
+		t.Error("expected an error with invalid credentials")
+	}
+}
+
+func TestDomainToDN(t *testing.T) {
+	if got := domainToDN("corp.example.com"); got != "dc=corp,dc=example,dc=com" {
+		t.Errorf("domainToDN() got: %s", got)
+	}
+
+	if got := domainToDN("ou=people,dc=example,dc=com"); got != "ou=people,dc=example,dc=com" {
+		t.Errorf("domainToDN() should leave DNs alone, got: %s", got)
+	}
+}
@@ -0,0 +1,119 @@
+package ldap
+
+import (
+	"fmt"
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+
+	"github.com/johnmikee/cuebert/pkg/helpers"
+)
+
+// memberAttributes are read from every member to decide if it is a group to expand.
+var memberAttributes = []string{"mail", "member", "uniqueMember"}
+
+// GetAdminGroup implements idp.Provider. The group may be passed as its DN
+// or its cn. Nested groups are expanded and the emails of every user returned.
+func (c *Client) GetAdminGroup(groupID string) ([]string, error) {
+	l, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	group, err := c.findGroup(l, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{normalizeDN(group.DN): true}
+	emails := []string{}
+
+	if err := c.expand(l, group, seen, &emails, 0); err != nil {
+		return nil, err
+	}
+
+	return helpers.RemoveEmpty(emails), nil
+}
+
+func (c *Client) findGroup(l conn, groupID string) (*goldap.Entry, error) {
+	if strings.Contains(groupID, "=") {
+		e, err := c.lookup(l, groupID, memberAttributes)
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			return nil, fmt.Errorf("group %s not found", groupID)
+		}
+		return e, nil
+	}
+
+	req := goldap.NewSearchRequest(
+		c.opts.BaseDN,
+		goldap.ScopeWholeSubtree,
+		goldap.NeverDerefAliases,
+		2,
+		0,
+		false,
+		fmt.Sprintf(
+			"(&(|(objectClass=group)(objectClass=groupOfNames)(objectClass=groupOfUniqueNames))(cn=%s))",
+			goldap.EscapeFilter(groupID),
+		),
+		memberAttributes,
+		nil,
+	)
+
+	res, err := l.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(res.Entries) {
+	case 0:
+		return nil, fmt.Errorf("group %s not found", groupID)
+	case 1:
+		return res.Entries[0], nil
+	default:
+		return nil, fmt.Errorf("group %s matched more than one entry, pass the DN instead", groupID)
+	}
+}
+
+// expand walks the member attributes of the entry adding the mail of every
+// user and recursing into groups. seen stops cycles between groups.
+func (c *Client) expand(l conn, e *goldap.Entry, seen map[string]bool, emails *[]string, depth int) error {
+	if depth > maxDepth {
+		return errors.Errorf("group nesting deeper than %d at %s", maxDepth, e.DN)
+	}
+
+	members := e.GetAttributeValues("member")
+	members = append(members, e.GetAttributeValues("uniqueMember")...)
+
+	for _, dn := range members {
+		key := normalizeDN(dn)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		m, err := c.lookup(l, dn, memberAttributes)
+		if err != nil {
+			c.log.Debug().Str("member", dn).AnErr("error", err).Msg("could not read member")
+			continue
+		}
+		if m == nil {
+			continue
+		}
+
+		if len(m.GetAttributeValues("member")) > 0 || len(m.GetAttributeValues("uniqueMember")) > 0 {
+			if err := c.expand(l, m, seen, emails, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		*emails = append(*emails, m.GetAttributeValue("mail"))
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package ldap
+
+import (
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+
+	"github.com/johnmikee/cuebert/idp"
+)
+
+// GetAllUsers implements idp.Provider. Each users manager DN is resolved
+// to the managers mail and name.
+func (c *Client) GetAllUsers() ([]idp.User, error) {
+	l, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	req := goldap.NewSearchRequest(
+		c.opts.BaseDN,
+		goldap.ScopeWholeSubtree,
+		goldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		c.opts.UserFilter,
+		userAttributes,
+		nil,
+	)
+
+	res, err := l.SearchWithPaging(req, pageSize)
+	if err != nil {
+		c.log.Err(err).Str("filter", c.opts.UserFilter).Msg("error searching users")
+		return nil, err
+	}
+
+	byDN := make(map[string]*goldap.Entry, len(res.Entries))
+	for _, e := range res.Entries {
+		byDN[normalizeDN(e.DN)] = e
+	}
+
+	results := make([]idp.User, 0, len(res.Entries))
+	for _, e := range res.Entries {
+		u := transform(e)
+
+		if dn := e.GetAttributeValue("manager"); dn != "" {
+			mgr, ok := byDN[normalizeDN(dn)]
+			if !ok {
+				// the manager may sit outside the user filter.
+				mgr, err = c.lookup(l, dn, userAttributes)
+				if err != nil {
+					c.log.Debug().Str("user", u.Profile.Email).Str("manager", dn).Msg("could not resolve manager")
+				}
+				byDN[normalizeDN(dn)] = mgr
+			}
+
+			if mgr != nil {
+				u.Profile.ManagerID = mgr.GetAttributeValue("mail")
+				u.Profile.Manager = displayName(mgr)
+			}
+		}
+
+		results = append(results, u)
+	}
+
+	return results, nil
+}
+
+// lookup reads a single entry by its DN.
+func (c *Client) lookup(l conn, dn string, attrs []string) (*goldap.Entry, error) {
+	req := goldap.NewSearchRequest(
+		dn,
+		goldap.ScopeBaseObject,
+		goldap.NeverDerefAliases,
+		1,
+		0,
+		false,
+		"(objectClass=*)",
+		attrs,
+		nil,
+	)
+
+	res, err := l.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Entries) == 0 {
+		return nil, nil
+	}
+
+	return res.Entries[0], nil
+}
+
+// normalizeDN lower cases the DN and removes spaces after separators so
+// values written by different tools compare equal.
+func normalizeDN(dn string) string {
+	parsed, err := goldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+
+	rdns := make([]string, 0, len(parsed.RDNs))
+	for _, rdn := range parsed.RDNs {
+		attrs := make([]string, 0, len(rdn.Attributes))
+		for _, a := range rdn.Attributes {
+			attrs = append(attrs, strings.ToLower(a.Type)+"="+strings.ToLower(a.Value))
+		}
+		rdns = append(rdns, strings.Join(attrs, "+"))
+	}
+
+	return strings.Join(rdns, ",")
+}
+
+func displayName(e *goldap.Entry) string {
+	first, last := e.GetAttributeValue("givenName"), e.GetAttributeValue("sn")
+	if first != "" || last != "" {
+		return strings.TrimSpace(first + " " + last)
+	}
+
+	return e.GetAttributeValue("cn")
+}
+
+func login(e *goldap.Entry) string {
+	if v := e.GetAttributeValue("sAMAccountName"); v != "" {
+		return v
+	}
+
+	return e.GetAttributeValue("uid")
+}
+
+func transform(e *goldap.Entry) idp.User {
+	return idp.User{
+		ID:     e.DN,
+		Status: "ACTIVE",
+		Profile: idp.Profile{
+			LastName:   e.GetAttributeValue("sn"),
+			Title:      e.GetAttributeValue("title"),
+			Login:      login(e),
+			FirstName:  e.GetAttributeValue("givenName"),
+			UserType:   e.GetAttributeValue("employeeType"),
+			Department: e.GetAttributeValue("department"),
+			Email:      e.GetAttributeValue("mail"),
+		},
+	}
+}
@@ -2,6 +2,7 @@ package okta
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +14,10 @@ import (
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
+func init() {
+	idp.Register(idp.Okta, func() idp.Provider { return &Client{} })
+}
+
 // Client represents the Okta client.
 type Client struct {
 	token   string
@@ -21,6 +26,7 @@ type Client struct {
 
 	client *http.Client
 	log    logger.Logger
+	ctx    context.Context
 }
 
 // Config represents the configuration for the Okta client.
@@ -44,6 +50,15 @@ func (c *Client) Setup(i idp.Config) {
 	c.domain = i.Domain
 	c.client = httpClient(i.Client)
 	c.log = logger.ChildLogger("idp/okta", &i.Log)
+	c.ctx = context.Background()
+}
+
+// WithContext implements idp.Binder.
+func (c *Client) WithContext(ctx context.Context) idp.Provider {
+	bound := *c
+	bound.ctx = ctx
+
+	return &bound
 }
 
 // urlOverride is used to override the default url arg in a function
@@ -60,6 +75,7 @@ func NewClient(c *Config) *Client {
 		domain:  c.Domain,
 		client:  httpClient(c.Client),
 		log:     logger.ChildLogger("idp/okta", c.Log),
+		ctx:     context.Background(),
 	}
 }
 
@@ -94,7 +110,7 @@ func (o *Client) newRequest(method, u string, ur *urlOverride, body interface{})
 		}
 	}
 
-	req, err := http.NewRequest(method, url, &buf)
+	req, err := http.NewRequestWithContext(o.ctx, method, url, &buf)
 	if err != nil {
 		o.log.Err(err).Msg("error building request")
 		return nil, err
@@ -0,0 +1,65 @@
+package idp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Factory returns a new provider. Setup is called on the provider before it is used.
+type Factory func() Provider
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[IDP]Factory{}
+)
+
+// Register makes a provider available by name. It is meant to be called from
+// the init function of the provider package. Registering the same name twice
+// or a nil factory panics.
+func Register(name IDP, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("idp: Register factory is nil for " + string(name))
+	}
+
+	if _, dup := registry[name]; dup {
+		panic("idp: Register called twice for " + string(name))
+	}
+
+	registry[name] = factory
+}
+
+// New returns a new, unconfigured provider registered under name.
+func New(name IDP) (Provider, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf(
+			"unknown idp provider %q. registered providers are [%s]",
+			name,
+			strings.Join(Providers(), ", "),
+		)
+	}
+
+	return factory(), nil
+}
+
+// Providers returns the sorted names of the registered providers.
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,60 @@
+package idp
+
+import (
+	"strings"
+	"testing"
+)
+
+type stubProvider struct {
+	Provider
+}
+
+func TestRegister(t *testing.T) {
+	name := IDP("registry-test")
+	Register(name, func() Provider { return &stubProvider{} })
+
+	p, err := New(name)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	if _, ok := p.(*stubProvider); !ok {
+		t.Errorf("unexpected provider type %T", p)
+	}
+
+	found := false
+	for _, n := range Providers() {
+		if n == string(name) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected %s in Providers(), got: %v", name, Providers())
+	}
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	name := IDP("registry-twice")
+	Register(name, func() Provider { return &stubProvider{} })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic registering the same name twice")
+		}
+	}()
+
+	Register(name, func() Provider { return &stubProvider{} })
+}
+
+func TestNewUnknown(t *testing.T) {
+	Register("registry-known", func() Provider { return &stubProvider{} })
+
+	_, err := New("registry-unknown")
+	if err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+
+	if !strings.Contains(err.Error(), "registry-known") {
+		t.Errorf("expected the error to list registered providers, got: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnmikee/cuebert/idp"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+func init() {
+	idp.Register(idp.SCIM, func() idp.Provider { return &Client{} })
+}
+
+// Client represents a generic SCIM 2.0 client.
+//   - https://datatracker.ietf.org/doc/html/rfc7644
+type Client struct {
+	token   string
+	baseURL string
+
+	client *http.Client
+	log    logger.Logger
+	ctx    context.Context
+}
+
+// Config represents the configuration for the SCIM client.
+type Config struct {
+	// URL is the SCIM base, the path before /Users and /Groups.
+	URL    string
+	Token  string
+	Client *http.Client
+	Log    *logger.Logger
+}
+
+// Setup implements idp.Provider.
+func (c *Client) Setup(i idp.Config) {
+	c.token = helpers.TokenValidator(i.Token, "Bearer")
+	c.baseURL = helpers.URLShaper(i.URL, "")
+	c.client = httpClient(i.Client)
+	c.log = logger.ChildLogger("idp/scim", &i.Log)
+	c.ctx = context.Background()
+}
+
+// WithContext implements idp.Binder.
+func (c *Client) WithContext(ctx context.Context) idp.Provider {
+	bound := *c
+	bound.ctx = ctx
+
+	return &bound
+}
+
+// NewClient returns a pointer with the Client after validating the arguments passed.
+func NewClient(c *Config) *Client {
+	return &Client{
+		token:   helpers.TokenValidator(c.Token, "Bearer"),
+		baseURL: helpers.URLShaper(c.URL, ""),
+		client:  httpClient(c.Client),
+		log:     logger.ChildLogger("idp/scim", c.Log),
+		ctx:     context.Background(),
+	}
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+
+	return &http.Client{
+		Timeout: time.Minute,
+	}
+}
+
+func (c *Client) newRequest(method, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(c.ctx, method, fmt.Sprintf("%s%s", c.baseURL, strings.TrimPrefix(url, "/")), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", c.token)
+	req.Header.Set("Accept", "application/scim+json, application/json")
+	return req, nil
+}
+
+func (c *Client) get(url string, v interface{}) error {
+	req, err := c.newRequest(http.MethodGet, url)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("error during request. status code=%d error: %s", resp.StatusCode, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,165 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/johnmikee/cuebert/idp"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+func scimUsers() []User {
+	inactive := false
+
+	users := []User{
+		{
+			ID:       "boss",
+			UserName: "boss@example.com",
+			Name:     Name{GivenName: "Big", FamilyName: "Boss"},
+			Emails:   []Email{{Value: "boss@example.com", Primary: true}},
+		},
+		{
+			ID:       "worker",
+			UserName: "worker",
+			Name:     Name{GivenName: "Hard", FamilyName: "Worker"},
+			Title:    "Engineer",
+			Emails:   []Email{{Value: "home@example.net", Type: "home"}, {Value: "worker@example.com", Type: "work"}},
+			Enterprise: &Enterprise{
+				Department: "IT",
+				Manager:    &Manager{Value: "boss"},
+			},
+		},
+		{ID: "gone", UserName: "gone@example.com", Active: &inactive},
+	}
+
+	// filler so the results span several pages.
+	for i := 0; i < pageSize; i++ {
+		id := "filler" + strconv.Itoa(i)
+		users = append(users, User{ID: id, UserName: id + "@example.com"})
+	}
+
+	return users
+}
+
+func newSCIMServer(t *testing.T) *httptest.Server {
+	users := scimUsers()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/scim/v2/Users", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer scim-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		start, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+		count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+		// serve smaller pages than asked for to make sure startIndex follows what was returned.
+		count /= 2
+
+		res := ListResponse{TotalResults: len(users), StartIndex: start, Resources: []User{}}
+		for i := start - 1; i < start-1+count && i < len(users); i++ {
+			res.Resources = append(res.Resources, users[i])
+		}
+		res.ItemsPerPage = len(res.Resources)
+		_ = json.NewEncoder(w).Encode(res)
+	})
+	mux.HandleFunc("/scim/v2/Users/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/scim/v2/Users/")
+		for i := range users {
+			if users[i].ID == id {
+				_ = json.NewEncoder(w).Encode(users[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/scim/v2/Groups/admins", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Group{
+			ID: "admins",
+			Members: []Member{
+				{Value: "boss"},
+				{Value: "helpdesk", Type: "Group"},
+				{Value: "gone"},
+			},
+		})
+	})
+	mux.HandleFunc("/scim/v2/Groups/helpdesk", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Group{
+			ID: "helpdesk",
+			Members: []Member{
+				{Value: "worker", Ref: "https://idp/scim/v2/Users/worker"},
+				{Value: "admins", Ref: "https://idp/scim/v2/Groups/admins"},
+			},
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func testClient(srv *httptest.Server) *Client {
+	c := &Client{}
+	c.Setup(idp.Config{
+		URL:   srv.URL + "/scim/v2",
+		Token: "scim-token",
+		Log:   logger.NewLogger(&logger.Config{Level: "error"}),
+	})
+
+	return c
+}
+
+func TestGetAllUsers(t *testing.T) {
+	c := testClient(newSCIMServer(t))
+
+	users, err := c.GetAllUsers()
+	if err != nil {
+		t.Fatalf("GetAllUsers() returned error: %v", err)
+	}
+
+	if len(users) != pageSize+2 {
+		t.Fatalf("GetAllUsers() returned %d users, want %d", len(users), pageSize+2)
+	}
+
+	w := users[1].Profile
+	if w.Email != "worker@example.com" ||
+		w.ManagerID != "boss@example.com" ||
+		w.Manager != "Big Boss" ||
+		w.Title != "Engineer" ||
+		w.Department != "IT" ||
+		w.Login != "worker" {
+		t.Errorf("unexpected profile: %+v", w)
+	}
+
+	seen := map[string]bool{}
+	for _, u := range users {
+		if seen[u.ID] {
+			t.Errorf("user %s returned more than once", u.ID)
+		}
+		seen[u.ID] = true
+	}
+}
+
+func TestGetAdminGroup(t *testing.T) {
+	c := testClient(newSCIMServer(t))
+
+	members, err := c.GetAdminGroup("admins")
+	if err != nil {
+		t.Fatalf("GetAdminGroup() returned error: %v", err)
+	}
+
+	want := []string{"boss@example.com", "worker@example.com"}
+	if fmt.Sprint(members) != fmt.Sprint(want) {
+		t.Errorf("unexpected members, got: %v, want: %v", members, want)
+	}
+
+	if _, err := c.GetAdminGroup("missing"); err == nil {
+		t.Error("expected an error for a missing group")
+	}
+}
@@ -0,0 +1,90 @@
+package scim
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/johnmikee/cuebert/pkg/helpers"
+)
+
+// maxDepth is how deep nested groups are followed.
+const maxDepth = 10
+
+// Group is the subset of the SCIM group resource used by cuebert.
+type Group struct {
+	ID          string   `json:"id"`
+	DisplayName string   `json:"displayName"`
+	Members     []Member `json:"members"`
+}
+
+// Member references a user or group in the group.
+type Member struct {
+	Value   string `json:"value"`
+	Display string `json:"display"`
+	Type    string `json:"type"`
+	Ref     string `json:"$ref"`
+}
+
+func (m *Member) isGroup() bool {
+	return strings.EqualFold(m.Type, "Group") || strings.Contains(m.Ref, "/Groups/")
+}
+
+// GetAdminGroup implements idp.Provider. Members are read from /Groups/{id}
+// and each user is looked up for their email. Nested groups are expanded.
+func (c *Client) GetAdminGroup(groupID string) ([]string, error) {
+	emails := []string{}
+	seen := map[string]bool{}
+
+	if err := c.expand(groupID, seen, &emails, 0); err != nil {
+		return nil, err
+	}
+
+	return helpers.RemoveEmpty(emails), nil
+}
+
+func (c *Client) expand(groupID string, seen map[string]bool, emails *[]string, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("group nesting deeper than %d at %s", maxDepth, groupID)
+	}
+	seen["g:"+groupID] = true
+
+	var g Group
+	if err := c.get("Groups/"+url.PathEscape(groupID), &g); err != nil {
+		c.log.Err(err).Str("group", groupID).Msg("error getting group")
+		return err
+	}
+
+	for i := range g.Members {
+		m := &g.Members[i]
+
+		if m.isGroup() {
+			if seen["g:"+m.Value] {
+				continue
+			}
+			if err := c.expand(m.Value, seen, emails, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if seen["u:"+m.Value] {
+			continue
+		}
+		seen["u:"+m.Value] = true
+
+		var u User
+		if err := c.get("Users/"+url.PathEscape(m.Value), &u); err != nil {
+			c.log.Debug().Str("member", m.Value).AnErr("error", err).Msg("could not read member")
+			continue
+		}
+
+		if u.Active != nil && !*u.Active {
+			continue
+		}
+
+		*emails = append(*emails, u.email())
+	}
+
+	return nil
+}
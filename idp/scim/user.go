@@ -0,0 +1,192 @@
+package scim
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnmikee/cuebert/idp"
+)
+
+// pageSize is the count requested for each page of users.
+const pageSize = 100
+
+// EnterpriseSchema is the enterprise user extension.
+//   - https://datatracker.ietf.org/doc/html/rfc7643#section-4.3
+const EnterpriseSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+
+// ListResponse is a page of users.
+type ListResponse struct {
+	TotalResults int    `json:"totalResults"`
+	ItemsPerPage int    `json:"itemsPerPage"`
+	StartIndex   int    `json:"startIndex"`
+	Resources    []User `json:"Resources"`
+}
+
+// User is the subset of the SCIM user resource used by cuebert.
+type User struct {
+	ID         string      `json:"id"`
+	UserName   string      `json:"userName"`
+	Active     *bool       `json:"active"`
+	Name       Name        `json:"name"`
+	Title      string      `json:"title"`
+	UserType   string      `json:"userType"`
+	Emails     []Email     `json:"emails"`
+	Meta       Meta        `json:"meta"`
+	Enterprise *Enterprise `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`
+}
+
+// Name holds the users name.
+type Name struct {
+	Formatted  string `json:"formatted"`
+	GivenName  string `json:"givenName"`
+	FamilyName string `json:"familyName"`
+}
+
+// Email is one of the users email addresses.
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Primary bool   `json:"primary"`
+}
+
+// Meta holds the resource metadata.
+type Meta struct {
+	Created *time.Time `json:"created"`
+}
+
+// Enterprise holds the enterprise extension attributes.
+type Enterprise struct {
+	Department string   `json:"department"`
+	Manager    *Manager `json:"manager"`
+}
+
+// Manager references the users manager by their SCIM id.
+type Manager struct {
+	Value       string `json:"value"`
+	DisplayName string `json:"displayName"`
+}
+
+// email returns the primary email, then the work email, then the first
+// listed, falling back to the userName if it looks like an address.
+func (u *User) email() string {
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+
+	for _, e := range u.Emails {
+		if e.Type == "work" {
+			return e.Value
+		}
+	}
+
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+
+	if strings.Contains(u.UserName, "@") {
+		return u.UserName
+	}
+
+	return ""
+}
+
+func (u *User) displayName() string {
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+
+	return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+}
+
+// GetAllUsers implements idp.Provider. Inactive users are skipped and each
+// manager id is resolved to the managers email.
+func (c *Client) GetAllUsers() ([]idp.User, error) {
+	users, err := c.listUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	results := make([]idp.User, 0, len(users))
+	for i := range users {
+		if users[i].Active != nil && !*users[i].Active {
+			continue
+		}
+		results = append(results, transform(&users[i], byID))
+	}
+
+	return results, nil
+}
+
+// listUsers pages through /Users using startIndex and count. Paging stops
+// once totalResults is reached or a page comes back empty.
+func (c *Client) listUsers() ([]User, error) {
+	results := []User{}
+
+	for start := 1; ; {
+		var page ListResponse
+		if err := c.get(fmt.Sprintf("Users?startIndex=%d&count=%d", start, pageSize), &page); err != nil {
+			c.log.Err(err).Int("startIndex", start).Msg("error listing users")
+			return nil, err
+		}
+
+		results = append(results, page.Resources...)
+
+		if len(page.Resources) == 0 || len(results) >= page.TotalResults {
+			break
+		}
+
+		start += len(page.Resources)
+		c.log.Trace().Int("startIndex", start).Msg("checking next page..")
+	}
+
+	return results, nil
+}
+
+func transform(u *User, byID map[string]*User) idp.User {
+	user := idp.User{
+		ID:     u.ID,
+		Status: "ACTIVE",
+		Profile: idp.Profile{
+			LastName:  u.Name.FamilyName,
+			Title:     u.Title,
+			Login:     u.UserName,
+			FirstName: u.Name.GivenName,
+			UserType:  u.UserType,
+			Email:     u.email(),
+		},
+	}
+
+	if u.Meta.Created != nil {
+		user.Activated = *u.Meta.Created
+	}
+
+	if u.Enterprise == nil {
+		return user
+	}
+
+	user.Profile.Department = u.Enterprise.Department
+
+	if m := u.Enterprise.Manager; m != nil && m.Value != "" {
+		user.Profile.Manager = m.DisplayName
+
+		if mgr, ok := byID[m.Value]; ok {
+			user.Profile.ManagerID = mgr.email()
+			if user.Profile.Manager == "" {
+				user.Profile.Manager = mgr.displayName()
+			}
+		} else if strings.Contains(m.Value, "@") {
+			// some idps send the managers email instead of their id.
+			user.Profile.ManagerID = m.Value
+		}
+	}
+
+	return user
+}
@@ -1,6 +1,10 @@
 package mdm
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Device holds the general purpose information of the device
 type Device struct {
@@ -43,3 +47,35 @@ type QueryOpts struct {
 	UserID       string `json:"user_id"`
 	UserName     string `json:"user_name"`
 }
+
+// Match reports whether the device satisfies every option set. Empty options
+// are ignored and values are compared case-insensitively. It is used by
+// providers that cannot filter on the server side.
+func (o *QueryOpts) Match(d *Device) bool {
+	if o == nil {
+		return true
+	}
+
+	fields := []struct {
+		want, got string
+	}{
+		{o.AssetTag, fmt.Sprint(d.AssetTag)},
+		{o.DeviceID, d.DeviceID},
+		{o.DeviceName, d.DeviceName},
+		{o.Model, d.Model},
+		{o.OSVersion, d.OSVersion},
+		{o.SerialNumber, d.SerialNumber},
+		{o.Platform, d.Platform},
+		{o.UserEmail, d.User.Email},
+		{o.UserID, d.User.ID},
+		{o.UserName, d.User.Name},
+	}
+
+	for _, f := range fields {
+		if f.want != "" && !strings.EqualFold(f.want, f.got) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,9 +1,18 @@
 package client
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/johnmikee/cuebert/mdm"
-	"github.com/johnmikee/cuebert/mdm/jamf"
-	"github.com/johnmikee/cuebert/mdm/kandji"
+	"github.com/johnmikee/cuebert/mdm/middleware"
+
+	// built in providers register themselves with mdm.Register.
+	_ "github.com/johnmikee/cuebert/mdm/file"
+	_ "github.com/johnmikee/cuebert/mdm/fleet"
+	_ "github.com/johnmikee/cuebert/mdm/intune"
+	_ "github.com/johnmikee/cuebert/mdm/jamf"
+	_ "github.com/johnmikee/cuebert/mdm/kandji"
 )
 
 // Config represents the configuration for the client.
@@ -15,28 +24,64 @@ type Config struct {
 type MDM struct {
 	MDM    mdm.MDM
 	Config mdm.Config
+	// CacheTTL is how long provider results are reused. Zero disables the cache.
+	CacheTTL time.Duration
+	// RateLimit is the number of requests per second sent to the MDM. Zero
+	// disables the limiter.
+	RateLimit float64
+	// Burst is how many requests can be sent at once before RateLimit applies.
+	Burst int
+	// MaxRetries is how many times throttled or failed requests are retried.
+	MaxRetries int
+	// Instrument, if set, wraps the provider inside the cache so only the
+	// calls that reach the MDM pass through it.
+	Instrument func(mdm.Provider) mdm.Provider
 }
 
 // New creates a new MDM provider based on the provided MDM configuration.
-// It returns the MDM provider instance.
-func New(m *MDM) mdm.Provider {
+// Providers are looked up in the mdm registry, so out of tree providers only
+// need to be imported for their name to be accepted. It returns an error
+// listing the registered providers if the MDM type is unknown.
+func New(m *MDM) (mdm.Provider, error) {
+	provider, err := mdm.New(m.MDM)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Instrument != nil {
+		provider = m.Instrument(provider)
+	}
+
 	config := Config{
-		MDMProvider: createMDMProvider(m.MDM),
+		MDMProvider: provider,
 	}
 
-	config.MDMProvider.Setup(m.Config)
+	if m.CacheTTL > 0 {
+		config.MDMProvider = middleware.NewCache(provider, m.CacheTTL)
+	}
 
-	return config.MDMProvider
+	cfg := m.Config
+	if cfg.Client == nil && (m.RateLimit > 0 || m.MaxRetries > 0) {
+		cfg.Client = httpClient(m)
+	}
+
+	config.MDMProvider.Setup(cfg)
+
+	return config.MDMProvider, nil
 }
 
-// createMDMProvider creates and returns an MDM provider based on the provided MDM type.
-func createMDMProvider(providerName mdm.MDM) mdm.Provider {
-	switch providerName {
-	case mdm.Jamf:
-		return &jamf.Client{}
-	case mdm.Kandji:
-		return &kandji.Config{}
-	default:
-		return nil
+// httpClient returns a client that rate limits and retries requests to the MDM.
+func httpClient(m *MDM) *http.Client {
+	t := &middleware.Transport{
+		MaxRetries: m.MaxRetries,
+		Log:        &m.Config.Log,
+	}
+	if m.RateLimit > 0 {
+		t.Limiter = middleware.NewLimiter(m.RateLimit, m.Burst)
+	}
+
+	return &http.Client{
+		Transport: t,
+		Timeout:   5 * time.Minute,
 	}
 }
@@ -1,11 +1,15 @@
 package client
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/mdm/intune"
 	"github.com/johnmikee/cuebert/mdm/jamf"
 	"github.com/johnmikee/cuebert/mdm/kandji"
+	"github.com/johnmikee/cuebert/mdm/middleware"
 )
 
 func TestNewJamf(t *testing.T) {
@@ -23,7 +27,10 @@ func TestNewJamf(t *testing.T) {
 	}
 
 	// Call the New function to create the MDM provider
-	provider := New(&mdmInstance)
+	provider, err := New(&mdmInstance)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
 
 	// Check the type of the returned provider
 	switch provider.(type) {
@@ -52,7 +59,10 @@ func TestNewKandji(t *testing.T) {
 	}
 
 	// Call the New function to create the MDM provider
-	provider := New(&mdmInstance)
+	provider, err := New(&mdmInstance)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
 
 	// Check the type of the returned provider
 	switch provider.(type) {
@@ -65,3 +75,65 @@ func TestNewKandji(t *testing.T) {
 		t.Error("Unknown provider type")
 	}
 }
+
+func TestNewIntune(t *testing.T) {
+	mdmInstance := MDM{
+		MDM: mdm.Intune,
+		Config: mdm.Config{
+			Domain: "example.com",
+			MDM:    mdm.Intune,
+		},
+	}
+
+	provider, err := New(&mdmInstance)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	if _, ok := provider.(*intune.Client); !ok {
+		t.Errorf("Expected Intune provider, but got %T", provider)
+	}
+}
+
+func TestNewUnsupported(t *testing.T) {
+	mdmInstance := MDM{
+		MDM: mdm.MDM("unknown"),
+	}
+
+	provider, err := New(&mdmInstance)
+	if err == nil {
+		t.Fatal("Expected an error for an unsupported provider")
+	}
+
+	if !strings.Contains(err.Error(), "kandji") {
+		t.Errorf("Expected the error to list the registered providers, got: %v", err)
+	}
+
+	if provider != nil {
+		t.Errorf("Expected a nil provider, but got %T", provider)
+	}
+}
+
+func TestNewCached(t *testing.T) {
+	mdmInstance := MDM{
+		MDM:        mdm.Jamf,
+		Config:     mdm.Config{Domain: "example.com", MDM: mdm.Jamf},
+		CacheTTL:   time.Minute,
+		RateLimit:  5,
+		MaxRetries: 3,
+	}
+
+	provider, err := New(&mdmInstance)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	c, ok := provider.(*middleware.Cache)
+	if !ok {
+		t.Fatalf("Expected a cached provider, but got %T", provider)
+	}
+
+	if _, ok := c.Provider.(*jamf.Client); !ok {
+		t.Errorf("Expected the cache to wrap a Jamf provider, but got %T", c.Provider)
+	}
+}
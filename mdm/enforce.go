@@ -0,0 +1,75 @@
+package mdm
+
+import "errors"
+
+// Action is an enforcement action taken on a device once the deadline passes.
+type Action string
+
+const (
+	// ScheduleUpdate asks the device to download and install the update,
+	// letting the user defer it as the MDM allows.
+	ScheduleUpdate Action = "schedule_update"
+	// ForceInstall installs the update and restarts the device without
+	// waiting on the user.
+	ForceInstall Action = "force_install"
+	// Remediate moves the device into a blueprint, group or team meant for
+	// devices that missed the deadline.
+	Remediate Action = "remediate"
+)
+
+// Actions lists the supported enforcement actions.
+var Actions = []Action{ScheduleUpdate, ForceInstall, Remediate}
+
+// ErrActionNotSupported is returned by an Enforcer when the MDM has no way to
+// perform the requested action.
+var ErrActionNotSupported = errors.New("action not supported by this mdm")
+
+// EnforceOpts holds the values an action may need.
+type EnforceOpts struct {
+	// Version is the OS version the device should be updated to.
+	Version string `json:"version,omitempty"`
+	// Group is the blueprint, group or team id used by Remediate.
+	Group string `json:"group,omitempty"`
+}
+
+// Enforcer is implemented by providers that can act on a device instead of
+// only reporting on it. It is optional, callers should check for it with
+// AsEnforcer.
+type Enforcer interface {
+	Enforce(action Action, device *Device, opts *EnforceOpts) error
+}
+
+// Wrapper is implemented by providers that decorate another provider, such as
+// a cache, so the capabilities of the wrapped provider can still be found.
+type Wrapper interface {
+	Unwrap() Provider
+}
+
+// AsEnforcer returns the Enforcer of the provider, looking through any
+// wrappers, and false if the provider cannot enforce.
+func AsEnforcer(p Provider) (Enforcer, bool) {
+	for p != nil {
+		if e, ok := p.(Enforcer); ok {
+			return e, true
+		}
+
+		w, ok := p.(Wrapper)
+		if !ok {
+			return nil, false
+		}
+		p = w.Unwrap()
+	}
+
+	return nil, false
+}
+
+// ValidAction reports whether a is a supported enforcement action.
+func ValidAction(a Action) bool {
+	for i := range Actions {
+		if Actions[i] == a {
+			return true
+		}
+	}
+
+	return false
+}
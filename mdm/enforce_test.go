@@ -0,0 +1,48 @@
+package mdm
+
+import "testing"
+
+type enforcingProvider struct {
+	Provider
+}
+
+func (enforcingProvider) Enforce(Action, *Device, *EnforceOpts) error { return nil }
+
+type wrappingProvider struct {
+	Provider
+	inner Provider
+}
+
+func (w wrappingProvider) Unwrap() Provider { return w.inner }
+
+func TestAsEnforcer(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Provider
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", wrappingProvider{}, false},
+		{"enforcer", enforcingProvider{}, true},
+		{"wrapped enforcer", wrappingProvider{inner: enforcingProvider{}}, true},
+		{"double wrapped", wrappingProvider{inner: wrappingProvider{inner: enforcingProvider{}}}, true},
+	}
+
+	for _, tt := range tests {
+		if _, ok := AsEnforcer(tt.p); ok != tt.want {
+			t.Errorf("%s: AsEnforcer() = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+}
+
+func TestValidAction(t *testing.T) {
+	for _, a := range Actions {
+		if !ValidAction(a) {
+			t.Errorf("expected %q to be valid", a)
+		}
+	}
+
+	if ValidAction("reboot") {
+		t.Error("expected reboot to be invalid")
+	}
+}
@@ -0,0 +1,270 @@
+package file
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+func init() {
+	mdm.Register(mdm.File, func() mdm.Provider { return &Client{} })
+}
+
+// Client reads devices from an inventory file on disk.
+//
+// The file is read on every call so edits are picked up without a restart.
+// The format is chosen by the extension: .json, .yaml, .yml or .csv.
+type Client struct {
+	path string
+	log  logger.Logger
+}
+
+// Config represents the configuration for the file client.
+type Config struct {
+	Path string
+	Log  *logger.Logger
+}
+
+// Record is a single row of the inventory. JSON and YAML files hold a list
+// of records, CSV files use the tag names as the header row.
+//
+// Only the serial number is required. If the device id is missing the serial
+// number is used in its place, and if the platform is missing macOS is assumed.
+type Record struct {
+	DeviceID     string `json:"device_id" yaml:"device_id"`
+	DeviceName   string `json:"device_name" yaml:"device_name"`
+	Model        string `json:"model" yaml:"model"`
+	SerialNumber string `json:"serial_number" yaml:"serial_number"`
+	Platform     string `json:"platform" yaml:"platform"`
+	OSVersion    string `json:"os_version" yaml:"os_version"`
+	LastCheckIn  string `json:"last_check_in" yaml:"last_check_in"`
+	AssetTag     string `json:"asset_tag" yaml:"asset_tag"`
+	UserEmail    string `json:"user_email" yaml:"user_email"`
+	UserName     string `json:"user_name" yaml:"user_name"`
+	UserID       string `json:"user_id" yaml:"user_id"`
+}
+
+// checkInFormats are the layouts accepted for last_check_in.
+var checkInFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+}
+
+// Setup implements mdm.Provider.
+//
+// The path to the inventory is read from the URL.
+func (c *Client) Setup(m mdm.Config) {
+	c.path = m.URL
+	c.log = logger.ChildLogger("file", &m.Log)
+}
+
+// New returns a client reading the inventory at c.Path.
+func New(c *Config) *Client {
+	return &Client{
+		path: c.Path,
+		log:  logger.ChildLogger("file", c.Log),
+	}
+}
+
+// ListDevices implements mdm.Provider.
+func (c *Client) ListDevices() ([]mdm.Device, error) {
+	return c.read()
+}
+
+// GetDevice implements mdm.Provider. The id may be the device id or serial number.
+func (c *Client) GetDevice(deviceID string) (*mdm.Device, error) {
+	res, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range res {
+		if res[i].DeviceID == deviceID || res[i].SerialNumber == deviceID {
+			return &res[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("device %s not found in %s", deviceID, c.path)
+}
+
+// QueryDevices implements mdm.Provider.
+func (c *Client) QueryDevices(opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
+	res, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := mdm.DeviceResults{}
+	for i := range res {
+		if opts.Match(&res[i]) {
+			matched = append(matched, res[i])
+		}
+	}
+
+	return matched, nil
+}
+
+// GetUsers implements mdm.Provider.
+func (c *Client) GetUsers(opts *mdm.QueryOpts) ([]mdm.User, error) {
+	res, err := c.QueryDevices(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	mu := []mdm.User{}
+	for i := range res {
+		mu = append(mu, res[i].User)
+	}
+
+	return mu, nil
+}
+
+func (c *Client) read() (mdm.DeviceResults, error) {
+	f, err := os.Open(c.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening inventory")
+	}
+	defer f.Close()
+
+	var records []Record
+
+	switch ext := strings.ToLower(filepath.Ext(c.path)); ext {
+	case ".json":
+		err = json.NewDecoder(f).Decode(&records)
+	case ".yaml", ".yml":
+		err = yaml.NewDecoder(f).Decode(&records)
+		if err == io.EOF {
+			err = nil
+		}
+	case ".csv":
+		records, err = readCSV(f)
+	default:
+		return nil, fmt.Errorf("unsupported inventory format %q", ext)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading inventory %s", c.path)
+	}
+
+	res := mdm.DeviceResults{}
+	for i := range records {
+		if records[i].SerialNumber == "" {
+			c.log.Debug().Int("record", i).Msg("skipping record without a serial number")
+			continue
+		}
+		res = append(res, transform(&records[i]))
+	}
+
+	c.log.Trace().Int("devices", len(res)).Str("path", c.path).Msg("read inventory")
+
+	return res, nil
+}
+
+// readCSV maps each row onto a Record using the header row. Headers are
+// matched case-insensitively and unknown columns are ignored.
+func readCSV(r io.Reader) ([]Record, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := map[string]int{}
+	for i, h := range rows[0] {
+		header[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	if _, ok := header["serial_number"]; !ok {
+		return nil, errors.New("csv header is missing serial_number")
+	}
+
+	col := func(row []string, name string) string {
+		i, ok := header[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	records := make([]Record, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		records = append(records, Record{
+			DeviceID:     col(row, "device_id"),
+			DeviceName:   col(row, "device_name"),
+			Model:        col(row, "model"),
+			SerialNumber: col(row, "serial_number"),
+			Platform:     col(row, "platform"),
+			OSVersion:    col(row, "os_version"),
+			LastCheckIn:  col(row, "last_check_in"),
+			AssetTag:     col(row, "asset_tag"),
+			UserEmail:    col(row, "user_email"),
+			UserName:     col(row, "user_name"),
+			UserID:       col(row, "user_id"),
+		})
+	}
+
+	return records, nil
+}
+
+func parseCheckIn(s string) *time.Time {
+	for _, f := range checkInFormats {
+		if t, err := time.Parse(f, s); err == nil {
+			return &t
+		}
+	}
+
+	return nil
+}
+
+func transform(r *Record) mdm.Device {
+	id := r.DeviceID
+	if id == "" {
+		id = r.SerialNumber
+	}
+
+	platform := r.Platform
+	if platform == "" {
+		platform = mdm.MacOS
+	}
+
+	userID := r.UserID
+	if userID == "" {
+		userID = r.UserEmail
+	}
+
+	return mdm.Device{
+		DeviceID:     id,
+		DeviceName:   r.DeviceName,
+		Model:        r.Model,
+		SerialNumber: r.SerialNumber,
+		Platform:     platform,
+		OSVersion:    r.OSVersion,
+		LastCheckIn:  parseCheckIn(r.LastCheckIn),
+		AssetTag:     r.AssetTag,
+		User: mdm.User{
+			Email: r.UserEmail,
+			Name:  r.UserName,
+			ID:    userID,
+		},
+	}
+}
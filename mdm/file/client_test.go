@@ -0,0 +1,169 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+const jsonInventory = `[
+  {"serial_number": "C02AAA", "device_name": "mac-a", "os_version": "13.4.1", "user_email": "a@example.com", "last_check_in": "2023-07-01T12:00:00Z"},
+  {"serial_number": "C02BBB", "device_id": "42", "platform": "Windows", "os_version": "10.0", "user_email": "b@example.com"},
+  {"device_name": "no-serial"}
+]`
+
+const yamlInventory = `
+- serial_number: C02AAA
+  device_name: mac-a
+  os_version: 13.4.1
+  user_email: a@example.com
+- serial_number: C02BBB
+  os_version: 12.6
+  user_email: b@example.com
+`
+
+const csvInventory = `Serial_Number,Device_Name,OS_Version,User_Email,User_Name,Last_Check_In,Notes
+C02AAA,mac-a,13.4.1,a@example.com,User A,2023-07-01,loaner
+C02BBB,mac-b,12.6,b@example.com,User B,,
+`
+
+func write(t *testing.T, name, content string) string {
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func testClient(path string) *Client {
+	c := &Client{}
+	c.Setup(mdm.Config{
+		URL: path,
+		Log: logger.NewLogger(&logger.Config{Level: "error"}),
+	})
+
+	return c
+}
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"inventory.json", jsonInventory, 2},
+		{"inventory.yaml", yamlInventory, 2},
+		{"inventory.csv", csvInventory, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testClient(write(t, tt.name, tt.content))
+
+			res, err := c.ListDevices()
+			if err != nil {
+				t.Fatalf("ListDevices() returned error: %v", err)
+			}
+
+			if len(res) != tt.want {
+				t.Fatalf("ListDevices() returned %d devices, want %d", len(res), tt.want)
+			}
+
+			d := res[0]
+			if d.SerialNumber != "C02AAA" ||
+				d.DeviceID != "C02AAA" ||
+				d.DeviceName != "mac-a" ||
+				d.OSVersion != "13.4.1" ||
+				d.Platform != mdm.MacOS ||
+				d.User.Email != "a@example.com" {
+				t.Errorf("unexpected device: %+v", d)
+			}
+		})
+	}
+}
+
+func TestCheckInParsed(t *testing.T) {
+	c := testClient(write(t, "inventory.csv", csvInventory))
+
+	d, err := c.GetDevice("C02AAA")
+	if err != nil {
+		t.Fatalf("GetDevice() returned error: %v", err)
+	}
+
+	want := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	if d.LastCheckIn == nil || !d.LastCheckIn.Equal(want) {
+		t.Errorf("unexpected check in, got: %v, want: %v", d.LastCheckIn, want)
+	}
+
+	if d.User.Name != "User A" {
+		t.Errorf("unexpected user name: %s", d.User.Name)
+	}
+}
+
+func TestReReadOnList(t *testing.T) {
+	path := write(t, "inventory.json", jsonInventory)
+	c := testClient(path)
+
+	res, err := c.ListDevices()
+	if err != nil || len(res) != 2 {
+		t.Fatalf("ListDevices() got %d devices, error: %v", len(res), err)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"serial_number": "C02CCC"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = c.ListDevices()
+	if err != nil {
+		t.Fatalf("ListDevices() returned error: %v", err)
+	}
+
+	if len(res) != 1 || res[0].SerialNumber != "C02CCC" {
+		t.Errorf("expected edits to be picked up, got: %+v", res)
+	}
+}
+
+func TestQueryDevices(t *testing.T) {
+	c := testClient(write(t, "inventory.json", jsonInventory))
+
+	res, err := c.QueryDevices(&mdm.QueryOpts{UserEmail: "B@example.com"})
+	if err != nil {
+		t.Fatalf("QueryDevices() returned error: %v", err)
+	}
+
+	if len(res) != 1 || res[0].DeviceID != "42" || res[0].Platform != "Windows" {
+		t.Errorf("unexpected results: %+v", res)
+	}
+
+	users, err := c.GetUsers(nil)
+	if err != nil {
+		t.Fatalf("GetUsers() returned error: %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Errorf("GetUsers() returned %d users, want 2", len(users))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := testClient(filepath.Join(t.TempDir(), "missing.json")).ListDevices(); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+
+	if _, err := testClient(write(t, "inventory.txt", "")).ListDevices(); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+
+	if _, err := testClient(write(t, "inventory.csv", "name\nmac-a\n")).ListDevices(); err == nil {
+		t.Error("expected an error for a csv without serial_number")
+	}
+
+	if _, err := testClient(write(t, "inventory.json", jsonInventory)).GetDevice("nope"); err == nil {
+		t.Error("expected an error for a missing device")
+	}
+}
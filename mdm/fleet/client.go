@@ -0,0 +1,150 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+func init() {
+	mdm.Register(mdm.Fleet, func() mdm.Provider { return &Client{} })
+}
+
+// Client holds the values needed to interact with the Fleet API.
+type Client struct {
+	token   string
+	baseURL string
+	filter  Filter
+
+	client *http.Client
+	log    logger.Logger
+	ctx    context.Context
+}
+
+// Filter limits the hosts returned from Fleet. Zero values are ignored.
+//
+// Pass a *Filter as mdm.Config.ProviderSpecificConfig to set it through Setup.
+type Filter struct {
+	TeamID  uint `json:"team_id,omitempty"`
+	LabelID uint `json:"label_id,omitempty"`
+}
+
+// Config represents the configuration for the Fleet client.
+type Config struct {
+	Token   string
+	BaseURL string
+	Filter  Filter
+
+	Client *http.Client
+	Log    *logger.Logger
+}
+
+// Setup implements mdm.Provider.
+func (c *Client) Setup(m mdm.Config) {
+	c.token = helpers.TokenValidator(m.Token, "Bearer")
+	c.baseURL = helpers.URLShaper(m.URL, "api/v1/fleet/")
+	c.client = httpClient(m.Client)
+	c.log = logger.ChildLogger("fleet", &m.Log)
+	c.ctx = context.Background()
+
+	switch f := m.ProviderSpecificConfig.(type) {
+	case *Filter:
+		if f != nil {
+			c.filter = *f
+		}
+	case Filter:
+		c.filter = f
+	}
+}
+
+// New returns a client to communicate with the Fleet API.
+func New(c *Config) *Client {
+	return &Client{
+		token:   helpers.TokenValidator(c.Token, "Bearer"),
+		baseURL: helpers.URLShaper(c.BaseURL, "api/v1/fleet/"),
+		filter:  c.Filter,
+		client:  httpClient(c.Client),
+		log:     logger.ChildLogger("fleet", c.Log),
+		ctx:     context.Background(),
+	}
+}
+
+// WithContext implements mdm.Binder.
+func (c *Client) WithContext(ctx context.Context) mdm.Provider {
+	bound := *c
+	bound.ctx = ctx
+
+	return &bound
+}
+
+// GetDevice implements mdm.Provider.
+func (c *Client) GetDevice(deviceID string) (*mdm.Device, error) {
+	return c.getDevice(deviceID)
+}
+
+// ListDevices implements mdm.Provider.
+func (c *Client) ListDevices() ([]mdm.Device, error) {
+	return c.ListAllDevices()
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+
+	return &http.Client{
+		Timeout: time.Minute,
+	}
+}
+
+func (c *Client) newRequest(method, url string, body interface{}) (*http.Request, error) {
+	var buf bytes.Buffer
+
+	u := fmt.Sprintf("%s%s", c.baseURL, strings.TrimPrefix(url, "/"))
+
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			c.log.Err(err).Msg("error encoding payload body")
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(c.ctx, method, u, &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", c.token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-type", "application/json;charset=utf-8")
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request, v interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("error during request. status code=%d error: %s", resp.StatusCode, string(body))
+	}
+
+	if v != nil {
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+
+	return nil
+}
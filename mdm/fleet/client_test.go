@@ -0,0 +1,234 @@
+package fleet
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+var seen = time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+
+// hosts served by the stand-in server. Every third host is linux and every
+// fifth is windows.
+func host(i int) Host {
+	h := Host{
+		ID:             uint(i + 1),
+		Hostname:       "host-" + strconv.Itoa(i),
+		ComputerName:   "mac-" + strconv.Itoa(i),
+		HardwareSerial: "SERIAL" + strconv.Itoa(i),
+		HardwareModel:  "MacBookPro18,3",
+		Platform:       "darwin",
+		OSVersion:      "macOS 13.4.1",
+		SeenTime:       &seen,
+		DeviceMapping: []DeviceMapping{
+			{Email: "user" + strconv.Itoa(i) + "@example.com", Source: "google_chrome_profiles"},
+		},
+	}
+
+	switch {
+	case i%3 == 0:
+		h.Platform = "ubuntu"
+		h.OSVersion = "Ubuntu 22.04.2 LTS"
+	case i%5 == 0:
+		h.Platform = "windows"
+		h.OSVersion = "Microsoft Windows 11 Enterprise 22H2 10.0.22621.1848"
+	}
+
+	return h
+}
+
+func newFleetServer(t *testing.T, total int) (*httptest.Server, *[]string) {
+	var queries []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/fleet/hosts", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fleet-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		queries = append(queries, r.URL.RawQuery)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		res := Hosts{Hosts: []Host{}}
+		for i := page * size; i < (page+1)*size && i < total; i++ {
+			res.Hosts = append(res.Hosts, host(i))
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	})
+	mux.HandleFunc("/api/v1/fleet/hosts/2", func(w http.ResponseWriter, r *http.Request) {
+		h := host(1)
+		h.DeviceMapping = nil
+		_ = json.NewEncoder(w).Encode(HostResponse{Host: h})
+	})
+	mux.HandleFunc("/api/v1/fleet/hosts/2/device_mapping", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(DeviceMappingResponse{
+			HostID:        2,
+			DeviceMapping: []DeviceMapping{{Email: "user1@example.com"}},
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv, &queries
+}
+
+func testClient(srv *httptest.Server, filter interface{}) *Client {
+	c := &Client{}
+	c.Setup(mdm.Config{
+		URL:                    srv.URL,
+		Token:                  "fleet-token",
+		Log:                    logger.NewLogger(&logger.Config{Level: "error"}),
+		ProviderSpecificConfig: filter,
+	})
+
+	return c
+}
+
+func TestListDevices(t *testing.T) {
+	srv, queries := newFleetServer(t, perPage+10)
+	c := testClient(srv, &Filter{TeamID: 4, LabelID: 9})
+
+	devices, err := c.ListDevices()
+	if err != nil {
+		t.Fatalf("ListDevices() returned error: %v", err)
+	}
+
+	if len(devices) != perPage+10 {
+		t.Fatalf("ListDevices() returned %d devices, want %d", len(devices), perPage+10)
+	}
+
+	for _, q := range *queries {
+		v, _ := urlValues(q)
+		if v["team_id"] != "4" || v["label_id"] != "9" || v["device_mapping"] != "true" {
+			t.Errorf("expected team and label filters in query, got: %s", q)
+		}
+	}
+
+	d := devices[1]
+	if d.DeviceID != "2" ||
+		d.DeviceName != "mac-1" ||
+		d.SerialNumber != "SERIAL1" ||
+		d.Platform != mdm.MacOS ||
+		d.OSVersion != "13.4.1" ||
+		d.User.Email != "user1@example.com" {
+		t.Errorf("unexpected device mapping: %+v", d)
+	}
+
+	if devices[3].Platform != mdm.Linux || devices[3].OSVersion != "22.04.2" {
+		t.Errorf("unexpected linux mapping: %+v", devices[3])
+	}
+
+	if devices[5].Platform != mdm.Windows {
+		t.Errorf("unexpected windows mapping: %+v", devices[5])
+	}
+}
+
+func TestQueryDevices(t *testing.T) {
+	srv, queries := newFleetServer(t, 20)
+	c := testClient(srv, nil)
+
+	res, err := c.QueryDevices(&mdm.QueryOpts{SerialNumber: "SERIAL7"})
+	if err != nil {
+		t.Fatalf("QueryDevices() returned error: %v", err)
+	}
+
+	if len(res) != 1 || res[0].SerialNumber != "SERIAL7" {
+		t.Errorf("unexpected results: %+v", res)
+	}
+
+	v, _ := urlValues((*queries)[0])
+	if v["query"] != "SERIAL7" {
+		t.Errorf("expected the serial to be sent as the search, got: %s", (*queries)[0])
+	}
+}
+
+func TestGetDevice(t *testing.T) {
+	srv, _ := newFleetServer(t, 0)
+	c := testClient(srv, nil)
+
+	d, err := c.GetDevice("2")
+	if err != nil {
+		t.Fatalf("GetDevice() returned error: %v", err)
+	}
+
+	if d.User.Email != "user1@example.com" {
+		t.Errorf("expected the device mapping email, got: %s", d.User.Email)
+	}
+}
+
+func urlValues(q string) (map[string]string, error) {
+	r, err := http.NewRequest(http.MethodGet, "/?"+q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]string{}
+	for k, v := range r.URL.Query() {
+		m[k] = v[0]
+	}
+
+	return m, nil
+}
+
+func TestEnforce(t *testing.T) {
+	var run runCommand
+	var transfer transferHosts
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/fleet/hosts/2", func(w http.ResponseWriter, r *http.Request) {
+		h := host(1)
+		h.UUID = "UUID-2"
+		_ = json.NewEncoder(w).Encode(HostResponse{Host: h})
+	})
+	mux.HandleFunc("/api/v1/fleet/commands/run", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&run)
+		_, _ = w.Write([]byte(`{"command_uuid":"abc","request_type":"ScheduleOSUpdate"}`))
+	})
+	mux.HandleFunc("/api/v1/fleet/hosts/transfer", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&transfer)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c := testClient(srv, nil)
+	d := &mdm.Device{DeviceID: "2"}
+	opts := &mdm.EnforceOpts{Version: "13.5", Group: "4"}
+
+	if err := c.Enforce(mdm.ForceInstall, d, opts); err != nil {
+		t.Fatalf("Enforce() returned error: %v", err)
+	}
+	if len(run.HostUUIDs) != 1 || run.HostUUIDs[0] != "UUID-2" {
+		t.Errorf("unexpected hosts for the command: %v", run.HostUUIDs)
+	}
+
+	cmd, err := base64.StdEncoding.DecodeString(run.Command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"ScheduleOSUpdate", "InstallForceRestart", "<string>13.5</string>"} {
+		if !strings.Contains(string(cmd), want) {
+			t.Errorf("expected the command to contain %q, got:\n%s", want, cmd)
+		}
+	}
+
+	if err := c.Enforce(mdm.Remediate, d, opts); err != nil {
+		t.Fatalf("Enforce() returned error: %v", err)
+	}
+	if transfer.TeamID != 4 || len(transfer.Hosts) != 1 || transfer.Hosts[0] != 2 {
+		t.Errorf("unexpected transfer: %+v", transfer)
+	}
+
+	if err := c.Enforce(mdm.Remediate, d, &mdm.EnforceOpts{Group: "blueprint"}); err == nil {
+		t.Error("expected an error for a non numeric team id")
+	}
+}
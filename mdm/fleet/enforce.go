@@ -0,0 +1,139 @@
+package fleet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+// installActions maps the enforcement actions to the ScheduleOSUpdate install action.
+//   - https://developer.apple.com/documentation/devicemanagement/scheduleosupdatecommand/command/updatesitem
+var installActions = map[mdm.Action]string{
+	mdm.ScheduleUpdate: "Default",
+	mdm.ForceInstall:   "InstallForceRestart",
+}
+
+// runCommand is the body of the run mdm command endpoint.
+//   - https://fleetdm.com/docs/rest-api/rest-api#run-mdm-command
+type runCommand struct {
+	Command   string   `json:"command"`
+	HostUUIDs []string `json:"host_uuids"`
+}
+
+// transferHosts is the body of the transfer hosts endpoint.
+//   - https://fleetdm.com/docs/rest-api/rest-api#transfer-hosts-to-a-team
+type transferHosts struct {
+	TeamID uint   `json:"team_id"`
+	Hosts  []uint `json:"hosts"`
+}
+
+// Enforce implements mdm.Enforcer. Updates are sent as a ScheduleOSUpdate MDM
+// command and Remediate moves the host to the team id in opts.Group.
+func (c *Client) Enforce(action mdm.Action, device *mdm.Device, opts *mdm.EnforceOpts) error {
+	switch action {
+	case mdm.ScheduleUpdate, mdm.ForceInstall:
+		return c.scheduleUpdate(device.DeviceID, installActions[action], opts.Version)
+	case mdm.Remediate:
+		return c.transfer(device.DeviceID, opts.Group)
+	default:
+		return mdm.ErrActionNotSupported
+	}
+}
+
+func (c *Client) scheduleUpdate(id, installAction, version string) error {
+	req, err := c.newRequest(http.MethodGet, fmt.Sprintf("hosts/%s", url.PathEscape(id)), nil)
+	if err != nil {
+		return err
+	}
+
+	var hr HostResponse
+	if err := c.do(req, &hr); err != nil {
+		return errors.Wrap(err, "looking up host uuid")
+	}
+
+	cmd, err := scheduleOSUpdateCommand(installAction, version)
+	if err != nil {
+		return err
+	}
+
+	req, err = c.newRequest(http.MethodPost, "commands/run", &runCommand{
+		Command:   base64.StdEncoding.EncodeToString(cmd),
+		HostUUIDs: []string{hr.Host.UUID},
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.do(req, nil)
+}
+
+func (c *Client) transfer(id, team string) error {
+	hostID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid host id %q", id)
+	}
+
+	teamID, err := strconv.ParseUint(team, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid team id %q", team)
+	}
+
+	req, err := c.newRequest(http.MethodPost, "hosts/transfer", &transferHosts{
+		TeamID: uint(teamID),
+		Hosts:  []uint{uint(hostID)},
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.do(req, nil)
+}
+
+// scheduleOSUpdateCommand builds the plist for a ScheduleOSUpdate command.
+// An empty version lets the device pick the latest available update.
+func scheduleOSUpdateCommand(installAction, version string) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	var update bytes.Buffer
+	update.WriteString("<dict><key>InstallAction</key><string>")
+	if err := xml.EscapeText(&update, []byte(installAction)); err != nil {
+		return nil, err
+	}
+	update.WriteString("</string>")
+	if version != "" {
+		update.WriteString("<key>ProductVersion</key><string>")
+		if err := xml.EscapeText(&update, []byte(version)); err != nil {
+			return nil, err
+		}
+		update.WriteString("</string>")
+	}
+	update.WriteString("</dict>")
+
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CommandUUID</key>
+	<string>%x-%x-%x-%x-%x</string>
+	<key>Command</key>
+	<dict>
+		<key>RequestType</key>
+		<string>ScheduleOSUpdate</string>
+		<key>Updates</key>
+		<array>%s</array>
+	</dict>
+</dict>
+</plist>`, id[0:4], id[4:6], id[6:8], id[8:10], id[10:], update.String())), nil
+}
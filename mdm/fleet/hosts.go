@@ -0,0 +1,236 @@
+package fleet
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+// perPage is the number of hosts requested per page.
+const perPage = 100
+
+// Hosts is the response from the list hosts endpoint.
+//   - https://fleetdm.com/docs/rest-api/rest-api#list-hosts
+type Hosts struct {
+	Hosts []Host `json:"hosts"`
+}
+
+// HostResponse is the response from the get host endpoint.
+type HostResponse struct {
+	Host Host `json:"host"`
+}
+
+// DeviceMappingResponse is the response from the host device mapping endpoint.
+type DeviceMappingResponse struct {
+	HostID        uint            `json:"host_id"`
+	DeviceMapping []DeviceMapping `json:"device_mapping"`
+}
+
+// DeviceMapping ties an email to a host.
+type DeviceMapping struct {
+	Email  string `json:"email"`
+	Source string `json:"source"`
+}
+
+// Host is the subset of the host resource used by cuebert.
+type Host struct {
+	ID             uint            `json:"id"`
+	UUID           string          `json:"uuid"`
+	Hostname       string          `json:"hostname"`
+	ComputerName   string          `json:"computer_name"`
+	DisplayName    string          `json:"display_name"`
+	HardwareSerial string          `json:"hardware_serial"`
+	HardwareModel  string          `json:"hardware_model"`
+	Platform       string          `json:"platform"`
+	OSVersion      string          `json:"os_version"`
+	SeenTime       *time.Time      `json:"seen_time"`
+	CreatedAt      *time.Time      `json:"created_at"`
+	LastEnrolledAt *time.Time      `json:"last_enrolled_at"`
+	TeamID         *uint           `json:"team_id"`
+	DeviceMapping  []DeviceMapping `json:"device_mapping"`
+}
+
+// ListAllDevices will page through the hosts matching the configured
+// team and label filters and return the results.
+func (c *Client) ListAllDevices() (mdm.DeviceResults, error) {
+	return c.hosts("")
+}
+
+// QueryDevices will return devices based off query passed.
+//
+// Fleet only supports a free text search so the serial number, device
+// name or user email is sent as the search and the results are then
+// matched against every option.
+func (c *Client) QueryDevices(opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
+	if opts == nil {
+		return c.ListAllDevices()
+	}
+
+	search := opts.SerialNumber
+	if search == "" {
+		search = opts.DeviceName
+	}
+	if search == "" {
+		search = opts.UserEmail
+	}
+
+	res, err := c.hosts(search)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := mdm.DeviceResults{}
+	for i := range res {
+		if opts.Match(&res[i]) {
+			matched = append(matched, res[i])
+		}
+	}
+
+	return matched, nil
+}
+
+func (c *Client) getDevice(id string) (*mdm.Device, error) {
+	req, err := c.newRequest(http.MethodGet, fmt.Sprintf("hosts/%s", url.PathEscape(id)), nil)
+	if err != nil {
+		c.log.Err(err).Msg("error building request")
+		return nil, err
+	}
+
+	var hr HostResponse
+	if err := c.do(req, &hr); err != nil {
+		c.log.Err(err).Msg("error making request")
+		return nil, err
+	}
+
+	// the host details do not include the device mapping.
+	req, err = c.newRequest(http.MethodGet, fmt.Sprintf("hosts/%s/device_mapping", url.PathEscape(id)), nil)
+	if err != nil {
+		c.log.Err(err).Msg("error building request")
+		return nil, err
+	}
+
+	var dm DeviceMappingResponse
+	if err := c.do(req, &dm); err != nil {
+		c.log.Err(err).Msg("error getting device mapping")
+		return nil, err
+	}
+	hr.Host.DeviceMapping = dm.DeviceMapping
+
+	d := transform(&hr.Host)
+
+	return &d, nil
+}
+
+func (c *Client) hosts(search string) (mdm.DeviceResults, error) {
+	res := mdm.DeviceResults{}
+
+	for page := 0; ; page++ {
+		hosts, err := c.list(page, search)
+		if err != nil {
+			c.log.Err(err).Int("page", page).Msg("error listing devices")
+			return res, err
+		}
+
+		for i := range hosts.Hosts {
+			res = append(res, transform(&hosts.Hosts[i]))
+		}
+
+		if len(hosts.Hosts) < perPage {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+func (c *Client) list(page int, search string) (*Hosts, error) {
+	q := url.Values{}
+	q.Set("page", strconv.Itoa(page))
+	q.Set("per_page", strconv.Itoa(perPage))
+	q.Set("order_key", "id")
+	q.Set("device_mapping", "true")
+	if c.filter.TeamID != 0 {
+		q.Set("team_id", strconv.FormatUint(uint64(c.filter.TeamID), 10))
+	}
+	if c.filter.LabelID != 0 {
+		q.Set("label_id", strconv.FormatUint(uint64(c.filter.LabelID), 10))
+	}
+	if search != "" {
+		q.Set("query", search)
+	}
+
+	req, err := c.newRequest(http.MethodGet, "hosts?"+q.Encode(), nil)
+	if err != nil {
+		c.log.Err(err).Msg("error building request")
+		return nil, err
+	}
+
+	var hosts Hosts
+	if err := c.do(req, &hosts); err != nil {
+		c.log.Err(err).Str("url", req.URL.String()).Msg("error making request")
+		return nil, err
+	}
+
+	return &hosts, nil
+}
+
+// osVersion strips the name fleet prefixes the version with
+// (ex: macOS 13.4.1, Ubuntu 22.04.2 LTS) so it can be compared.
+func osVersion(v string) string {
+	for _, f := range strings.Fields(v) {
+		if f[0] >= '0' && f[0] <= '9' {
+			return f
+		}
+	}
+
+	return v
+}
+
+// mappedEmail returns the first email mapped to the host.
+func mappedEmail(dm []DeviceMapping) string {
+	for _, m := range dm {
+		if m.Email != "" {
+			return m.Email
+		}
+	}
+
+	return ""
+}
+
+func dateString(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func transform(h *Host) mdm.Device {
+	name := h.ComputerName
+	if name == "" {
+		name = h.Hostname
+	}
+
+	email := mappedEmail(h.DeviceMapping)
+
+	return mdm.Device{
+		DeviceID:        strconv.FormatUint(uint64(h.ID), 10),
+		DeviceName:      name,
+		Model:           h.HardwareModel,
+		SerialNumber:    h.HardwareSerial,
+		Platform:        mdm.NormalizePlatform(h.Platform),
+		OSVersion:       osVersion(h.OSVersion),
+		LastCheckIn:     h.SeenTime,
+		FirstEnrollment: dateString(h.CreatedAt),
+		LastEnrollment:  dateString(h.LastEnrolledAt),
+		User: mdm.User{
+			Email: email,
+			ID:    email,
+		},
+	}
+}
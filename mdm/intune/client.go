@@ -0,0 +1,83 @@
+package intune
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/graph"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+func init() {
+	mdm.Register(mdm.Intune, func() mdm.Provider { return &Client{} })
+}
+
+// Client holds the values needed to interact with Intune through Microsoft Graph.
+//
+// The app registration used needs the DeviceManagementManagedDevices.Read.All
+// application permission.
+type Client struct {
+	graph *graph.Client
+	log   logger.Logger
+}
+
+// Config represents the configuration for the Intune client.
+type Config struct {
+	// Tenant is the Entra ID tenant the app registration lives in. It is only
+	// used to build the token url if TokenURL is empty.
+	Tenant       string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	BaseURL      string
+
+	Client *http.Client
+	Log    *logger.Logger
+}
+
+// Setup implements mdm.Provider.
+//
+// User and Password are the client id and secret of the app registration.
+// If TokenURL is not set the token endpoint is built from Domain as the tenant.
+func (c *Client) Setup(m mdm.Config) {
+	*c = *New(&Config{
+		Tenant:       m.Domain,
+		TokenURL:     m.TokenURL,
+		ClientID:     m.User,
+		ClientSecret: m.Password,
+		BaseURL:      m.URL,
+		Client:       m.Client,
+		Log:          &m.Log,
+	})
+}
+
+// New returns a client to communicate with Intune.
+func New(c *Config) *Client {
+	return &Client{
+		graph: graph.New(&graph.Config{
+			Tenant:       c.Tenant,
+			TokenURL:     c.TokenURL,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			BaseURL:      c.BaseURL,
+			Client:       c.Client,
+		}),
+		log: logger.ChildLogger("intune", c.Log),
+	}
+}
+
+// WithContext implements mdm.Binder.
+func (c *Client) WithContext(ctx context.Context) mdm.Provider {
+	return &Client{graph: c.graph.WithContext(ctx), log: c.log}
+}
+
+// GetDevice implements mdm.Provider.
+func (c *Client) GetDevice(deviceID string) (*mdm.Device, error) {
+	return c.getDevice(deviceID)
+}
+
+// ListDevices implements mdm.Provider.
+func (c *Client) ListDevices() ([]mdm.Device, error) {
+	return c.ListAllDevices()
+}
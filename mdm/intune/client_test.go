@@ -0,0 +1,160 @@
+package intune
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// newGraphServer is a stand-in for the token endpoint and Graph. It serves
+// total managed devices across pages of size pageSize.
+func newGraphServer(t *testing.T, total, pageSize int) (*httptest.Server, *string) {
+	var lastFilter string
+	var srv *httptest.Server
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("client_id") != "id" || r.Form.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "graph-token",
+			"expires_in":   3600,
+		})
+	})
+	mux.HandleFunc("/v1.0/deviceManagement/managedDevices", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer graph-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		lastFilter = r.URL.Query().Get("$filter")
+
+		skip, _ := strconv.Atoi(r.URL.Query().Get("$skiptoken"))
+		value := []ManagedDevice{}
+		for i := skip; i < skip+pageSize && i < total; i++ {
+			value = append(value, managedDevice(i))
+		}
+		page := map[string]interface{}{"value": value}
+		if skip+pageSize < total {
+			page["@odata.nextLink"] = srv.URL + "/v1.0/deviceManagement/managedDevices?$skiptoken=" + strconv.Itoa(skip+pageSize)
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv, &lastFilter
+}
+
+func managedDevice(i int) ManagedDevice {
+	id := strconv.Itoa(i)
+	return ManagedDevice{
+		ID:                "dev-" + id,
+		DeviceName:        "mac-" + id,
+		Model:             "MacBookPro18,3",
+		SerialNumber:      "SERIAL" + id,
+		OperatingSystem:   "macOS",
+		OSVersion:         "13.4.1 (22F82)",
+		UserID:            "uid-" + id,
+		UserPrincipalName: "user" + id + "@example.com",
+		UserDisplayName:   "User " + id,
+		LastSyncDateTime:  time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC),
+		EnrolledDateTime:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func testClient(srv *httptest.Server) *Client {
+	c := &Client{}
+	c.Setup(mdm.Config{
+		URL:      srv.URL + "/v1.0",
+		TokenURL: srv.URL + "/token",
+		User:     "id",
+		Password: "secret",
+		Log:      logger.NewLogger(&logger.Config{Level: "error"}),
+	})
+
+	return c
+}
+
+func TestListDevicesFollowsNextLink(t *testing.T) {
+	srv, _ := newGraphServer(t, 25, 10)
+	c := testClient(srv)
+
+	devices, err := c.ListDevices()
+	if err != nil {
+		t.Fatalf("ListDevices() returned error: %v", err)
+	}
+
+	if len(devices) != 25 {
+		t.Fatalf("ListDevices() returned %d devices, want 25", len(devices))
+	}
+
+	d := devices[12]
+	if d.DeviceID != "dev-12" ||
+		d.SerialNumber != "SERIAL12" ||
+		d.Platform != "macOS" ||
+		d.OSVersion != "13.4.1" ||
+		d.FirstEnrollment != "2023-01-01T00:00:00Z" {
+		t.Errorf("unexpected device mapping: %+v", d)
+	}
+
+	if d.LastCheckIn == nil || !d.LastCheckIn.Equal(time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected last check in: %v", d.LastCheckIn)
+	}
+
+	want := mdm.User{Email: "user12@example.com", Name: "User 12", ID: "uid-12"}
+	if d.User != want {
+		t.Errorf("unexpected user mapping, got: %+v, want: %+v", d.User, want)
+	}
+}
+
+func TestQueryDevicesFilter(t *testing.T) {
+	srv, lastFilter := newGraphServer(t, 1, 10)
+	c := testClient(srv)
+
+	_, err := c.QueryDevices(&mdm.QueryOpts{SerialNumber: "SERIAL0", UserEmail: "o'neil@example.com"})
+	if err != nil {
+		t.Fatalf("QueryDevices() returned error: %v", err)
+	}
+
+	want := "serialNumber eq 'SERIAL0' and userPrincipalName eq 'o''neil@example.com'"
+	if *lastFilter != want {
+		t.Errorf("unexpected filter, got: %s, want: %s", *lastFilter, want)
+	}
+}
+
+func TestBadCredentials(t *testing.T) {
+	srv, _ := newGraphServer(t, 1, 10)
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+	c := New(&Config{
+		TokenURL:     srv.URL + "/token",
+		BaseURL:      srv.URL + "/v1.0",
+		ClientID:     "id",
+		ClientSecret: "wrong",
+		Log:          &log,
+	})
+
+	if _, err := c.ListDevices(); err == nil {
+		t.Error("expected an error with invalid credentials")
+	}
+}
+
+func TestNeverSynced(t *testing.T) {
+	d := transform(&ManagedDevice{OSVersion: "14.0"})
+
+	if d.LastCheckIn != nil {
+		t.Errorf("expected a nil check in for a device that never synced, got %v", d.LastCheckIn)
+	}
+	if d.OSVersion != "14.0" {
+		t.Errorf("unexpected os version: %s", d.OSVersion)
+	}
+}
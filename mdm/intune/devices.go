@@ -0,0 +1,176 @@
+package intune
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+// selectFields are the managedDevice properties needed to fill out an mdm.Device.
+var selectFields = []string{
+	"id",
+	"deviceName",
+	"model",
+	"serialNumber",
+	"operatingSystem",
+	"osVersion",
+	"userId",
+	"userPrincipalName",
+	"userDisplayName",
+	"lastSyncDateTime",
+	"enrolledDateTime",
+}
+
+// ManagedDevice is the subset of the managedDevice resource used by cuebert.
+//   - https://learn.microsoft.com/en-us/graph/api/intune-devices-manageddevice-list
+type ManagedDevice struct {
+	ID                string    `json:"id"`
+	DeviceName        string    `json:"deviceName"`
+	Model             string    `json:"model"`
+	SerialNumber      string    `json:"serialNumber"`
+	OperatingSystem   string    `json:"operatingSystem"`
+	OSVersion         string    `json:"osVersion"`
+	UserID            string    `json:"userId"`
+	UserPrincipalName string    `json:"userPrincipalName"`
+	UserDisplayName   string    `json:"userDisplayName"`
+	LastSyncDateTime  time.Time `json:"lastSyncDateTime"`
+	EnrolledDateTime  time.Time `json:"enrolledDateTime"`
+}
+
+// ListAllDevices will follow @odata.nextLink until all devices are returned.
+func (c *Client) ListAllDevices() (mdm.DeviceResults, error) {
+	return c.managedDevices("")
+}
+
+// QueryDevices will return devices based off query passed.
+func (c *Client) QueryDevices(opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
+	if opts == nil {
+		return c.ListAllDevices()
+	}
+
+	return c.managedDevices(filter(opts))
+}
+
+func (c *Client) getDevice(id string) (*mdm.Device, error) {
+	q := url.Values{}
+	q.Set("$select", strings.Join(selectFields, ","))
+
+	var md ManagedDevice
+	err := c.graph.Get(fmt.Sprintf("deviceManagement/managedDevices/%s?%s", url.PathEscape(id), q.Encode()), &md)
+	if err != nil {
+		c.log.Err(err).Msg("error making request")
+		return nil, err
+	}
+
+	d := transform(&md)
+
+	return &d, nil
+}
+
+func (c *Client) managedDevices(f string) (mdm.DeviceResults, error) {
+	q := url.Values{}
+	q.Set("$select", strings.Join(selectFields, ","))
+	if f != "" {
+		q.Set("$filter", f)
+	}
+
+	res := mdm.DeviceResults{}
+
+	path := "deviceManagement/managedDevices?" + q.Encode()
+	err := c.graph.List(path, func(value json.RawMessage) error {
+		var page []ManagedDevice
+		if err := json.Unmarshal(value, &page); err != nil {
+			return err
+		}
+
+		for i := range page {
+			res = append(res, transform(&page[i]))
+		}
+
+		return nil
+	})
+	if err != nil {
+		c.log.Err(err).Str("url", path).Msg("error listing devices")
+		return res, err
+	}
+
+	return res, nil
+}
+
+// filter builds an OData $filter from the query options. Each option set
+// is joined with a logical and.
+func filter(opts *mdm.QueryOpts) string {
+	fields := []struct {
+		key, val string
+	}{
+		{"id", opts.DeviceID},
+		{"deviceName", opts.DeviceName},
+		{"model", opts.Model},
+		{"operatingSystem", opts.Platform},
+		{"osVersion", opts.OSVersion},
+		{"serialNumber", opts.SerialNumber},
+		{"userId", opts.UserID},
+		{"userPrincipalName", opts.UserEmail},
+		{"userDisplayName", opts.UserName},
+	}
+
+	var f []string
+	for _, v := range fields {
+		if v.val == "" {
+			continue
+		}
+		f = append(f, fmt.Sprintf("%s eq '%s'", v.key, strings.ReplaceAll(v.val, "'", "''")))
+	}
+
+	return strings.Join(f, " and ")
+}
+
+// osVersion trims any build information from the version Intune reports
+// for macOS (ex: 13.4.1 (22F82)) so it can be compared.
+func osVersion(v string) string {
+	if i := strings.Index(v, " "); i != -1 {
+		return v[:i]
+	}
+
+	return v
+}
+
+// timeOrNil returns nil for the zero time Graph reports when a device
+// has never synced.
+func timeOrNil(t time.Time) *time.Time {
+	if t.Year() <= 1 {
+		return nil
+	}
+
+	return &t
+}
+
+func dateString(t time.Time) string {
+	if timeOrNil(t) == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func transform(md *ManagedDevice) mdm.Device {
+	return mdm.Device{
+		DeviceID:        md.ID,
+		DeviceName:      md.DeviceName,
+		Model:           md.Model,
+		SerialNumber:    md.SerialNumber,
+		Platform:        md.OperatingSystem,
+		OSVersion:       osVersion(md.OSVersion),
+		LastCheckIn:     timeOrNil(md.LastSyncDateTime),
+		FirstEnrollment: dateString(md.EnrolledDateTime),
+		User: mdm.User{
+			Email: md.UserPrincipalName,
+			Name:  md.UserDisplayName,
+			ID:    md.UserID,
+		},
+	}
+}
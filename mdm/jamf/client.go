@@ -1,24 +1,38 @@
 package jamf
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/johnmikee/cuebert/mdm"
 	"github.com/johnmikee/cuebert/pkg/helpers"
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
-// Client represents the configuration for the Jamf API client
+func init() {
+	mdm.Register(mdm.Jamf, func() mdm.Provider { return &Client{} })
+}
+
+// Config represents the configuration for the Jamf API client
 type Config struct {
 	Domain   string
 	Username string
 	Password string
 	BaseURL  string
 	Log      logger.Logger
-	client   *http.Client
+	Client   *http.Client
 }
 
+// Client holds the values needed to interact with the Jamf Pro API.
 type Client struct {
 	domain   string
 	username string
@@ -26,26 +40,37 @@ type Client struct {
 	url      string
 	log      logger.Logger
 	client   *http.Client
+	ctx      context.Context
+
+	// the token is shared with the copies made by WithContext.
+	auth *auth
 }
 
-// GetUsers implements mdm.Provider.
-func (*Client) GetUsers(opts *mdm.QueryOpts) ([]mdm.User, error) {
-	panic("unimplemented")
+// auth holds the bearer token until it is about to expire.
+type auth struct {
+	token   string
+	expires time.Time
+	lock    sync.Mutex
 }
 
-// QueryDevices implements mdm.Provider.
-func (*Client) QueryDevices(opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
-	panic("unimplemented")
+// refreshWindow is how long before the token expires we request a new one.
+const refreshWindow = time.Minute
+
+// authToken is returned from the token endpoint.
+//   - https://developer.jamf.com/jamf-pro/reference/post_v1-auth-token
+type authToken struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
 }
 
 // GetDevice implements mdm.Provider.
 func (c *Client) GetDevice(deviceID string) (*mdm.Device, error) {
-	panic("unimplemented")
+	return c.getDevice(deviceID)
 }
 
 // ListDevices implements mdm.Provider.
 func (c *Client) ListDevices() ([]mdm.Device, error) {
-	panic("unimplemented")
+	return c.ListAllDevices()
 }
 
 // Setup implements mdm.Provider.
@@ -53,9 +78,19 @@ func (c *Client) Setup(m mdm.Config) {
 	c.domain = m.Domain
 	c.username = m.User
 	c.password = m.Password
-	c.url = helpers.URLShaper(m.URL, "JSSResource")
+	c.url = helpers.URLShaper(m.URL, "api/")
 	c.log = logger.ChildLogger("jamf", &m.Log)
 	c.client = httpClient(m.Client)
+	c.ctx = context.Background()
+	c.auth = &auth{}
+}
+
+// WithContext implements mdm.Binder.
+func (c *Client) WithContext(ctx context.Context) mdm.Provider {
+	bound := *c
+	bound.ctx = ctx
+
+	return &bound
 }
 
 func httpClient(c *http.Client) *http.Client {
@@ -79,8 +114,97 @@ func New(c *Config) *Client {
 		domain:   c.Domain,
 		username: c.Username,
 		password: c.Password,
-		url:      c.BaseURL,
-		log:      c.Log,
-		client:   c.client,
+		url:      helpers.URLShaper(c.BaseURL, "api/"),
+		log:      logger.ChildLogger("jamf", &c.Log),
+		client:   httpClient(c.Client),
+		ctx:      context.Background(),
+		auth:     &auth{},
 	}
 }
+
+// bearer returns a valid bearer token, requesting a new one if there is
+// no token yet or the current one is about to expire.
+func (c *Client) bearer() (string, error) {
+	c.auth.lock.Lock()
+	defer c.auth.lock.Unlock()
+
+	if c.auth.token != "" && time.Now().Add(refreshWindow).Before(c.auth.expires) {
+		return c.auth.token, nil
+	}
+
+	c.log.Trace().Msg("requesting new bearer token")
+
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, c.url+"v1/auth/token", http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", errors.Errorf("error requesting token. status code=%d error: %s", resp.StatusCode, string(body))
+	}
+
+	var at authToken
+	if err := json.NewDecoder(resp.Body).Decode(&at); err != nil {
+		return "", errors.Wrap(err, "decoding token response")
+	}
+
+	c.auth.token = at.Token
+	c.auth.expires = at.Expires
+
+	return c.auth.token, nil
+}
+
+func (c *Client) newRequest(method, url string, body interface{}) (*http.Request, error) {
+	var buf bytes.Buffer
+
+	u := fmt.Sprintf("%s%s", c.url, strings.TrimPrefix(url, "/"))
+
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			c.log.Err(err).Msg("error encoding payload body")
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(c.ctx, method, u, &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.bearer()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", helpers.TokenValidator(token, "Bearer"))
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-type", "application/json;charset=utf-8")
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request, v interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("error during request. status code=%d error: %s", resp.StatusCode, string(body))
+	}
+
+	if v != nil {
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+
+	return nil
+}
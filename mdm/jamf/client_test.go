@@ -0,0 +1,269 @@
+package jamf
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// stubServer is a stand-in for the Jamf Pro API. It hands out tokens that
+// expire after tokenTTL and serves total computer records across pages.
+type stubServer struct {
+	*httptest.Server
+	tokenTTL     time.Duration
+	total        int
+	tokenCalls   int32
+	lastFilter   string
+	lastUpdate   *sendUpdates
+	issuedTokens map[string]bool
+}
+
+func newStubServer(t *testing.T, total int, ttl time.Duration) *stubServer {
+	s := &stubServer{
+		tokenTTL:     ttl,
+		total:        total,
+		issuedTokens: map[string]bool{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if r.Method != http.MethodPost || !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		n := atomic.AddInt32(&s.tokenCalls, 1)
+		tok := "token-" + strconv.Itoa(int(n))
+		s.issuedTokens[tok] = true
+
+		_ = json.NewEncoder(w).Encode(authToken{
+			Token:   tok,
+			Expires: time.Now().Add(s.tokenTTL),
+		})
+	})
+	mux.HandleFunc("/api/v1/computers-inventory", func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s.lastFilter = r.URL.Query().Get("filter")
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("page-size"))
+
+		res := InventoryResults{TotalCount: s.total, Results: []Inventory{}}
+		for i := page * size; i < (page+1)*size && i < s.total; i++ {
+			res.Results = append(res.Results, inventoryRecord(i))
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	})
+	mux.HandleFunc("/api/v1/computers-inventory/7", func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(inventoryRecord(7))
+	})
+
+	mux.HandleFunc("/api/v1/macos-managed-software-updates/send-updates", func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s.lastUpdate = &sendUpdates{}
+		_ = json.NewDecoder(r.Body).Decode(s.lastUpdate)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"deviceIds":["7"],"commands":[]}`))
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *stubServer) authorized(r *http.Request) bool {
+	h := r.Header.Get("Authorization")
+	return len(h) > 7 && s.issuedTokens[h[7:]]
+}
+
+func inventoryRecord(i int) Inventory {
+	checkIn := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	id := strconv.Itoa(i)
+
+	return Inventory{
+		ID: id,
+		General: General{
+			Name:             "mac-" + id,
+			AssetTag:         "A" + id,
+			Platform:         "Mac",
+			LastContactTime:  &checkIn,
+			InitialEntryDate: "2023-01-01",
+			LastEnrolledDate: "2023-06-01",
+		},
+		Hardware: Hardware{
+			Model:        "MacBook Pro (14-inch, 2021)",
+			SerialNumber: "SERIAL" + id,
+		},
+		OperatingSystem: OperatingSystem{
+			Version: "13.4.1",
+		},
+		UserAndLocation: UserAndLocation{
+			Username: "user" + id,
+			Realname: "User " + id,
+			Email:    "user" + id + "@example.com",
+		},
+	}
+}
+
+func testClient(s *stubServer) *Client {
+	c := &Client{}
+	c.Setup(mdm.Config{
+		URL:      s.URL,
+		User:     "admin",
+		Password: "secret",
+		Log:      logger.NewLogger(&logger.Config{Level: "error"}),
+	})
+
+	return c
+}
+
+func TestListDevicesPaginates(t *testing.T) {
+	s := newStubServer(t, 2*pageSize+5, time.Hour)
+	c := testClient(s)
+
+	devices, err := c.ListDevices()
+	if err != nil {
+		t.Fatalf("ListDevices() returned error: %v", err)
+	}
+
+	if len(devices) != 2*pageSize+5 {
+		t.Fatalf("ListDevices() returned %d devices, want %d", len(devices), 2*pageSize+5)
+	}
+
+	if s.tokenCalls != 1 {
+		t.Errorf("expected the token to be reused, got %d token requests", s.tokenCalls)
+	}
+
+	d := devices[3]
+	if d.DeviceID != "3" ||
+		d.DeviceName != "mac-3" ||
+		d.SerialNumber != "SERIAL3" ||
+		d.Model != "MacBook Pro (14-inch, 2021)" ||
+		d.Platform != "Mac" ||
+		d.OSVersion != "13.4.1" ||
+		d.AssetTag != "A3" ||
+		d.FirstEnrollment != "2023-01-01" ||
+		d.LastEnrollment != "2023-06-01" {
+		t.Errorf("unexpected device mapping: %+v", d)
+	}
+
+	if d.LastCheckIn == nil || !d.LastCheckIn.Equal(time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected last check in: %v", d.LastCheckIn)
+	}
+
+	want := mdm.User{Email: "user3@example.com", Name: "User 3", ID: "user3"}
+	if d.User != want {
+		t.Errorf("unexpected user mapping, got: %+v, want: %+v", d.User, want)
+	}
+}
+
+func TestTokenRefreshedBeforeExpiry(t *testing.T) {
+	// tokens that expire inside the refresh window are replaced on every call.
+	s := newStubServer(t, 1, refreshWindow/2)
+	c := testClient(s)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.ListDevices(); err != nil {
+			t.Fatalf("ListDevices() returned error: %v", err)
+		}
+	}
+
+	if s.tokenCalls != 3 {
+		t.Errorf("expected a token request per call, got %d", s.tokenCalls)
+	}
+}
+
+func TestBadCredentials(t *testing.T) {
+	s := newStubServer(t, 1, time.Hour)
+	c := testClient(s)
+	c.password = "wrong"
+
+	if _, err := c.ListDevices(); err == nil {
+		t.Error("expected an error with invalid credentials")
+	}
+}
+
+func TestGetDevice(t *testing.T) {
+	s := newStubServer(t, 10, time.Hour)
+	c := testClient(s)
+
+	d, err := c.GetDevice("7")
+	if err != nil {
+		t.Fatalf("GetDevice() returned error: %v", err)
+	}
+
+	if d.SerialNumber != "SERIAL7" || d.User.Email != "user7@example.com" {
+		t.Errorf("unexpected device: %+v", d)
+	}
+}
+
+func TestQueryDevicesFilter(t *testing.T) {
+	s := newStubServer(t, 1, time.Hour)
+	c := testClient(s)
+
+	_, err := c.QueryDevices(&mdm.QueryOpts{SerialNumber: "SERIAL0", UserEmail: "user0@example.com"})
+	if err != nil {
+		t.Fatalf("QueryDevices() returned error: %v", err)
+	}
+
+	want := `hardware.serialNumber=="SERIAL0";userAndLocation.email=="user0@example.com"`
+	if s.lastFilter != want {
+		t.Errorf("unexpected filter, got: %s, want: %s", s.lastFilter, want)
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	s := newStubServer(t, 10, time.Hour)
+	c := testClient(s)
+	d := &mdm.Device{DeviceID: "7", OSVersion: "13.4.1"}
+	opts := &mdm.EnforceOpts{Version: "13.5"}
+
+	if err := c.Enforce(mdm.ScheduleUpdate, d, opts); err != nil {
+		t.Fatalf("Enforce() returned error: %v", err)
+	}
+	if s.lastUpdate == nil ||
+		s.lastUpdate.DeviceIDs[0] != "7" ||
+		s.lastUpdate.Version != "13.5" ||
+		s.lastUpdate.ApplyMajorUpdate ||
+		s.lastUpdate.ForceRestart ||
+		s.lastUpdate.MaxDeferrals != scheduleDeferrals {
+		t.Errorf("unexpected scheduled update: %+v", s.lastUpdate)
+	}
+
+	// a newer major version is applied only when the policy requires it.
+	if err := c.Enforce(mdm.ScheduleUpdate, d, &mdm.EnforceOpts{Version: "14.1"}); err != nil {
+		t.Fatalf("Enforce() returned error: %v", err)
+	}
+	if !s.lastUpdate.ApplyMajorUpdate {
+		t.Errorf("expected a major update: %+v", s.lastUpdate)
+	}
+
+	if err := c.Enforce(mdm.ForceInstall, d, opts); err != nil {
+		t.Fatalf("Enforce() returned error: %v", err)
+	}
+	if !s.lastUpdate.ForceRestart || s.lastUpdate.MaxDeferrals != 0 {
+		t.Errorf("unexpected forced update: %+v", s.lastUpdate)
+	}
+
+	if err := c.Enforce(mdm.Remediate, d, opts); err != mdm.ErrActionNotSupported {
+		t.Errorf("expected remediate to be unsupported, got %v", err)
+	}
+}
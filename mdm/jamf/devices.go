@@ -0,0 +1,201 @@
+package jamf
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+// pageSize is the number of records requested per page from computers-inventory.
+const pageSize = 100
+
+// sections are the inventory sections needed to fill out an mdm.Device.
+var sections = []string{
+	"GENERAL",
+	"HARDWARE",
+	"OPERATING_SYSTEM",
+	"USER_AND_LOCATION",
+}
+
+// InventoryResults is the paged response from computers-inventory.
+//   - https://developer.jamf.com/jamf-pro/reference/get_v1-computers-inventory
+type InventoryResults struct {
+	TotalCount int         `json:"totalCount"`
+	Results    []Inventory `json:"results"`
+}
+
+// Inventory is a single computer record.
+type Inventory struct {
+	ID              string          `json:"id"`
+	UDID            string          `json:"udid"`
+	General         General         `json:"general"`
+	Hardware        Hardware        `json:"hardware"`
+	OperatingSystem OperatingSystem `json:"operatingSystem"`
+	UserAndLocation UserAndLocation `json:"userAndLocation"`
+}
+
+// General holds the general section of the inventory record.
+type General struct {
+	Name             string     `json:"name"`
+	AssetTag         string     `json:"assetTag"`
+	Platform         string     `json:"platform"`
+	LastContactTime  *time.Time `json:"lastContactTime"`
+	InitialEntryDate string     `json:"initialEntryDate"`
+	LastEnrolledDate string     `json:"lastEnrolledDate"`
+}
+
+// Hardware holds the hardware section of the inventory record.
+type Hardware struct {
+	Model        string `json:"model"`
+	SerialNumber string `json:"serialNumber"`
+}
+
+// OperatingSystem holds the operatingSystem section of the inventory record.
+type OperatingSystem struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Build   string `json:"build"`
+}
+
+// UserAndLocation holds the userAndLocation section of the inventory record.
+type UserAndLocation struct {
+	Username string `json:"username"`
+	Realname string `json:"realname"`
+	Email    string `json:"email"`
+}
+
+// ListAllDevices will page through the computer inventory and return the results
+func (c *Client) ListAllDevices() (mdm.DeviceResults, error) {
+	return c.inventory("")
+}
+
+// QueryDevices will return devices based off query passed.
+func (c *Client) QueryDevices(opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
+	if opts == nil {
+		return c.ListAllDevices()
+	}
+
+	return c.inventory(filter(opts))
+}
+
+func (c *Client) getDevice(id string) (*mdm.Device, error) {
+	q := url.Values{}
+	q["section"] = sections
+
+	req, err := c.newRequest(http.MethodGet, fmt.Sprintf("v1/computers-inventory/%s?%s", id, q.Encode()), nil)
+	if err != nil {
+		c.log.Err(err).Msg("error building request")
+		return nil, err
+	}
+
+	var inv Inventory
+	if err := c.do(req, &inv); err != nil {
+		c.log.Err(err).Msg("error making request")
+		return nil, err
+	}
+
+	d := transform(&inv)
+
+	return &d, nil
+}
+
+func (c *Client) inventory(rsql string) (mdm.DeviceResults, error) {
+	res := mdm.DeviceResults{}
+
+	for page := 0; ; page++ {
+		results, err := c.list(page, rsql)
+		if err != nil {
+			c.log.Err(err).Int("page", page).Msg("error listing devices")
+			return res, err
+		}
+
+		for i := range results.Results {
+			res = append(res, transform(&results.Results[i]))
+		}
+
+		if len(results.Results) < pageSize || len(res) >= results.TotalCount {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+func (c *Client) list(page int, rsql string) (*InventoryResults, error) {
+	q := url.Values{}
+	q["section"] = sections
+	q.Set("page", strconv.Itoa(page))
+	q.Set("page-size", strconv.Itoa(pageSize))
+	q.Set("sort", "id:asc")
+	if rsql != "" {
+		q.Set("filter", rsql)
+	}
+
+	req, err := c.newRequest(http.MethodGet, "v1/computers-inventory?"+q.Encode(), nil)
+	if err != nil {
+		c.log.Err(err).Msg("error building request")
+		return nil, err
+	}
+
+	var results InventoryResults
+	if err := c.do(req, &results); err != nil {
+		c.log.Err(err).Str("url", req.URL.String()).Msg("error making request")
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// filter builds an RSQL expression from the query options. Each option set
+// is joined with a logical AND.
+func filter(opts *mdm.QueryOpts) string {
+	fields := []struct {
+		key, val string
+	}{
+		{"id", opts.DeviceID},
+		{"general.assetTag", opts.AssetTag},
+		{"general.name", opts.DeviceName},
+		{"general.platform", opts.Platform},
+		{"hardware.model", opts.Model},
+		{"hardware.serialNumber", opts.SerialNumber},
+		{"operatingSystem.version", opts.OSVersion},
+		{"userAndLocation.email", opts.UserEmail},
+		{"userAndLocation.username", opts.UserID},
+		{"userAndLocation.realname", opts.UserName},
+	}
+
+	var f []string
+	for _, v := range fields {
+		if v.val == "" {
+			continue
+		}
+		f = append(f, fmt.Sprintf("%s==%q", v.key, v.val))
+	}
+
+	return strings.Join(f, ";")
+}
+
+func transform(inv *Inventory) mdm.Device {
+	return mdm.Device{
+		DeviceID:        inv.ID,
+		DeviceName:      inv.General.Name,
+		Model:           inv.Hardware.Model,
+		SerialNumber:    inv.Hardware.SerialNumber,
+		Platform:        inv.General.Platform,
+		OSVersion:       inv.OperatingSystem.Version,
+		LastCheckIn:     inv.General.LastContactTime,
+		AssetTag:        inv.General.AssetTag,
+		FirstEnrollment: inv.General.InitialEntryDate,
+		LastEnrollment:  inv.General.LastEnrolledDate,
+		User: mdm.User{
+			Email: inv.UserAndLocation.Email,
+			Name:  inv.UserAndLocation.Realname,
+			ID:    inv.UserAndLocation.Username,
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package jamf
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+// sendUpdates is the body of the managed software updates endpoint.
+//   - https://developer.jamf.com/jamf-pro/reference/post_v1-macos-managed-software-updates-send-updates
+type sendUpdates struct {
+	DeviceIDs        []string `json:"deviceIds"`
+	MaxDeferrals     int      `json:"maxDeferrals"`
+	Version          string   `json:"version,omitempty"`
+	ApplyMajorUpdate bool     `json:"applyMajorUpdate"`
+	UpdateAction     string   `json:"updateAction"`
+	ForceRestart     bool     `json:"forceRestart"`
+}
+
+// scheduleDeferrals is how many times the user can defer a scheduled update.
+const scheduleDeferrals = 3
+
+// Enforce implements mdm.Enforcer. Jamf can schedule or force an update but
+// group membership is managed with smart groups so Remediate is not supported.
+func (c *Client) Enforce(action mdm.Action, device *mdm.Device, opts *mdm.EnforceOpts) error {
+	body := &sendUpdates{
+		DeviceIDs:        []string{device.DeviceID},
+		Version:          opts.Version,
+		ApplyMajorUpdate: majorUpdate(device.OSVersion, opts.Version),
+		UpdateAction:     "DOWNLOAD_AND_INSTALL",
+	}
+
+	switch action {
+	case mdm.ScheduleUpdate:
+		body.MaxDeferrals = scheduleDeferrals
+	case mdm.ForceInstall:
+		body.ForceRestart = true
+	default:
+		return mdm.ErrActionNotSupported
+	}
+
+	req, err := c.newRequest(http.MethodPost, "v1/macos-managed-software-updates/send-updates", body)
+	if err != nil {
+		return err
+	}
+
+	return c.do(req, nil)
+}
+
+// majorUpdate reports if the target version is a newer major release than
+// the device runs, so a device is only moved across a major release when the
+// policy asks for one.
+func majorUpdate(current, target string) bool {
+	cur, err := strconv.Atoi(major(current))
+	if err != nil {
+		return false
+	}
+
+	tgt, err := strconv.Atoi(major(target))
+	if err != nil {
+		return false
+	}
+
+	return tgt > cur
+}
+
+// major returns the major version of the OS, ex: 13 for 13.6.4.
+func major(v string) string {
+	v = strings.TrimSpace(v)
+	if i := strings.Index(v, "."); i >= 0 {
+		return v[:i]
+	}
+
+	return v
+}
@@ -0,0 +1,18 @@
+package jamf
+
+import "github.com/johnmikee/cuebert/mdm"
+
+// GetUsers implements mdm.Provider.
+func (c *Client) GetUsers(opts *mdm.QueryOpts) ([]mdm.User, error) {
+	res, err := c.QueryDevices(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	mu := []mdm.User{}
+	for i := range res {
+		mu = append(mu, res[i].User)
+	}
+
+	return mu, nil
+}
@@ -2,6 +2,7 @@ package kandji
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -15,12 +16,17 @@ import (
 	"github.com/johnmikee/cuebert/pkg/logger"
 )
 
+func init() {
+	mdm.Register(mdm.Kandji, func() mdm.Provider { return &Config{} })
+}
+
 type Config struct {
 	token   string
 	baseURL string
 
 	client *http.Client
 	log    logger.Logger
+	ctx    context.Context
 }
 
 // Setup implements mdm.Provider.
@@ -29,6 +35,15 @@ func (c *Config) Setup(m mdm.Config) {
 	c.baseURL = helpers.URLShaper(m.URL, "api/v1/")
 	c.client = httpClient(m.Client)
 	c.log = logger.ChildLogger("kandji", &m.Log)
+	c.ctx = context.Background()
+}
+
+// WithContext implements mdm.Binder.
+func (c *Config) WithContext(ctx context.Context) mdm.Provider {
+	bound := *c
+	bound.ctx = ctx
+
+	return &bound
 }
 
 // GetDevice implements mdm.Provider.
@@ -62,6 +77,7 @@ func NewClient(c *Client) *Config {
 		baseURL: helpers.URLShaper(c.BaseURL, "api/v1/"),
 		client:  httpClient(c.Client),
 		log:     logger.ChildLogger("kandji", c.Log),
+		ctx:     context.Background(),
 	}
 }
 
@@ -84,7 +100,7 @@ func (c *Config) newRequest(method, url string, body interface{}) (*http.Request
 			return nil, err
 		}
 	}
-	req, err := http.NewRequest(method, u, &buf)
+	req, err := http.NewRequestWithContext(c.ctx, method, u, &buf)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,43 @@
+package kandji
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+// assignBlueprint is the body of the update device endpoint used to move a
+// device to another blueprint.
+type assignBlueprint struct {
+	BlueprintID string `json:"blueprint_id"`
+}
+
+// Enforce implements mdm.Enforcer. Kandji enforces updates through the
+// blueprint a device is in so the only action is to move the device to the
+// remediation blueprint in opts.Group.
+func (c *Config) Enforce(action mdm.Action, device *mdm.Device, opts *mdm.EnforceOpts) error {
+	if action != mdm.Remediate {
+		return mdm.ErrActionNotSupported
+	}
+
+	req, err := c.newRequest(
+		http.MethodPatch,
+		fmt.Sprintf("devices/%s", device.DeviceID),
+		&assignBlueprint{BlueprintID: opts.Group},
+	)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		// do reads the body of a failed request but leaves it open.
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	return resp.Body.Close()
+}
@@ -0,0 +1,93 @@
+package kandji
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnmikee/cuebert/mdm"
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// closeTracker records if the bodies of the responses were closed.
+type closeTracker struct {
+	next   http.RoundTripper
+	closed []bool
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	closed *bool
+}
+
+func (b *trackedBody) Close() error {
+	*b.closed = true
+	return b.ReadCloser.Close()
+}
+
+func (c *closeTracker) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := c.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c.closed = append(c.closed, false)
+	resp.Body = &trackedBody{ReadCloser: resp.Body, closed: &c.closed[len(c.closed)-1]}
+
+	return resp, nil
+}
+
+func TestEnforce(t *testing.T) {
+	var (
+		path   string
+		method string
+		body   assignBlueprint
+		status = http.StatusOK
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, method = r.URL.Path, r.Method
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+	tracker := &closeTracker{next: http.DefaultTransport}
+	c := NewClient(&Client{
+		Token:   "token",
+		BaseURL: ts.URL,
+		Client:  &http.Client{Transport: tracker},
+		Log:     &log,
+	})
+
+	d := &mdm.Device{DeviceID: "7"}
+	opts := &mdm.EnforceOpts{Group: "blueprint-1"}
+
+	if err := c.Enforce(mdm.Remediate, d, opts); err != nil {
+		t.Fatalf("Enforce() returned error: %v", err)
+	}
+	if method != http.MethodPatch || path != "/api/v1/devices/7" || body.BlueprintID != "blueprint-1" {
+		t.Errorf("got %s %s with %+v", method, path, body)
+	}
+
+	status = http.StatusBadRequest
+	if err := c.Enforce(mdm.Remediate, d, opts); err == nil {
+		t.Error("expected an error for the failed request")
+	}
+
+	for i, closed := range tracker.closed {
+		if !closed {
+			t.Errorf("response %d was not closed", i)
+		}
+	}
+
+	if err := c.Enforce(mdm.ForceInstall, d, opts); err != mdm.ErrActionNotSupported {
+		t.Errorf("expected force install to be unsupported, got %v", err)
+	}
+}
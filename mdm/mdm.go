@@ -1,6 +1,7 @@
 package mdm
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/johnmikee/cuebert/pkg/logger"
@@ -10,6 +11,9 @@ import (
 type MDM string
 
 const (
+	File   MDM = "file"
+	Fleet  MDM = "fleet"
+	Intune MDM = "intune"
 	Jamf   MDM = "jamf"
 	Kandji MDM = "kandji"
 )
@@ -23,6 +27,23 @@ type Provider interface {
 	GetUsers(opts *QueryOpts) ([]User, error)
 }
 
+// Binder is implemented by providers that can make their requests with a
+// context, such as the span of the routine calling them. Wrappers implement
+// it by binding the provider they wrap.
+type Binder interface {
+	WithContext(ctx context.Context) Provider
+}
+
+// WithContext returns the provider bound to ctx, or the provider as is if it
+// cannot be bound.
+func WithContext(p Provider, ctx context.Context) Provider {
+	if b, ok := p.(Binder); ok {
+		return b.WithContext(ctx)
+	}
+
+	return p
+}
+
 type Config struct {
 	// Common configuration fields
 	Domain   string        `json:"domain,omitempty"`
@@ -31,6 +52,7 @@ type Config struct {
 	User     string        `json:"user,omitempty"`
 	Password string        `json:"password,omitempty"`
 	Token    string        `json:"token,omitempty"`
+	TokenURL string        `json:"token_url,omitempty"`
 	Client   *http.Client  `json:"client,omitempty"`
 	Log      logger.Logger `json:"log,omitempty"`
 	// Provider-specific configuration fields
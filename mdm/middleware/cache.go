@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+// Cache wraps an mdm.Provider and keeps results for a TTL. Concurrent
+// callers asking for the same data while a request is in flight share
+// the one response instead of each calling the MDM.
+type Cache struct {
+	mdm.Provider
+
+	ttl   time.Duration
+	now   func() time.Time
+	group singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// NewCache returns the provider wrapped in a cache holding results for ttl.
+func NewCache(p mdm.Provider, ttl time.Duration) *Cache {
+	return &Cache{
+		Provider: p,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  map[string]entry{},
+	}
+}
+
+// Setup implements mdm.Provider. The cache is cleared since the results
+// may now come from a different tenant.
+func (c *Cache) Setup(config mdm.Config) {
+	c.Provider.Setup(config)
+	c.Flush()
+}
+
+// Unwrap implements mdm.Wrapper.
+func (c *Cache) Unwrap() mdm.Provider {
+	return c.Provider
+}
+
+// Flush drops every cached result.
+func (c *Cache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[string]entry{}
+}
+
+// WithContext implements mdm.Binder. The bound cache shares its results with
+// c and fetches the ones it is missing from the provider bound to ctx.
+func (c *Cache) WithContext(ctx context.Context) mdm.Provider {
+	return &boundCache{Cache: c, bound: mdm.WithContext(c.Provider, ctx)}
+}
+
+// ListDevices implements mdm.Provider.
+func (c *Cache) ListDevices() ([]mdm.Device, error) {
+	return c.listDevices(c.Provider)
+}
+
+// GetDevice implements mdm.Provider.
+func (c *Cache) GetDevice(deviceID string) (*mdm.Device, error) {
+	return c.getDevice(c.Provider, deviceID)
+}
+
+// QueryDevices implements mdm.Provider.
+func (c *Cache) QueryDevices(opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
+	return c.queryDevices(c.Provider, opts)
+}
+
+// GetUsers implements mdm.Provider.
+func (c *Cache) GetUsers(opts *mdm.QueryOpts) ([]mdm.User, error) {
+	return c.getUsers(c.Provider, opts)
+}
+
+func (c *Cache) listDevices(p mdm.Provider) ([]mdm.Device, error) {
+	v, err := c.get("list", func() (interface{}, error) {
+		return p.ListDevices()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyDevices(v.([]mdm.Device)), nil
+}
+
+func (c *Cache) getDevice(p mdm.Provider, deviceID string) (*mdm.Device, error) {
+	v, err := c.get("device:"+deviceID, func() (interface{}, error) {
+		return p.GetDevice(deviceID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d := *v.(*mdm.Device)
+
+	return &d, nil
+}
+
+func (c *Cache) queryDevices(p mdm.Provider, opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
+	v, err := c.get("query:"+optsKey(opts), func() (interface{}, error) {
+		return p.QueryDevices(opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyDevices(v.(mdm.DeviceResults)), nil
+}
+
+func (c *Cache) getUsers(p mdm.Provider, opts *mdm.QueryOpts) ([]mdm.User, error) {
+	v, err := c.get("users:"+optsKey(opts), func() (interface{}, error) {
+		return p.GetUsers(opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := v.([]mdm.User)
+	out := make([]mdm.User, len(users))
+	copy(out, users)
+
+	return out, nil
+}
+
+// boundCache is a Cache fetching through a provider bound to a context.
+type boundCache struct {
+	*Cache
+	bound mdm.Provider
+}
+
+// Unwrap implements mdm.Wrapper.
+func (b *boundCache) Unwrap() mdm.Provider {
+	return b.bound
+}
+
+// ListDevices implements mdm.Provider.
+func (b *boundCache) ListDevices() ([]mdm.Device, error) {
+	return b.listDevices(b.bound)
+}
+
+// GetDevice implements mdm.Provider.
+func (b *boundCache) GetDevice(deviceID string) (*mdm.Device, error) {
+	return b.getDevice(b.bound, deviceID)
+}
+
+// QueryDevices implements mdm.Provider.
+func (b *boundCache) QueryDevices(opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
+	return b.queryDevices(b.bound, opts)
+}
+
+// GetUsers implements mdm.Provider.
+func (b *boundCache) GetUsers(opts *mdm.QueryOpts) ([]mdm.User, error) {
+	return b.getUsers(b.bound, opts)
+}
+
+// get returns the cached value for key or calls fetch, sharing the call
+// with anyone else asking for the same key. Errors are not cached.
+func (c *Cache) get(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && c.now().Before(e.expires) {
+		return e.value, nil
+	}
+
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		v, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		c.entries[key] = entry{value: v, expires: c.now().Add(c.ttl)}
+		c.mu.Unlock()
+
+		return v, nil
+	})
+
+	return v, err
+}
+
+// copyDevices returns a copy of the slice so callers cannot change what is cached.
+func copyDevices(d []mdm.Device) []mdm.Device {
+	if d == nil {
+		return nil
+	}
+
+	out := make([]mdm.Device, len(d))
+	copy(out, d)
+
+	return out
+}
+
+func optsKey(opts *mdm.QueryOpts) string {
+	if opts == nil {
+		return "all"
+	}
+
+	b, _ := json.Marshal(opts)
+
+	return string(b)
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnmikee/cuebert/mdm"
+)
+
+type stubProvider struct {
+	calls   int32
+	release chan struct{}
+	err     error
+}
+
+func (s *stubProvider) Setup(mdm.Config) {}
+
+func (s *stubProvider) ListDevices() ([]mdm.Device, error) {
+	atomic.AddInt32(&s.calls, 1)
+	if s.release != nil {
+		<-s.release
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return []mdm.Device{{SerialNumber: "C02ABC"}}, nil
+}
+
+func (s *stubProvider) GetDevice(id string) (*mdm.Device, error) {
+	atomic.AddInt32(&s.calls, 1)
+	return &mdm.Device{DeviceID: id}, nil
+}
+
+func (s *stubProvider) QueryDevices(opts *mdm.QueryOpts) (mdm.DeviceResults, error) {
+	atomic.AddInt32(&s.calls, 1)
+	return mdm.DeviceResults{{SerialNumber: opts.SerialNumber}}, nil
+}
+
+func (s *stubProvider) GetUsers(*mdm.QueryOpts) ([]mdm.User, error) {
+	atomic.AddInt32(&s.calls, 1)
+	return []mdm.User{{Email: "user@example.com"}}, nil
+}
+
+// bindingProvider records the context it was bound to when it is called.
+type bindingProvider struct {
+	*stubProvider
+	ctx  context.Context
+	seen *context.Context
+}
+
+func (b *bindingProvider) WithContext(ctx context.Context) mdm.Provider {
+	return &bindingProvider{stubProvider: b.stubProvider, ctx: ctx, seen: b.seen}
+}
+
+func (b *bindingProvider) ListDevices() ([]mdm.Device, error) {
+	*b.seen = b.ctx
+	return b.stubProvider.ListDevices()
+}
+
+type ctxKey struct{}
+
+func TestCacheTTL(t *testing.T) {
+	p := &stubProvider{}
+	c := NewCache(p, time.Minute)
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		d, err := c.ListDevices()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(d) != 1 || d[0].SerialNumber != "C02ABC" {
+			t.Fatalf("unexpected devices %+v", d)
+		}
+		// changing the result must not change the cache.
+		d[0].SerialNumber = "changed"
+	}
+	if p.calls != 1 {
+		t.Errorf("expected 1 call, got %d", p.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := c.ListDevices(); err != nil {
+		t.Fatal(err)
+	}
+	if p.calls != 2 {
+		t.Errorf("expected a new call after the ttl, got %d calls", p.calls)
+	}
+}
+
+func TestCacheWithContext(t *testing.T) {
+	var seen context.Context
+	p := &stubProvider{}
+	c := NewCache(&bindingProvider{stubProvider: p, seen: &seen}, time.Minute)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "check")
+	if _, err := mdm.WithContext(c, ctx).ListDevices(); err != nil {
+		t.Fatal(err)
+	}
+	if seen != ctx {
+		t.Error("expected the bound cache to call the provider bound to the context")
+	}
+
+	if _, err := c.ListDevices(); err != nil {
+		t.Fatal(err)
+	}
+	if p.calls != 1 {
+		t.Errorf("expected the bound cache to share its results, got %d calls", p.calls)
+	}
+}
+
+func TestCacheKeys(t *testing.T) {
+	p := &stubProvider{}
+	c := NewCache(p, time.Minute)
+
+	a, _ := c.QueryDevices(&mdm.QueryOpts{SerialNumber: "A"})
+	b, _ := c.QueryDevices(&mdm.QueryOpts{SerialNumber: "B"})
+	_, _ = c.QueryDevices(&mdm.QueryOpts{SerialNumber: "A"})
+
+	if a[0].SerialNumber != "A" || b[0].SerialNumber != "B" {
+		t.Errorf("queries returned the wrong results: %v %v", a, b)
+	}
+	if p.calls != 2 {
+		t.Errorf("expected 2 calls, got %d", p.calls)
+	}
+
+	d, _ := c.GetDevice("1")
+	d.DeviceID = "changed"
+	d, _ = c.GetDevice("1")
+	if d.DeviceID != "1" {
+		t.Errorf("cached device was modified: %s", d.DeviceID)
+	}
+
+	c.Flush()
+	_, _ = c.QueryDevices(&mdm.QueryOpts{SerialNumber: "A"})
+	if p.calls != 4 {
+		t.Errorf("expected flush to clear the cache, got %d calls", p.calls)
+	}
+}
+
+func TestCacheCoalesce(t *testing.T) {
+	p := &stubProvider{release: make(chan struct{})}
+	c := NewCache(p, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.ListDevices(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	// give the callers a moment to pile up behind the first request.
+	time.Sleep(50 * time.Millisecond)
+	close(p.release)
+	wg.Wait()
+
+	if p.calls != 1 {
+		t.Errorf("expected concurrent callers to share 1 call, got %d", p.calls)
+	}
+}
+
+func TestCacheErrorNotCached(t *testing.T) {
+	p := &stubProvider{err: errors.New("boom")}
+	c := NewCache(p, time.Minute)
+
+	if _, err := c.ListDevices(); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	p.err = nil
+	if _, err := c.ListDevices(); err != nil {
+		t.Fatal(err)
+	}
+	if p.calls != 2 {
+		t.Errorf("expected errors to not be cached, got %d calls", p.calls)
+	}
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/johnmikee/cuebert/pkg/logger"
+)
+
+// Transport is an http.RoundTripper that waits on a Limiter before each
+// request and retries throttled (429) and server (5xx) responses with an
+// exponential backoff, honoring Retry-After when the server sends it.
+//
+// Only idempotent requests are retried on a 5xx. A POST or PATCH may have
+// been acted on before the server failed, so those are retried on a 429, or a
+// 503 with Retry-After, where the server says it did not take the request.
+type Transport struct {
+	// Base is the transport used to make requests. http.DefaultTransport if nil.
+	Base http.RoundTripper
+	// Limiter, if set, is waited on before every attempt.
+	Limiter *Limiter
+	// MaxRetries is how many times a request is retried after the first attempt.
+	MaxRetries int
+	// MinBackoff is the wait before the first retry. It doubles on every retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the wait between retries, including Retry-After.
+	MaxBackoff time.Duration
+
+	Log *logger.Logger
+
+	sleep func(time.Duration, *http.Request) error
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	for attempt := 0; ; attempt++ {
+		if t.Limiter != nil {
+			if err := t.Limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		r := req
+		if attempt > 0 && req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		resp, err := base.RoundTrip(r)
+		if err != nil || !retryable(req.Method, resp) || attempt >= t.MaxRetries || !rewindable(req) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp.Header.Get("Retry-After"))
+		if t.Log != nil {
+			t.Log.Debug().
+				Str("url", req.URL.String()).
+				Int("status", resp.StatusCode).
+				Int("attempt", attempt+1).
+				Dur("wait", wait).
+				Msg("retrying request")
+		}
+
+		// the response is discarded so close it before trying again.
+		_ = resp.Body.Close()
+
+		if err := t.wait(wait, req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *Transport) wait(d time.Duration, req *http.Request) error {
+	if t.sleep != nil {
+		return t.sleep(d, req)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff returns how long to wait before the next attempt. Retry-After
+// wins if it is set, otherwise the wait doubles each attempt with jitter.
+func (t *Transport) backoff(attempt int, retryAfter string) time.Duration {
+	maxWait := t.MaxBackoff
+	if maxWait <= 0 {
+		maxWait = time.Minute
+	}
+
+	if d, ok := parseRetryAfter(retryAfter, time.Now()); ok {
+		if d > maxWait {
+			return maxWait
+		}
+		return d
+	}
+
+	minWait := t.MinBackoff
+	if minWait <= 0 {
+		minWait = 500 * time.Millisecond
+	}
+
+	d := minWait << uint(attempt)
+	if d <= 0 || d > maxWait {
+		d = maxWait
+	}
+
+	// up to 20% jitter so concurrent callers do not retry in lockstep.
+	jitter := time.Duration(rand.Int63n(int64(d)/5 + 1)) // #nosec G404 -- jitter does not need a secure source
+
+	return d - jitter
+}
+
+// parseRetryAfter reads Retry-After as either a number of seconds or an http date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(v); err == nil {
+		d := at.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+// retryable reports if the response to a request with the method can be
+// retried.
+func retryable(method string, resp *http.Response) bool {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode < 500:
+		return false
+	case idempotent(method):
+		return true
+	default:
+		return resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") != ""
+	}
+}
+
+// idempotent reports if sending a request with the method twice has the same
+// effect as sending it once. an empty method is a GET.
+func idempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// rewindable reports if the request can be sent again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransportRetry(t *testing.T) {
+	var calls int
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		switch calls {
+		case 1:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	tr := &Transport{
+		MaxRetries: 3,
+		MinBackoff: time.Second,
+		sleep: func(d time.Duration, _ *http.Request) error {
+			waits = append(waits, d)
+			return nil
+		},
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, ts.URL, strings.NewReader("payload"))
+	resp, err := (&http.Client{Transport: tr}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+	for i, b := range bodies {
+		if b != "payload" {
+			t.Errorf("attempt %d sent body %q", i+1, b)
+		}
+	}
+	if len(waits) != 2 || waits[0] != 7*time.Second {
+		t.Errorf("expected to honor Retry-After first, got %v", waits)
+	}
+	if len(waits) == 2 && (waits[1] > 2*time.Second || waits[1] < time.Second) {
+		t.Errorf("expected a backoff between 1s and 2s, got %v", waits[1])
+	}
+}
+
+func TestTransportGivesUp(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	tr := &Transport{
+		MaxRetries: 2,
+		sleep:      func(time.Duration, *http.Request) error { return nil },
+	}
+
+	resp, err := (&http.Client{Transport: tr}).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the last response to be returned, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestTransportNoRetryOnClientError(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	tr := &Transport{MaxRetries: 3}
+
+	resp, err := (&http.Client{Transport: tr}).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", calls)
+	}
+}
+
+func TestTransportRetryMethods(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		status     int
+		retryAfter string
+		calls      int
+	}{
+		{"get server error", http.MethodGet, http.StatusBadGateway, "", 3},
+		{"delete server error", http.MethodDelete, http.StatusInternalServerError, "", 3},
+		{"post throttled", http.MethodPost, http.StatusTooManyRequests, "", 3},
+		{"post server error", http.MethodPost, http.StatusBadGateway, "", 1},
+		{"post unavailable", http.MethodPost, http.StatusServiceUnavailable, "", 1},
+		{"post unavailable with retry-after", http.MethodPost, http.StatusServiceUnavailable, "1", 3},
+		{"patch server error", http.MethodPatch, http.StatusInternalServerError, "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			tr := &Transport{
+				MaxRetries: 2,
+				sleep:      func(time.Duration, *http.Request) error { return nil },
+			}
+
+			req, _ := http.NewRequest(tt.method, ts.URL, strings.NewReader("payload"))
+			resp, err := (&http.Client{Transport: tr}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if calls != tt.calls {
+				t.Errorf("expected %d attempts, got %d", tt.calls, calls)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, false},
+		{"30", 30 * time.Second, true},
+		{"-1", 0, false},
+		{"Sun, 01 Oct 2023 12:00:45 GMT", 45 * time.Second, true},
+		{"Sun, 01 Oct 2023 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.in, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v. want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(10, 2)
+
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	if l.reserve() != 0 || l.reserve() != 0 {
+		t.Fatal("expected the burst to be available immediately")
+	}
+
+	wait := l.reserve()
+	if wait <= 0 || wait > 100*time.Millisecond {
+		t.Errorf("expected to wait up to 100ms for a token, got %v", wait)
+	}
+
+	now = now.Add(100 * time.Millisecond)
+	if l.reserve() != 0 {
+		t.Error("expected a token after refilling")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.now = time.Now
+	l.tokens = 0
+	if err := l.Wait(ctx); err == nil {
+		t.Error("expected a canceled context to stop the wait")
+	}
+}
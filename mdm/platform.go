@@ -0,0 +1,64 @@
+package mdm
+
+import "strings"
+
+// Platform names devices are normalized to. Each MDM reports the platform
+// differently (ex: Kandji uses Mac, Intune macOS, Fleet darwin) so callers
+// should compare against these after passing the value through NormalizePlatform.
+const (
+	MacOS    = "macOS"
+	IOS      = "iOS"
+	IPadOS   = "iPadOS"
+	TVOS     = "tvOS"
+	Windows  = "Windows"
+	Linux    = "Linux"
+	ChromeOS = "ChromeOS"
+	Android  = "Android"
+)
+
+// linuxDistros are the platform values osquery reports for linux hosts.
+var linuxDistros = []string{
+	"linux",
+	"ubuntu",
+	"debian",
+	"rhel",
+	"centos",
+	"fedora",
+	"amzn",
+	"arch",
+	"manjaro",
+	"opensuse",
+	"sles",
+	"gentoo",
+	"pop",
+	"kali",
+}
+
+// NormalizePlatform maps the platform reported by an MDM to one of the
+// platform constants. Unknown values are returned as is.
+func NormalizePlatform(p string) string {
+	switch l := strings.ToLower(strings.TrimSpace(p)); l {
+	case "mac", "macos", "darwin", "osx", "mac os x":
+		return MacOS
+	case "ios", "iphone":
+		return IOS
+	case "ipados", "ipad":
+		return IPadOS
+	case "tvos", "appletv", "apple tv":
+		return TVOS
+	case "windows", "windowsrt", "windows10x":
+		return Windows
+	case "chrome", "chromeos", "chrome os":
+		return ChromeOS
+	case "android", "androidforwork", "androidenterprise":
+		return Android
+	default:
+		for _, d := range linuxDistros {
+			if l == d {
+				return Linux
+			}
+		}
+	}
+
+	return p
+}
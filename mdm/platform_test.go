@@ -0,0 +1,27 @@
+package mdm
+
+import "testing"
+
+func TestNormalizePlatform(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Mac", MacOS},
+		{"macOS", MacOS},
+		{"darwin", MacOS},
+		{"iPhone", IOS},
+		{"iPad", IPadOS},
+		{"windows", Windows},
+		{"ubuntu", Linux},
+		{"rhel", Linux},
+		{"chrome", ChromeOS},
+		{"Plan9", "Plan9"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizePlatform(tt.in); got != tt.want {
+			t.Errorf("NormalizePlatform(%q) got: %s, want: %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnmikee/cuebert/pkg/helpers"
+	"github.com/johnmikee/cuebert/pkg/oauth"
+)
+
+// DefaultURL is the Microsoft Graph base used when no URL is passed.
+const DefaultURL = "https://graph.microsoft.com/v1.0/"
+
+// Config holds the app registration used to talk to Microsoft Graph.
+type Config struct {
+	// Tenant is the Entra ID tenant the app registration lives in. It is only
+	// used to build the token url if TokenURL is empty.
+	Tenant       string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	BaseURL      string
+
+	Client *http.Client
+}
+
+// Client makes authenticated requests against Microsoft Graph using the
+// client credentials flow. It is shared by the Intune MDM and Entra ID IdP.
+type Client struct {
+	baseURL string
+	creds   *oauth.ClientCredentials
+	client  *http.Client
+	ctx     context.Context
+}
+
+// Page is a single page of a collection response.
+type Page struct {
+	NextLink string          `json:"@odata.nextLink"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// New returns a Graph client.
+func New(c *Config) *Client {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultURL
+	}
+
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = oauth.MicrosoftTokenURL(c.Tenant)
+	}
+
+	client := httpClient(c.Client)
+
+	return &Client{
+		baseURL: helpers.URLShaper(base, ""),
+		creds: &oauth.ClientCredentials{
+			TokenURL:     tokenURL,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			Scopes:       []string{oauth.GraphScope},
+			Client:       client,
+		},
+		client: client,
+		ctx:    context.Background(),
+	}
+}
+
+// WithContext returns a copy of the client that makes its requests with ctx.
+// The token is shared with the client it was copied from.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	bound := *c
+	bound.ctx = ctx
+
+	return &bound
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+
+	return &http.Client{
+		Timeout: time.Minute,
+	}
+}
+
+// Get decodes the response from url into v. Relative urls are joined to the
+// base url while absolute urls, such as the @odata.nextLink, are used as is.
+func (c *Client) Get(url string, v interface{}) error {
+	req, err := c.newRequest(http.MethodGet, url)
+	if err != nil {
+		return err
+	}
+
+	return c.do(req, v)
+}
+
+// List follows @odata.nextLink starting at url and calls fn with the value
+// of each page until there are no pages left or fn returns an error.
+func (c *Client) List(url string, fn func(value json.RawMessage) error) error {
+	next := url
+
+	for next != "" {
+		var page Page
+		if err := c.Get(next, &page); err != nil {
+			return err
+		}
+
+		if err := fn(page.Value); err != nil {
+			return err
+		}
+
+		next = page.NextLink
+	}
+
+	return nil
+}
+
+func (c *Client) newRequest(method, url string) (*http.Request, error) {
+	u := url
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		u = c.baseURL + strings.TrimPrefix(url, "/")
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.creds.TokenContext(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", helpers.TokenValidator(token, "Bearer"))
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request, v interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// the token may have been revoked early. drop it so the next request starts fresh.
+		c.creds.Invalidate()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("error during request. status code=%d error: %s", resp.StatusCode, string(body))
+	}
+
+	if v != nil {
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+
+	return nil
+}
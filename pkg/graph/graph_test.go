@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	var srv *httptest.Server
+	tokens := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokens++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "abc", "expires_in": 3600})
+	})
+	mux.HandleFunc("/v1.0/things", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		page := map[string]interface{}{"value": []int{n * 2, n*2 + 1}}
+		if n < 2 {
+			page["@odata.nextLink"] = srv.URL + "/v1.0/things?n=" + strconv.Itoa(n+1)
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := New(&Config{
+		TokenURL: srv.URL + "/token",
+		BaseURL:  srv.URL + "/v1.0",
+	})
+
+	var got []int
+	err := c.List("things", func(value json.RawMessage) error {
+		var v []int
+		if err := json.Unmarshal(value, &v); err != nil {
+			return err
+		}
+		got = append(got, v...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+
+	if len(got) != 6 || got[5] != 5 {
+		t.Errorf("unexpected values: %v", got)
+	}
+
+	if tokens != 1 {
+		t.Errorf("expected the token to be reused, got %d token requests", tokens)
+	}
+}
+
+func TestGetError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "abc", "expires_in": 3600})
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c := New(&Config{TokenURL: srv.URL + "/token", BaseURL: srv.URL})
+
+	if err := c.Get("things", nil); err == nil {
+		t.Error("expected an error for a forbidden request")
+	}
+}
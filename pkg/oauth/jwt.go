@@ -0,0 +1,174 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// GoogleTokenURL is used when the service account key does not include a token_uri.
+const GoogleTokenURL = "https://oauth2.googleapis.com/token"
+
+// jwtLifetime is how long the signed assertion is valid for. Google caps this at one hour.
+const jwtLifetime = time.Hour
+
+// ServiceAccountKey is the json key downloaded for a Google service account.
+type ServiceAccountKey struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// ServiceAccount fetches and caches an access token using the JWT bearer grant.
+//   - https://datatracker.ietf.org/doc/html/rfc7523
+//   - https://developers.google.com/identity/protocols/oauth2/service-account
+type ServiceAccount struct {
+	// Subject is the user to impersonate when domain-wide delegation is used.
+	Subject string
+	Scopes  []string
+
+	Client *http.Client
+
+	key   *ServiceAccountKey
+	rsa   *rsa.PrivateKey
+	cache tokenCache
+}
+
+// NewServiceAccount parses the json key and returns a ServiceAccount ready to request tokens.
+func NewServiceAccount(keyJSON []byte, subject string, scopes []string, client *http.Client) (*ServiceAccount, error) {
+	var key ServiceAccountKey
+	if err := json.Unmarshal(keyJSON, &key); err != nil {
+		return nil, errors.Wrap(err, "parsing service account key")
+	}
+
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, errors.New("service account key is missing client_email or private_key")
+	}
+
+	pk, err := parseRSAKey([]byte(key.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	if key.TokenURI == "" {
+		key.TokenURI = GoogleTokenURL
+	}
+
+	return &ServiceAccount{
+		Subject: subject,
+		Scopes:  scopes,
+		Client:  client,
+		key:     &key,
+		rsa:     pk,
+	}, nil
+}
+
+// Token returns a cached access token, requesting a new one if there
+// is no token yet or the current one is about to expire.
+func (s *ServiceAccount) Token() (string, error) {
+	return s.TokenContext(context.Background())
+}
+
+// TokenContext is Token with the request for a new token made with ctx.
+func (s *ServiceAccount) TokenContext(ctx context.Context) (string, error) {
+	return s.cache.get(func() (*tokenResponse, error) {
+		return s.fetch(ctx)
+	})
+}
+
+// Invalidate drops the cached token so the next call to Token requests a new one.
+func (s *ServiceAccount) Invalidate() {
+	s.cache.invalidate()
+}
+
+func (s *ServiceAccount) fetch(ctx context.Context) (*tokenResponse, error) {
+	assertion, err := s.assertion(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+
+	return postToken(ctx, s.Client, s.key.TokenURI, form)
+}
+
+// assertion builds and signs the RS256 JWT sent to the token endpoint.
+func (s *ServiceAccount) assertion(now time.Time) (string, error) {
+	header := map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	}
+	if s.key.PrivateKeyID != "" {
+		header["kid"] = s.key.PrivateKeyID
+	}
+
+	claims := map[string]interface{}{
+		"iss":   s.key.ClientEmail,
+		"scope": strings.Join(s.Scopes, " "),
+		"aud":   s.key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(jwtLifetime).Unix(),
+	}
+	if s.Subject != "" {
+		claims["sub"] = s.Subject
+	}
+
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(h) + "." + enc.EncodeToString(c)
+
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.rsa, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", errors.Wrap(err, "signing assertion")
+	}
+
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// parseRSAKey reads a PEM encoded PKCS#8 or PKCS#1 private key.
+func parseRSAKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		pk, ok := k.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("private key is not an RSA key")
+		}
+		return pk, nil
+	}
+
+	pk, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing private key")
+	}
+
+	return pk, nil
+}
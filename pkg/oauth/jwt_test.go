@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testKey returns a service account json key and the public key to verify it with.
+func testKey(t *testing.T, tokenURI string) ([]byte, *rsa.PublicKey) {
+	pk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, _ := json.Marshal(ServiceAccountKey{
+		Type:         "service_account",
+		ClientEmail:  "cuebert@project.iam.gserviceaccount.com",
+		PrivateKeyID: "kid-1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:     tokenURI,
+	})
+
+	return key, &pk.PublicKey
+}
+
+func TestServiceAccountToken(t *testing.T) {
+	var pub *rsa.PublicKey
+	calls := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) != 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		b, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims map[string]interface{}
+		_ = json.Unmarshal(b, &claims)
+		if claims["sub"] != "admin@example.com" ||
+			claims["iss"] != "cuebert@project.iam.gserviceaccount.com" ||
+			claims["scope"] != "a b" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		calls++
+		_ = json.NewEncoder(w).Encode(tokenResponse{AccessToken: "ya29", ExpiresIn: 3600})
+	}))
+	defer srv.Close()
+
+	key, p := testKey(t, srv.URL)
+	pub = p
+
+	sa, err := NewServiceAccount(key, "admin@example.com", []string{"a", "b"}, nil)
+	if err != nil {
+		t.Fatalf("NewServiceAccount() returned error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		tok, err := sa.Token()
+		if err != nil {
+			t.Fatalf("Token() returned error: %v", err)
+		}
+		if tok != "ya29" {
+			t.Errorf("Token() got: %s, want: ya29", tok)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected cached token to be reused, got %d requests", calls)
+	}
+}
+
+func TestNewServiceAccountErrors(t *testing.T) {
+	if _, err := NewServiceAccount([]byte("nope"), "", nil, nil); err == nil {
+		t.Error("expected an error for invalid json")
+	}
+
+	if _, err := NewServiceAccount([]byte(`{"client_email":"a"}`), "", nil, nil); err == nil {
+		t.Error("expected an error for a missing private key")
+	}
+
+	if _, err := NewServiceAccount([]byte(`{"client_email":"a","private_key":"junk"}`), "", nil, nil); err == nil {
+		t.Error("expected an error for a key that is not PEM encoded")
+	}
+}
@@ -0,0 +1,148 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// refreshWindow is how long before the token expires we request a new one.
+const refreshWindow = time.Minute
+
+// ClientCredentials fetches and caches an access token using the OAuth 2.0
+// client credentials grant.
+//   - https://datatracker.ietf.org/doc/html/rfc6749#section-4.4
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	Client *http.Client
+
+	cache tokenCache
+}
+
+// tokenCache holds an access token until it is about to expire.
+type tokenCache struct {
+	token   string
+	expires time.Time
+	lock    sync.Mutex
+}
+
+// get returns the cached token or calls fetch for a new one if there is no
+// token yet or the current one is about to expire.
+func (t *tokenCache) get(fetch func() (*tokenResponse, error)) (string, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.token != "" && time.Now().Add(refreshWindow).Before(t.expires) {
+		return t.token, nil
+	}
+
+	tr, err := fetch()
+	if err != nil {
+		return "", err
+	}
+
+	t.token = tr.AccessToken
+	t.expires = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+
+	return t.token, nil
+}
+
+func (t *tokenCache) invalidate() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.token = ""
+	t.expires = time.Time{}
+}
+
+// tokenResponse is the successful response from the token endpoint.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Token returns a cached access token, requesting a new one if there
+// is no token yet or the current one is about to expire.
+func (c *ClientCredentials) Token() (string, error) {
+	return c.TokenContext(context.Background())
+}
+
+// TokenContext is Token with the request for a new token made with ctx.
+func (c *ClientCredentials) TokenContext(ctx context.Context) (string, error) {
+	return c.cache.get(func() (*tokenResponse, error) {
+		return c.fetch(ctx)
+	})
+}
+
+// Invalidate drops the cached token so the next call to Token requests a new one.
+func (c *ClientCredentials) Invalidate() {
+	c.cache.invalidate()
+}
+
+func (c *ClientCredentials) fetch(ctx context.Context) (*tokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", c.ClientID)
+	form.Set("client_secret", c.ClientSecret)
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+
+	return postToken(ctx, c.Client, c.TokenURL, form)
+}
+
+// postToken sends the form to the token endpoint and decodes the response.
+func postToken(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (*tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, errors.Errorf("error requesting token. status code=%d error: %s", resp.StatusCode, string(body))
+	}
+
+	var tr tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, errors.Wrap(err, "decoding token response")
+	}
+
+	if tr.AccessToken == "" {
+		return nil, errors.New("token endpoint returned an empty access token")
+	}
+
+	return &tr, nil
+}
+
+// MicrosoftTokenURL returns the v2.0 token endpoint for an Entra ID tenant.
+func MicrosoftTokenURL(tenant string) string {
+	return "https://login.microsoftonline.com/" + url.PathEscape(tenant) + "/oauth2/v2.0/token"
+}
+
+// GraphScope is the default scope used for application access to Microsoft Graph.
+const GraphScope = "https://graph.microsoft.com/.default"
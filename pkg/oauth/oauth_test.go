@@ -0,0 +1,85 @@
+package oauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCredentialsToken(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.Form.Get("grant_type") != "client_credentials" ||
+			r.Form.Get("client_id") != "id" ||
+			r.Form.Get("client_secret") != "secret" ||
+			r.Form.Get("scope") != GraphScope {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		calls++
+		_ = json.NewEncoder(w).Encode(tokenResponse{AccessToken: "abc", TokenType: "Bearer", ExpiresIn: 3600})
+	}))
+	defer srv.Close()
+
+	c := &ClientCredentials{
+		TokenURL:     srv.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{GraphScope},
+	}
+
+	for i := 0; i < 2; i++ {
+		tok, err := c.Token()
+		if err != nil {
+			t.Fatalf("Token() returned error: %v", err)
+		}
+		if tok != "abc" {
+			t.Errorf("Token() got: %s, want: abc", tok)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected cached token to be reused, got %d requests", calls)
+	}
+
+	c.Invalidate()
+	if _, err := c.Token(); err != nil {
+		t.Fatalf("Token() returned error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected a new token after Invalidate, got %d requests", calls)
+	}
+}
+
+func TestClientCredentialsShortLived(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_ = json.NewEncoder(w).Encode(tokenResponse{AccessToken: "abc", ExpiresIn: 30})
+	}))
+	defer srv.Close()
+
+	c := &ClientCredentials{TokenURL: srv.URL}
+	_, _ = c.Token()
+	_, _ = c.Token()
+
+	if calls != 2 {
+		t.Errorf("expected tokens inside the refresh window to be replaced, got %d requests", calls)
+	}
+}
+
+func TestClientCredentialsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := &ClientCredentials{TokenURL: srv.URL}
+	if _, err := c.Token(); err == nil {
+		t.Error("expected an error from a failed token request")
+	}
+}
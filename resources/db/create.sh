@@ -25,7 +25,6 @@ do
         n) DBNAME=${OPTARG};;
         p) PORT=${OPTARG};;
         t) TABLES=${OPTARG};;
-        tg) TRIGGERS=${OPTARG};;
         u) USER=${OPTARG};;
         un) USERNAME=${OPTARG};;
     esac
@@ -37,12 +36,12 @@ db() {
     psql --username=$USERNAME --host=$HOST --port=$PORT < "$base_dir"/resources/db/create_db.sql
 }
 
+# cuebert applies the migrations itself on start. running them here is safe
+# since each one can be applied to an existing schema.
 tables() {
-    psql --user=cue --host=$HOST --dbname=$DBNAME --port=$PORT < "$base_dir"/resources/db/create_tables.sql
-}
-
-triggers() {
-    psql --user=cue --host=$HOST --dbname=$DBNAME --port=$PORT < "$base_dir"/resources/db/create_triggers.sql
+    for f in "$base_dir"/db/migrate/migrations/*.up.sql; do
+        psql --user=cue --host=$HOST --dbname=$DBNAME --port=$PORT < "$f"
+    done
 }
 
 user() {
@@ -53,7 +52,6 @@ all() {
     user
     db
     tables
-    triggers
 }
 
 if $ALL; then
@@ -65,8 +63,6 @@ elif $DB; then
     db
 elif $TABLES; then
     tables
-elif $TRIGGERS; then
-    triggers
 else
     echo "please check the arguements passed and try again"
 fi